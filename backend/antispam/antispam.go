@@ -0,0 +1,67 @@
+// Package antispam — эвристики против спама в отзывах и заявках с сайта. Подозрительная
+// отправка не отклоняется, а уходит на модерацию: ложное срабатывание обходится дешевле
+// потерянного отзыва или клиента.
+package antispam
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Причины, по которым отправка попала на модерацию
+const (
+	ReasonHoneypot     = "honeypot"
+	ReasonNoFormToken  = "invalid_form_token"
+	ReasonTooFast      = "filled_too_fast"
+	ReasonTooManyLinks = "too_many_links"
+	ReasonStopWord     = "stop_word"
+	ReasonDuplicate    = "duplicate_text"
+)
+
+// Policy — пороги проверок
+type Policy struct {
+	// MinFillTime — быстрее человек форму не заполнит
+	MinFillTime time.Duration
+	// MaxLinks — сколько ссылок допустимо во всех полях вместе
+	MaxLinks  int
+	StopWords *StopWords
+}
+
+// Submission — отправка формы глазами проверок
+type Submission struct {
+	// Fields — всё, что человек набрал сам: текст, имя, модель; email сюда не входит
+	Fields []string
+	// Honeypot — скрытое поле формы, человек его не видит и не заполняет
+	Honeypot string
+	// FormAge — сколько прошло с выдачи токена формы; FormTokenValid=false — токена нет или он поддельный
+	FormAge        time.Duration
+	FormTokenValid bool
+}
+
+// Check возвращает причины считать отправку подозрительной; пустой список — всё в порядке.
+// Повторы текста проверяются отдельно, по отпечаткам в БД.
+func (p Policy) Check(s Submission) []string {
+	var reasons []string
+	if strings.TrimSpace(s.Honeypot) != "" {
+		reasons = append(reasons, ReasonHoneypot)
+	}
+	if !s.FormTokenValid {
+		reasons = append(reasons, ReasonNoFormToken)
+	} else if s.FormAge < p.MinFillTime {
+		reasons = append(reasons, ReasonTooFast)
+	}
+
+	links := 0
+	for _, f := range s.Fields {
+		links += CountLinks(f)
+	}
+	if links > p.MaxLinks {
+		reasons = append(reasons, ReasonTooManyLinks+":"+strconv.Itoa(links))
+	}
+
+	for _, w := range p.StopWords.Match(strings.Join(s.Fields, "\n")) {
+		reasons = append(reasons, ReasonStopWord+":"+w)
+	}
+	return reasons
+}
@@ -0,0 +1,94 @@
+package antispam
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	stopWords, err := ParseStopWords(strings.NewReader("казин*\nбыстрый заработок\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := Policy{MinFillTime: 3 * time.Second, MaxLinks: 1, StopWords: stopWords}
+	human := Submission{Fields: []string{"Отличная машина, рекомендую", "Иван"}, FormAge: 40 * time.Second, FormTokenValid: true}
+
+	tests := []struct {
+		name   string
+		change func(s *Submission)
+		want   []string
+	}{
+		{"human", func(s *Submission) {}, nil},
+		{"honeypot filled", func(s *Submission) { s.Honeypot = "http://spam.example" }, []string{ReasonHoneypot}},
+		{"honeypot with spaces only", func(s *Submission) { s.Honeypot = "  " }, nil},
+		{"no form token", func(s *Submission) { s.FormTokenValid, s.FormAge = false, 0 }, []string{ReasonNoFormToken}},
+		{"filled too fast", func(s *Submission) { s.FormAge = 2 * time.Second }, []string{ReasonTooFast}},
+		{"minimum fill time is enough", func(s *Submission) { s.FormAge = 3 * time.Second }, nil},
+		{"one link is allowed", func(s *Submission) { s.Fields = []string{"Подробнее на renault.ru", "Иван"} }, nil},
+		{"links are counted across fields", func(s *Submission) {
+			s.Fields = []string{"Подробнее на renault.ru", "www.example.com"}
+		}, []string{ReasonTooManyLinks + ":2"}},
+		{"stop words", func(s *Submission) {
+			s.Fields = []string{"Быстрый заработок в казино", "Иван"}
+		},
+			[]string{ReasonStopWord + ":казин*", ReasonStopWord + ":быстрый заработок"}},
+		{"several reasons", func(s *Submission) { s.Honeypot, s.FormAge = "x", time.Second },
+			[]string{ReasonHoneypot, ReasonTooFast}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := human
+			tt.change(&s)
+			if got := p.Check(s); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// без списка стоп-слов остальные проверки работают
+	p.StopWords = nil
+	if got := p.Check(human); got != nil {
+		t.Errorf("Check without stop words = %v, want none", got)
+	}
+}
+
+func TestStopWordsMatch(t *testing.T) {
+	sw, err := ParseStopWords(strings.NewReader(`# комментарий
+Спам
+казин*   # любое слово с этим началом
+быстрый   заработок
+Ёлка
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"СПАМ!", []string{"спам"}},
+		{"спамер", nil}, // без * — только слово целиком
+		{"Казино, казино и казинак", []string{"казин*"}},
+		{"Быстрый, заработок!", []string{"быстрый заработок"}},
+		{"быстрый доход", nil},
+		{"елка", []string{"елка"}},
+		{"комментарий", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := sw.Match(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Match(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+
+	builtin, err := LoadStopWords("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := builtin.Match("Cheap VIAGRA here"); !reflect.DeepEqual(got, []string{"viagra"}) {
+		t.Errorf("built-in list: Match = %v, want [viagra]", got)
+	}
+}
@@ -0,0 +1,54 @@
+package antispam
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// formTokenTTL — дольше форму не заполняют; старый токен считается недействительным
+const formTokenTTL = 24 * time.Hour
+
+// FormTokens выдаёт и проверяет токены форм: время показа формы, подписанное HMAC.
+// По нему сервер узнаёт, сколько заполнялась форма, и клиент не может это подделать.
+type FormTokens struct {
+	secret []byte
+	now    func() time.Time
+}
+
+// NewFormTokens — key используется только для токенов форм
+func NewFormTokens(key []byte) *FormTokens {
+	return &FormTokens{secret: key, now: time.Now}
+}
+
+// Issue — новый токен вида <unix-миллисекунды>.<подпись>
+func (t *FormTokens) Issue() string {
+	ts := strconv.FormatInt(t.now().UnixMilli(), 10)
+	return ts + "." + t.sign(ts)
+}
+
+// Age — сколько прошло с выдачи токена; false — токен пустой, поддельный или просрочен
+func (t *FormTokens) Age(token string) (time.Duration, bool) {
+	ts, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(t.sign(ts))) {
+		return 0, false
+	}
+	ms, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	age := t.now().Sub(time.UnixMilli(ms))
+	if age < 0 || age > formTokenTTL {
+		return 0, false
+	}
+	return age, true
+}
+
+func (t *FormTokens) sign(ts string) string {
+	mac := hmac.New(sha256.New, t.secret)
+	mac.Write([]byte(ts))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,48 @@
+package antispam
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormTokens(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := t0
+	tokens := NewFormTokens([]byte("form-key"))
+	tokens.now = func() time.Time { return clock }
+	token := tokens.Issue()
+	ts, sig, _ := strings.Cut(token, ".")
+
+	other := NewFormTokens([]byte("other-key"))
+	other.now = tokens.now
+
+	tests := []struct {
+		name    string
+		tokens  *FormTokens
+		token   string
+		at      time.Duration
+		wantAge time.Duration
+		wantOK  bool
+	}{
+		{"fresh token", tokens, token, 5 * time.Second, 5 * time.Second, true},
+		{"just before expiry", tokens, token, formTokenTTL, formTokenTTL, true},
+		{"expired", tokens, token, formTokenTTL + time.Second, 0, false},
+		{"issued in the future", tokens, token, -time.Second, 0, false},
+		{"tampered signature", tokens, ts + "." + strings.Repeat("A", len(sig)), time.Second, 0, false},
+		{"tampered time", tokens, "1" + ts + "." + sig, time.Second, 0, false},
+		{"signed with another key", other, token, time.Second, 0, false},
+		{"signed garbage time", tokens, "abc." + tokens.sign("abc"), time.Second, 0, false},
+		{"empty", tokens, "", time.Second, 0, false},
+		{"no signature", tokens, ts, time.Second, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock = t0.Add(tt.at)
+			age, ok := tt.tokens.Age(tt.token)
+			if age != tt.wantAge || ok != tt.wantOK {
+				t.Errorf("Age = %v, %v; want %v, %v", age, ok, tt.wantAge, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package antispam
+
+import (
+	"bufio"
+	_ "embed"
+	"io"
+	"os"
+	"strings"
+)
+
+// defaultStopWords — встроенный список: мат и типичный спам на русском и английском
+//
+//go:embed stopwords.txt
+var defaultStopWords string
+
+// StopWords — слова и фразы, при которых отправка уходит на модерацию.
+// Запись «слово*» совпадает с любым словом, начинающимся с «слово».
+type StopWords struct {
+	exact   map[string]bool
+	phrases []string
+	prefix  []string
+}
+
+// ParseStopWords читает список: по записи на строку, # — комментарий
+func ParseStopWords(r io.Reader) (*StopWords, error) {
+	sw := &StopWords{exact: map[string]bool{}}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		prefix := strings.HasSuffix(strings.TrimSpace(line), "*")
+		entry := Normalize(line)
+		switch {
+		case entry == "":
+		case prefix:
+			sw.prefix = append(sw.prefix, entry)
+		case strings.Contains(entry, " "):
+			sw.phrases = append(sw.phrases, entry)
+		default:
+			sw.exact[entry] = true
+		}
+	}
+	return sw, scanner.Err()
+}
+
+// LoadStopWords — список из файла; пустой path — встроенный список
+func LoadStopWords(path string) (*StopWords, error) {
+	if path == "" {
+		return ParseStopWords(strings.NewReader(defaultStopWords))
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseStopWords(f)
+}
+
+// Match — найденные в тексте записи списка, без повторов
+func (sw *StopWords) Match(text string) []string {
+	if sw == nil {
+		return nil
+	}
+	var found []string
+	seen := map[string]bool{}
+	add := func(entry string) {
+		if !seen[entry] {
+			seen[entry] = true
+			found = append(found, entry)
+		}
+	}
+
+	tokens := words(text)
+	for _, w := range tokens {
+		if sw.exact[w] {
+			add(w)
+		}
+		for _, p := range sw.prefix {
+			if strings.HasPrefix(w, p) {
+				add(p + "*")
+			}
+		}
+	}
+	if len(sw.phrases) > 0 {
+		joined := " " + strings.Join(tokens, " ") + " "
+		for _, p := range sw.phrases {
+			if strings.Contains(joined, " "+p+" ") {
+				add(p)
+			}
+		}
+	}
+	return found
+}
@@ -0,0 +1,58 @@
+package antispam
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// minFingerprintRunes — короче этого текст за дубль не считаем: «Отличная машина!»
+// честно пишут многие
+const minFingerprintRunes = 20
+
+// Normalize приводит текст к виду для сравнения: нижний регистр, ё → е, слова через один пробел
+func Normalize(text string) string {
+	return strings.Join(words(text), " ")
+}
+
+// words — слова текста в нижнем регистре; всё, кроме букв и цифр, — разделители
+func words(text string) []string {
+	text = strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// Fingerprint — отпечаток текста для поиска повторов; пустая строка — текст слишком короткий
+func Fingerprint(text string) string {
+	normalized := Normalize(text)
+	if utf8.RuneCountInString(normalized) < minFingerprintRunes {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// domainRe — «renault.ru», «shop.example.com/path», «сайт.рф»: домен с буквенной зоной
+var domainRe = regexp.MustCompile(`^[\p{L}\p{N}-]+(\.[\p{L}\p{N}-]+)*\.([a-z]{2,}|рф)(/\S*)?$`)
+
+// CountLinks считает ссылки в тексте, в том числе без http:// — спамеры пишут голые домены
+func CountLinks(text string) int {
+	n := 0
+	for _, token := range strings.Fields(strings.ToLower(text)) {
+		token = strings.TrimFunc(token, func(r rune) bool {
+			return unicode.IsPunct(r) && r != '/'
+		})
+		if strings.Contains(token, "@") {
+			continue // email, не ссылка
+		}
+		if strings.HasPrefix(token, "http://") || strings.HasPrefix(token, "https://") ||
+			strings.HasPrefix(token, "www.") || domainRe.MatchString(token) {
+			n++
+		}
+	}
+	return n
+}
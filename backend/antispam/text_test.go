@@ -0,0 +1,53 @@
+package antispam
+
+import "testing"
+
+func TestCountLinks(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"Отличная машина.", 0},
+		{"http://spam.example", 1},
+		{"HTTPS://Spam.Example/path?x=1", 1},
+		{"заходите на www.example.com", 1},
+		{"голый домен renault.ru, и ещё shop.example.com/sale", 2},
+		{"сайт.рф", 1},
+		{"пишите на ivan@mail.ru", 0},
+		{"1.6 л, расход 6.1 л/100км", 0},
+		{"т.е. всё хорошо", 0},
+		{"(см. example.org)", 1},
+	}
+	for _, tt := range tests {
+		if got := CountLinks(tt.text); got != tt.want {
+			t.Errorf("CountLinks(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	base := "Отличная машина, езжу уже год — всё отлично!"
+	tests := []struct {
+		name string
+		a, b string
+		same bool
+	}{
+		{"case, punctuation and spaces", base, "отличная   машина езжу уже год всё отлично", true},
+		{"ё and е", base, "Отличная машина, езжу уже год — все отлично!", true},
+		{"different words", base, "Отличная машина, езжу уже два года — всё отлично!", false},
+	}
+	for _, tt := range tests {
+		a, b := Fingerprint(tt.a), Fingerprint(tt.b)
+		if a == "" || b == "" {
+			t.Fatalf("%s: empty fingerprint", tt.name)
+		}
+		if (a == b) != tt.same {
+			t.Errorf("%s: same = %v, want %v", tt.name, a == b, tt.same)
+		}
+	}
+
+	// короткие тексты честно пишут многие — они не считаются повтором
+	if got := Fingerprint("Отличная машина!"); got != "" {
+		t.Errorf("Fingerprint of a short text = %q, want empty", got)
+	}
+}
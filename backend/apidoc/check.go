@@ -0,0 +1,94 @@
+package apidoc
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gorilla/mux"
+)
+
+// Route — метод и шаблон пути, зарегистрированные в роутере
+type Route struct {
+	Method string
+	Path   string
+}
+
+func (r Route) String() string {
+	return r.Method + " " + r.Path
+}
+
+// Routes — маршруты роутера без регулярных выражений в параметрах. OPTIONS (preflight CORS)
+// и маршруты без методов (раздача файлов по префиксу) не учитываются.
+func Routes(router *mux.Router) ([]Route, error) {
+	seen := map[Route]bool{}
+	var routes []Route
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		tpl, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, m := range methods {
+			r := Route{Method: m, Path: NormalizePath(tpl)}
+			if m == http.MethodOptions || seen[r] {
+				continue
+			}
+			seen[r] = true
+			routes = append(routes, r)
+		}
+		return nil
+	})
+	return routes, err
+}
+
+// Check сверяет маршруты роутера с описанными операциями и возвращает расхождения:
+// маршруты без описания, описания без маршрута и повторные описания
+func Check(routes []Route, ops []Operation) []string {
+	registered := map[Route]bool{}
+	for _, r := range routes {
+		registered[r] = true
+	}
+
+	var problems []string
+	documented := map[Route]bool{}
+	for _, op := range ops {
+		r := Route{Method: op.Method, Path: NormalizePath(op.Path)}
+		if documented[r] {
+			problems = append(problems, "documented twice: "+r.String())
+			continue
+		}
+		documented[r] = true
+		if !registered[r] {
+			problems = append(problems, "documented but not registered: "+r.String())
+		}
+	}
+	for _, r := range routes {
+		if !documented[r] {
+			problems = append(problems, "registered but not documented: "+r.String())
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// CheckFile сравнивает сохранённую спецификацию с собранной из кода:
+// расхождение значит, что изменились типы запросов или ответов, а файл не перегенерирован
+func CheckFile(saved, generated []byte) error {
+	if bytes.Equal(bytes.TrimSpace(saved), bytes.TrimSpace(generated)) {
+		return nil
+	}
+	savedLines := bytes.Split(saved, []byte("\n"))
+	generatedLines := bytes.Split(generated, []byte("\n"))
+	for i := 0; i < len(savedLines) && i < len(generatedLines); i++ {
+		if !bytes.Equal(savedLines[i], generatedLines[i]) {
+			return fmt.Errorf("spec differs from code at line %d:\n  saved:     %s\n  generated: %s",
+				i+1, bytes.TrimSpace(savedLines[i]), bytes.TrimSpace(generatedLines[i]))
+		}
+	}
+	return fmt.Errorf("spec differs from code: saved %d lines, generated %d", len(savedLines), len(generatedLines))
+}
@@ -0,0 +1,35 @@
+package apidoc
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed ui
+var uiFiles embed.FS
+
+// docsCSP — страница документации грузит только свои скрипт, стили и спецификацию
+const docsCSP = "default-src 'none'; script-src 'self'; style-src 'self'; connect-src 'self'; img-src 'self' data:; frame-ancestors 'none'; base-uri 'none'"
+
+// SpecHandler отдаёт готовую спецификацию
+func SpecHandler(spec []byte) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(spec)
+	})
+}
+
+// DocsHandler — страница документации; монтируется с http.StripPrefix по префиксу вида /api/docs/,
+// спецификацию берёт по относительному адресу ../openapi.json
+func DocsHandler() http.Handler {
+	sub, err := fs.Sub(uiFiles, "ui")
+	if err != nil {
+		panic(err)
+	}
+	files := http.FileServerFS(sub)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", docsCSP)
+		files.ServeHTTP(w, r)
+	})
+}
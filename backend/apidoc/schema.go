@@ -0,0 +1,177 @@
+package apidoc
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemas строит JSON Schema по Go-типам так же, как их сериализует encoding/json.
+// Именованные структуры выносятся в components/schemas и подключаются через $ref.
+type schemas struct {
+	components map[string]any
+	names      map[reflect.Type]string
+	taken      map[string]reflect.Type
+}
+
+func newSchemas() *schemas {
+	return &schemas{components: map[string]any{}, names: map[reflect.Type]string{}, taken: map[string]reflect.Type{}}
+}
+
+// of — схема значения v; nil — схемы нет
+func (s *schemas) of(v any) map[string]any {
+	if v == nil {
+		return nil
+	}
+	return s.schema(reflect.TypeOf(v))
+}
+
+func (s *schemas) schema(t reflect.Type) map[string]any {
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case rawMessageType:
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		elem := s.schema(t.Elem())
+		if _, isRef := elem["$ref"]; isRef {
+			return elem
+		}
+		elem["nullable"] = true
+		return elem
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": s.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": s.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + s.component(t)}
+	}
+	// interface{} и прочее — любое значение
+	return map[string]any{}
+}
+
+// component регистрирует именованную структуру и возвращает имя её схемы
+func (s *schemas) component(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+	name := exportedName(t.Name())
+	if other, ok := s.taken[name]; ok && other != t {
+		// одинаковые имена в разных пакетах: main.Car и models.Car
+		pkg := t.PkgPath()
+		name = exportedName(pkg[strings.LastIndex(pkg, "/")+1:]) + name
+	}
+	s.names[t] = name
+	s.taken[name] = t
+	// сначала имя, потом поля: рекурсивные типы ссылаются сами на себя
+	s.components[name] = nil
+	s.components[name] = s.object(t)
+	return name
+}
+
+// object — схема структуры: поля по тегам json, встроенные структуры раскрываются
+func (s *schemas) object(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	var required []string
+	s.fields(t, props, &required)
+	schema := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		schema["required"] = unique(required)
+	}
+	return schema
+}
+
+func (s *schemas) fields(t reflect.Type, props map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				s.fields(ft, props, required)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		schema := s.schema(f.Type)
+		if hasOption(opts, "string") {
+			schema = map[string]any{"type": "string"}
+		}
+		props[name] = schema
+		if !hasOption(opts, "omitempty") && !hasOption(opts, "omitzero") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+func hasOption(opts, option string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// exportedName — имя схемы с заглавной буквы (типы запросов в хендлерах неэкспортируемые)
+func exportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	r := []rune(name)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// unique — без повторов, в исходном порядке (поле встроенной структуры может быть перекрыто)
+func unique(list []string) []string {
+	seen := map[string]bool{}
+	out := list[:0]
+	for _, v := range list {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,253 @@
+// Package apidoc — спецификация OpenAPI 3 для HTTP API: операции описываются в Go рядом
+// с хендлерами, схемы тел строятся по Go-типам, а Check сверяет описание с роутером
+package apidoc
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Operation — один метод одного маршрута
+type Operation struct {
+	Method  string
+	Path    string // как в роутере; регулярные выражения параметров ({id:[0-9]+}) допустимы
+	Tag     string
+	Summary string
+	// Description — подробности: бизнес-правила, коды ошибок и т.п.
+	Description string
+	// Admin — нужен JWT администратора (Authorization: Bearer)
+	Admin bool
+	// Login — нужен JWT пользователя из POST /api/login (Authorization: Bearer)
+	Login bool
+	// User — покупатель определяется заголовком X-User-Id
+	User  bool
+	Query []Param
+	// Request — значение типа тела запроса (nil — без тела); RequestType по умолчанию application/json
+	Request     any
+	RequestType string
+	Responses   []Response
+	// Error — тело ответа с ошибкой; nil — {"error": "..."}, TextError — текст
+	Error any
+}
+
+// Param — параметр строки запроса
+type Param struct {
+	Name        string
+	Type        string // string, integer, number, boolean
+	Description string
+	Required    bool
+}
+
+// Response — успешный ответ; Body nil — без тела, ContentType по умолчанию application/json
+type Response struct {
+	Status      int
+	Description string
+	Body        any
+	ContentType string
+}
+
+// Error — тело ошибки большинства хендлеров (respondWithError)
+type Error struct {
+	Error string `json:"error"`
+}
+
+// TextError — хендлер отвечает на ошибки текстом (http.Error)
+type TextError struct{}
+
+// Q — необязательный параметр строки запроса
+func Q(name, typ, description string) Param {
+	return Param{Name: name, Type: typ, Description: description}
+}
+
+// OK — ответ 200 с JSON-телом
+func OK(body any) []Response {
+	return []Response{{Status: http.StatusOK, Body: body}}
+}
+
+// Info — заголовок спецификации
+type Info struct {
+	Title       string
+	Version     string
+	Description string
+}
+
+// paramRe — параметр пути mux: {id} или {id:[0-9]+}
+var paramRe = regexp.MustCompile(`\{([^{}:]+)(:[^{}]*(\{[^{}]*\}[^{}]*)*)?\}`)
+
+// NormalizePath убирает из шаблона mux регулярные выражения параметров: /leads/{id:[0-9]+} → /leads/{id}
+func NormalizePath(path string) string {
+	return paramRe.ReplaceAllString(path, "{$1}")
+}
+
+func pathParams(path string) []string {
+	var names []string
+	for _, m := range paramRe.FindAllStringSubmatch(path, -1) {
+		names = append(names, m[1])
+	}
+	return names
+}
+
+// Build собирает документ OpenAPI 3.0. Результат детерминирован: одни и те же операции
+// и типы дают байт в байт одинаковый JSON.
+func Build(info Info, ops []Operation) map[string]any {
+	s := newSchemas()
+	paths := map[string]any{}
+	tags := []any{}
+	seenTags := map[string]bool{}
+
+	for _, op := range ops {
+		path := NormalizePath(op.Path)
+		item, ok := paths[path].(map[string]any)
+		if !ok {
+			item = map[string]any{}
+			paths[path] = item
+		}
+		item[strings.ToLower(op.Method)] = buildOperation(s, op)
+
+		if op.Tag != "" && !seenTags[op.Tag] {
+			seenTags[op.Tag] = true
+			tags = append(tags, map[string]any{"name": op.Tag})
+		}
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       info.Title,
+			"version":     info.Version,
+			"description": info.Description,
+		},
+		"tags":  tags,
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": s.components,
+			"securitySchemes": map[string]any{
+				"adminJWT": map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+				"userJWT":  map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+	}
+}
+
+func buildOperation(s *schemas, op Operation) map[string]any {
+	out := map[string]any{
+		"summary":     op.Summary,
+		"operationId": operationID(op),
+	}
+	if op.Description != "" {
+		out["description"] = op.Description
+	}
+	if op.Tag != "" {
+		out["tags"] = []string{op.Tag}
+	}
+	if op.Admin {
+		out["security"] = []any{map[string]any{"adminJWT": []string{}}}
+	}
+	if op.Login {
+		out["security"] = []any{map[string]any{"userJWT": []string{}}}
+	}
+
+	params := []any{}
+	for _, name := range pathParams(op.Path) {
+		params = append(params, map[string]any{
+			"name": name, "in": "path", "required": true, "schema": map[string]any{"type": "string"},
+		})
+	}
+	if op.User {
+		params = append(params, map[string]any{
+			"name": "X-User-Id", "in": "header", "required": true,
+			"description": "идентификатор покупателя", "schema": map[string]any{"type": "string"},
+		})
+	}
+	for _, p := range op.Query {
+		param := map[string]any{"name": p.Name, "in": "query", "schema": map[string]any{"type": p.Type}}
+		if p.Description != "" {
+			param["description"] = p.Description
+		}
+		if p.Required {
+			param["required"] = true
+		}
+		params = append(params, param)
+	}
+	if len(params) > 0 {
+		out["parameters"] = params
+	}
+
+	if op.Request != nil {
+		contentType := op.RequestType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		out["requestBody"] = map[string]any{
+			"required": true,
+			"content":  map[string]any{contentType: map[string]any{"schema": s.of(op.Request)}},
+		}
+	}
+
+	responses := map[string]any{}
+	for _, r := range op.Responses {
+		// один код с разными форматами (JSON и CSV) — один ответ с несколькими content
+		resp, ok := responses[strconv.Itoa(r.Status)].(map[string]any)
+		if !ok {
+			resp = map[string]any{"description": r.Description}
+			if r.Description == "" {
+				resp["description"] = http.StatusText(r.Status)
+			}
+			responses[strconv.Itoa(r.Status)] = resp
+		}
+		if r.Body != nil {
+			contentType := r.ContentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			content, ok := resp["content"].(map[string]any)
+			if !ok {
+				content = map[string]any{}
+				resp["content"] = content
+			}
+			content[contentType] = map[string]any{"schema": s.of(r.Body)}
+		}
+	}
+	switch op.Error.(type) {
+	case TextError:
+		responses["default"] = map[string]any{
+			"description": "Ошибка",
+			"content":     map[string]any{"text/plain": map[string]any{"schema": map[string]any{"type": "string"}}},
+		}
+	default:
+		body := op.Error
+		if body == nil {
+			body = Error{}
+		}
+		responses["default"] = map[string]any{
+			"description": "Ошибка",
+			"content":     map[string]any{"application/json": map[string]any{"schema": s.of(body)}},
+		}
+	}
+	out["responses"] = responses
+	return out
+}
+
+// operationID — «getApiCarsId» из GET /api/cars/{id}
+func operationID(op Operation) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(op.Method))
+	for _, part := range strings.FieldsFunc(NormalizePath(op.Path), func(r rune) bool {
+		return r == '/' || r == '{' || r == '}' || r == '-' || r == '.'
+	}) {
+		b.WriteString(exportedName(part))
+	}
+	return b.String()
+}
+
+// Marshal — спецификация в виде отформатированного JSON
+func Marshal(doc map[string]any) ([]byte, error) {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,104 @@
+// Страница документации: читает /api/openapi.json и показывает операции по разделам
+(function () {
+    'use strict';
+
+    const el = (tag, attrs, ...children) => {
+        const node = document.createElement(tag);
+        Object.entries(attrs || {}).forEach(([k, v]) => { node[k] = v; });
+        children.flat().forEach(c => node.append(c instanceof Node ? c : document.createTextNode(String(c))));
+        return node;
+    };
+
+    // схемы показываем с раскрытыми $ref; повторный тип внутри себя не раскрываем
+    function resolve(schema, spec, seen) {
+        if (!schema || typeof schema !== 'object') return schema;
+        if (schema.$ref) {
+            const name = schema.$ref.split('/').pop();
+            if (seen.includes(name)) return '→ ' + name;
+            return resolve(spec.components.schemas[name], spec, seen.concat(name));
+        }
+        if (schema.type === 'object' && schema.properties) {
+            const out = {};
+            const required = schema.required || [];
+            Object.keys(schema.properties).sort().forEach(key => {
+                out[required.includes(key) ? key : key + '?'] = resolve(schema.properties[key], spec, seen);
+            });
+            return out;
+        }
+        if (schema.type === 'object' && schema.additionalProperties) {
+            return { '<key>': resolve(schema.additionalProperties, spec, seen) };
+        }
+        if (schema.type === 'array') return [resolve(schema.items, spec, seen)];
+        if (schema.type) return schema.type + (schema.format ? ' (' + schema.format + ')' : '') + (schema.nullable ? ' | null' : '');
+        return 'any';
+    }
+
+    function schemaBlock(content, spec) {
+        return Object.entries(content || {}).map(([type, media]) =>
+            el('div', {}, el('span', { className: 'muted' }, type),
+                el('pre', {}, JSON.stringify(resolve(media.schema, spec, []), null, 2))));
+    }
+
+    function operation(path, method, op, spec) {
+        const body = el('div', { className: 'body' });
+        if (op.description) body.append(el('p', {}, op.description));
+        if (op.parameters && op.parameters.length) {
+            body.append(el('h4', {}, 'Параметры'), el('table', {},
+                op.parameters.map(p => el('tr', {},
+                    el('td', {}, el('code', {}, p.name)),
+                    el('td', { className: 'muted' }, p.in + (p.required ? ', обязателен' : '')),
+                    el('td', {}, p.schema ? p.schema.type : ''),
+                    el('td', {}, p.description || '')))));
+        }
+        if (op.requestBody) body.append(el('h4', {}, 'Тело запроса'), schemaBlock(op.requestBody.content, spec));
+        Object.entries(op.responses || {}).forEach(([status, resp]) => {
+            body.append(el('h4', {}, 'Ответ ' + status + ' — ' + resp.description), schemaBlock(resp.content, spec));
+        });
+
+        const details = el('details', {},
+            el('summary', {},
+                el('span', { className: 'method ' + method }, method.toUpperCase()),
+                el('span', { className: 'path' }, path),
+                el('span', { className: 'summary' }, op.summary || ''),
+                op.security ? el('span', { className: 'lock', title: 'нужен токен администратора' }, '🔒 admin') : ''),
+            body);
+        details.dataset.search = (method + ' ' + path + ' ' + (op.summary || '') + ' ' + (op.description || '')).toLowerCase();
+        return details;
+    }
+
+    function render(spec) {
+        document.title = spec.info.title;
+        document.getElementById('title').textContent = spec.info.title + ' ' + spec.info.version;
+        document.getElementById('description').textContent = spec.info.description || '';
+
+        const byTag = new Map((spec.tags || []).map(t => [t.name, []]));
+        Object.entries(spec.paths).forEach(([path, item]) => {
+            Object.entries(item).forEach(([method, op]) => {
+                const tag = (op.tags && op.tags[0]) || 'other';
+                if (!byTag.has(tag)) byTag.set(tag, []);
+                byTag.get(tag).push(operation(path, method, op, spec));
+            });
+        });
+
+        const root = document.getElementById('operations');
+        root.replaceChildren();
+        byTag.forEach((ops, tag) => {
+            if (ops.length) root.append(el('section', {}, el('h2', {}, tag), ops));
+        });
+    }
+
+    document.getElementById('filter').addEventListener('input', e => {
+        const q = e.target.value.trim().toLowerCase();
+        document.querySelectorAll('details').forEach(d => { d.hidden = q !== '' && !d.dataset.search.includes(q); });
+        document.querySelectorAll('section').forEach(s => {
+            s.hidden = !Array.from(s.querySelectorAll('details')).some(d => !d.hidden);
+        });
+    });
+
+    fetch('../openapi.json')
+        .then(resp => { if (!resp.ok) throw new Error('HTTP ' + resp.status); return resp.json(); })
+        .then(render)
+        .catch(err => {
+            document.getElementById('operations').replaceChildren(el('p', {}, 'Не удалось загрузить спецификацию: ' + err.message));
+        });
+})();
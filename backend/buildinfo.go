@@ -0,0 +1,32 @@
+package main
+
+import (
+	"runtime/debug"
+
+	"renault-backend/handlers"
+)
+
+// задаются при сборке:
+// go build -ldflags "-X main.version=1.4.0 -X main.commit=$(git rev-parse --short HEAD)"
+var (
+	version = "dev"
+	commit  = ""
+)
+
+// buildInfo — версия и коммит сборки; без -ldflags коммит берётся из сведений VCS,
+// которые go build встраивает сам при сборке из git-репозитория
+func buildInfo() handlers.BuildInfo {
+	info := handlers.BuildInfo{Version: version, Commit: commit}
+	if info.Commit != "" {
+		return info
+	}
+	info.Commit = "unknown"
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			if s.Key == "vcs.revision" && len(s.Value) >= 7 {
+				info.Commit = s.Value[:7]
+			}
+		}
+	}
+	return info
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"renault-backend/apidoc"
+	"renault-backend/database"
+	"renault-backend/fixtures"
+	"renault-backend/models"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+const cliUsage = `Использование:
+  server                                    запустить HTTP-сервер
+  server catalog export [-format json|csv] [-o файл]
+  server catalog import [-format json|csv] [-dry-run] [-atomic] [-author имя] файл
+  server seed [-env dev|demo|test] [-dry-run]
+  server openapi [-o файл]                  спецификация OpenAPI (по умолчанию stdout)
+  server openapi check [файл]               сверить роутер и сохранённую спецификацию
+                                            (по умолчанию docs/openapi.json) с кодом
+`
+
+// runCommand выполняет подкоманду CLI и возвращает код выхода
+func runCommand(args []string, catalogIO *database.CatalogIORepository, promos *database.PromotionRepository) int {
+	if len(args) >= 1 && args[0] == "seed" {
+		return seedCommand(args[1:], catalogIO, promos)
+	}
+	if len(args) >= 2 && args[0] == "catalog" {
+		switch args[1] {
+		case "export":
+			return catalogExportCommand(args[2:], catalogIO)
+		case "import":
+			return catalogImportCommand(args[2:], catalogIO)
+		}
+	}
+	fmt.Fprint(os.Stderr, cliUsage)
+	return 2
+}
+
+func catalogExportCommand(args []string, repo *database.CatalogIORepository) int {
+	fs := flag.NewFlagSet("catalog export", flag.ContinueOnError)
+	format := fs.String("format", "", "json или csv (по умолчанию — по расширению -o, иначе json)")
+	out := fs.String("o", "", "файл для выгрузки (по умолчанию stdout)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *format == "" {
+		*format = formatFromPath(*out)
+	}
+
+	export, err := repo.Export()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "export:", err)
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "export:", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch *format {
+	case "csv":
+		err = models.WriteCatalogCSV(w, export.Cars)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(export)
+	default:
+		err = fmt.Errorf("неизвестный формат %q", *format)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "export:", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "exported %d car(s)\n", len(export.Cars))
+	return 0
+}
+
+func catalogImportCommand(args []string, repo *database.CatalogIORepository) int {
+	fs := flag.NewFlagSet("catalog import", flag.ContinueOnError)
+	format := fs.String("format", "", "json или csv (по умолчанию — по расширению файла)")
+	dryRun := fs.Bool("dry-run", false, "только показать изменения")
+	atomic := fs.Bool("atomic", false, "всё или ничего: при любой ошибке ничего не применять")
+	author := fs.String("author", "cli", "автор изменений для истории цен")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprint(os.Stderr, cliUsage)
+		return 2
+	}
+	path := fs.Arg(0)
+	if *format == "" {
+		*format = formatFromPath(path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import:", err)
+		return 1
+	}
+	defer f.Close()
+
+	cars, rows, parseErrs, err := models.ReadCatalog(f, *format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import:", err)
+		return 1
+	}
+	report, err := repo.Import(cars, rows, parseErrs, models.ImportOptions{
+		DryRun: *dryRun, Atomic: *atomic, Author: *author,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import:", err)
+		return 1
+	}
+
+	for _, row := range report.Rows {
+		switch row.Action {
+		case models.ImportError:
+			fmt.Printf("row %d %s: ERROR %s\n", row.Row, row.ID, row.Error)
+		case models.ImportUnchanged:
+		default:
+			fmt.Printf("row %d %s: %s %s\n", row.Row, row.ID, row.Action, row.Diff)
+		}
+	}
+	fmt.Printf("created %d, updated %d, unchanged %d, failed %d; applied: %v\n",
+		report.Created, report.Updated, report.Unchanged, report.Failed, report.Applied)
+
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func seedCommand(args []string, catalogIO *database.CatalogIORepository, promos *database.PromotionRepository) int {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	env := fs.String("env", "dev", "окружение: "+strings.Join(fixtures.EnvNames(), ", "))
+	dryRun := fs.Bool("dry-run", false, "только показать изменения")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	res, err := fixtures.Load(*env, catalogIO, promos, *dryRun)
+	if res != nil {
+		for _, report := range res.Catalog {
+			fmt.Printf("cars: created %d, updated %d, unchanged %d, failed %d; applied: %v\n",
+				report.Created, report.Updated, report.Unchanged, report.Failed, report.Applied)
+			for _, row := range report.Rows {
+				if row.Action == models.ImportError {
+					fmt.Printf("row %d %s: ERROR %s\n", row.Row, row.ID, row.Error)
+				}
+			}
+		}
+		if res.PromotionsCreated+res.PromotionsUpdated > 0 {
+			fmt.Printf("promotions: created %d, updated %d\n", res.PromotionsCreated, res.PromotionsUpdated)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "seed:", err)
+		return 1
+	}
+	return 0
+}
+
+func formatFromPath(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return "csv"
+	}
+	return "json"
+}
+
+// openapiCommand — `server openapi`: печатает спецификацию или (check) проверяет,
+// что все маршруты роутера описаны, а сохранённый файл совпадает с собранным из кода
+func openapiCommand(args []string, router *mux.Router, spec []byte) int {
+	if len(args) >= 1 && args[0] == "check" {
+		return openapiCheckCommand(args[1:], router, spec)
+	}
+
+	fs := flag.NewFlagSet("openapi", flag.ContinueOnError)
+	out := fs.String("o", "", "файл для спецификации (по умолчанию stdout)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprint(os.Stderr, cliUsage)
+		return 2
+	}
+
+	if *out == "" {
+		os.Stdout.Write(spec)
+		return 0
+	}
+	if err := os.WriteFile(*out, spec, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "openapi:", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "spec written to %s\n", *out)
+	return 0
+}
+
+func openapiCheckCommand(args []string, router *mux.Router, spec []byte) int {
+	path := specPath
+	if len(args) > 1 {
+		fmt.Fprint(os.Stderr, cliUsage)
+		return 2
+	}
+	if len(args) == 1 {
+		path = args[0]
+	}
+
+	problems, err := checkAPISpec(router)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "openapi check:", err)
+		return 1
+	}
+	for _, p := range problems {
+		fmt.Fprintln(os.Stderr, p)
+	}
+
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "openapi check:", err)
+		return 1
+	}
+	if err := apidoc.CheckFile(saved, spec); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintf(os.Stderr, "regenerate it with `server openapi -o %s`\n", path)
+		return 1
+	}
+	if len(problems) > 0 {
+		return 1
+	}
+	fmt.Fprintln(os.Stderr, "openapi: spec is up to date")
+	return 0
+}
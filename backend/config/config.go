@@ -1,8 +1,10 @@
 package config
 
 import (
-	"log"
+	"log/slog"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -15,12 +17,77 @@ type Config struct {
 	DBName     string
 	JWTSecret  string
 	Port       string
+
+	// Сколько часов лид может пролежать без реакции менеджера
+	LeadSLAHours int
+
+	// Корзина без изменений дольше CartExpiryDays удаляется,
+	// после CartAbandonedHours бездействия пользователю уходит напоминание
+	CartExpiryDays     int
+	CartAbandonedHours int
+
+	// Канал доставки уведомлений: log или file (NotificationFile)
+	NotificationSink string
+	NotificationFile string
+
+	// Сколько дней хранить журнал аудита; 0 — хранить бессрочно
+	AuditRetentionDays int
+
+	// Сколько дней удалённый автомобиль лежит в корзине до окончательного удаления
+	CarTrashRetentionDays int
+
+	// Сколько событий аналитики может ждать записи в БД; сверх этого события отбрасываются
+	AnalyticsBufferSize int
+
+	// Журнал: уровень debug|info|warn|error и формат json|text
+	LogLevel  string
+	LogFormat string
+
+	// Таймауты HTTP-сервера и время на завершение начатых запросов при остановке, в секундах
+	HTTPReadTimeoutSeconds  int
+	HTTPWriteTimeoutSeconds int
+	HTTPIdleTimeoutSeconds  int
+	ShutdownTimeoutSeconds  int
+
+	// Сертификат и ключ в PEM; если заданы оба, сервер принимает только HTTPS
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// Источники (scheme://host[:port]), которым разрешены запросы из браузера, через запятую.
+	// Свой список на каждое окружение; "*" — любой источник, но только без credentials.
+	CORSAllowedOrigins   []string
+	CORSAllowCredentials bool
+
+	// Токен для GET /metrics (Authorization: Bearer); пусто — метрики закрыты
+	MetricsToken string
+
+	// Content-Security-Policy для всех ответов и срок HSTS в секундах (0 — без HSTS)
+	ContentSecurityPolicy string
+	HSTSMaxAgeSeconds     int
+
+	// Лимиты публичных маршрутов в формате "<запросов>/<период>", например "10/1m"; "off" — без лимита
+	RateLimitRegister         string
+	RateLimitLogin            string
+	RateLimitValidatePassword string
+	RateLimitReviews          string
+	RateLimitEvents           string
+	// Сколько клиентов помнить в памяти и заголовок с адресом клиента от доверенного прокси;
+	// только при нём учитывается и X-Forwarded-Proto (HSTS за прокси, завершающим TLS)
+	RateLimitMaxKeys int
+	RealIPHeader     string
+
+	// Антиспам отзывов и заявок: минимальное время заполнения формы, допустимое число ссылок,
+	// файл стоп-слов (пусто — встроенный список) и окно поиска повторов текста
+	SpamMinFillSeconds      int
+	SpamMaxLinks            int
+	SpamStopWordsFile       string
+	SpamDuplicateWindowDays int
 }
 
 func LoadConfig() *Config {
 	err := godotenv.Load()
 	if err != nil {
-		log.Println("Warning: .env file not found, using environment variables")
+		slog.Warn(".env file not found, using environment variables")
 	}
 
 	return &Config{
@@ -31,6 +98,52 @@ func LoadConfig() *Config {
 		DBName:     getEnv("DB_NAME", "renault_db"),
 		JWTSecret:  getEnv("JWT_SECRET", "default_jwt_secret"),
 		Port:       getEnv("PORT", "8080"),
+
+		LeadSLAHours: getEnvInt("LEAD_SLA_HOURS", 2),
+
+		CartExpiryDays:     getEnvInt("CART_EXPIRY_DAYS", 30),
+		CartAbandonedHours: getEnvInt("CART_ABANDONED_HOURS", 24),
+
+		NotificationSink: getEnv("NOTIFICATION_SINK", "log"),
+		NotificationFile: getEnv("NOTIFICATION_FILE", "notifications.log"),
+
+		AuditRetentionDays: getEnvInt("AUDIT_RETENTION_DAYS", 365),
+
+		CarTrashRetentionDays: getEnvInt("CAR_TRASH_RETENTION_DAYS", 30),
+
+		AnalyticsBufferSize: getEnvInt("ANALYTICS_BUFFER_SIZE", 1000),
+
+		LogLevel:  getEnv("LOG_LEVEL", "info"),
+		LogFormat: getEnv("LOG_FORMAT", "json"),
+
+		HTTPReadTimeoutSeconds:  getEnvInt("HTTP_READ_TIMEOUT_SECONDS", 15),
+		HTTPWriteTimeoutSeconds: getEnvInt("HTTP_WRITE_TIMEOUT_SECONDS", 60),
+		HTTPIdleTimeoutSeconds:  getEnvInt("HTTP_IDLE_TIMEOUT_SECONDS", 120),
+		ShutdownTimeoutSeconds:  getEnvInt("SHUTDOWN_TIMEOUT_SECONDS", 20),
+
+		TLSCertFile: getEnv("TLS_CERT_FILE", ""),
+		TLSKeyFile:  getEnv("TLS_KEY_FILE", ""),
+
+		CORSAllowedOrigins:   getEnvList("CORS_ALLOWED_ORIGINS"),
+		CORSAllowCredentials: getEnvBool("CORS_ALLOW_CREDENTIALS", false),
+
+		MetricsToken: getEnv("METRICS_TOKEN", ""),
+
+		ContentSecurityPolicy: getEnv("CONTENT_SECURITY_POLICY", "default-src 'none'; frame-ancestors 'none'; base-uri 'none'"),
+		HSTSMaxAgeSeconds:     getEnvInt("HSTS_MAX_AGE_SECONDS", 31536000),
+
+		RateLimitRegister:         getEnv("RATE_LIMIT_REGISTER", "5/1h"),
+		RateLimitLogin:            getEnv("RATE_LIMIT_LOGIN", "10/1m"),
+		RateLimitValidatePassword: getEnv("RATE_LIMIT_VALIDATE_PASSWORD", "30/1m"),
+		RateLimitReviews:          getEnv("RATE_LIMIT_REVIEWS", "5/10m"),
+		RateLimitEvents:           getEnv("RATE_LIMIT_EVENTS", "60/1m"),
+		RateLimitMaxKeys:          getEnvInt("RATE_LIMIT_MAX_KEYS", 100000),
+		RealIPHeader:              getEnv("REAL_IP_HEADER", ""),
+
+		SpamMinFillSeconds:      getEnvInt("SPAM_MIN_FILL_SECONDS", 3),
+		SpamMaxLinks:            getEnvInt("SPAM_MAX_LINKS", 1),
+		SpamStopWordsFile:       getEnv("SPAM_STOP_WORDS_FILE", ""),
+		SpamDuplicateWindowDays: getEnvInt("SPAM_DUPLICATE_WINDOW_DAYS", 30),
 	}
 }
 
@@ -40,3 +153,40 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		slog.Warn("config value is not a number, using default", "key", key, "value", value, "default", defaultValue)
+		return defaultValue
+	}
+	return n
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		slog.Warn("config value is not a boolean, using default", "key", key, "value", value, "default", defaultValue)
+		return defaultValue
+	}
+	return b
+}
+
+// getEnvList — значения через запятую без пробелов и пустых элементов
+func getEnvList(key string) []string {
+	var list []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
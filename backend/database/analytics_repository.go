@@ -0,0 +1,299 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"renault-backend/models"
+	"strings"
+)
+
+// analyticsDayLayout — формат дня в таблицах аналитики (UTC)
+const analyticsDayLayout = "2006-01-02"
+
+// createAnalyticsTables — сырые события и дневные свёртки для отчётов.
+// Отчёты читают только свёртки; их пересчитывает RefreshRollups.
+func createAnalyticsTables(logger *slog.Logger) error {
+	_, err := DB.Exec(`
+	CREATE TABLE IF NOT EXISTS analytics_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		type TEXT NOT NULL,
+		car_id TEXT NOT NULL DEFAULT '',
+		user_id TEXT NOT NULL DEFAULT '',
+		rating INTEGER NOT NULL DEFAULT 0,
+		occurred_at TIMESTAMP NOT NULL,
+		day TEXT NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_analytics_events_day ON analytics_events (day);
+	-- совместные просмотры для рекомендаций, см. RecommendationRepository.AlsoViewed
+	CREATE INDEX IF NOT EXISTS idx_analytics_events_viewer ON analytics_events (type, user_id, car_id);
+	CREATE INDEX IF NOT EXISTS idx_analytics_events_car ON analytics_events (type, car_id, user_id);
+
+	-- просмотры раньше писались ещё и сюда; единственный источник теперь — события car_viewed
+	DROP TABLE IF EXISTS car_views;
+
+	CREATE TABLE IF NOT EXISTS analytics_car_daily (
+		day TEXT NOT NULL,
+		car_id TEXT NOT NULL,
+		views INTEGER NOT NULL DEFAULT 0,
+		cart_adds INTEGER NOT NULL DEFAULT 0,
+		leads INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (day, car_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS analytics_rating_daily (
+		day TEXT NOT NULL,
+		rating INTEGER NOT NULL,
+		reviews INTEGER NOT NULL,
+		PRIMARY KEY (day, rating)
+	);
+
+	-- до какого события свёртки актуальны
+	CREATE TABLE IF NOT EXISTS analytics_rollup_state (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		last_event_id INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("error creating analytics tables: %v", err)
+	}
+	logger.Debug("analytics tables ready")
+	return nil
+}
+
+type AnalyticsRepository struct {
+	db *sql.DB
+}
+
+func NewAnalyticsRepository() *AnalyticsRepository {
+	return &AnalyticsRepository{db: DB}
+}
+
+// PublishedCars отмечает, какие из ids — опубликованные автомобили каталога
+func (r *AnalyticsRepository) PublishedCars(ids []string) (map[string]bool, error) {
+	published := map[string]bool{}
+	if len(ids) == 0 {
+		return published, nil
+	}
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	rows, err := r.db.Query(`
+		SELECT id FROM catalog.cars
+		WHERE deleted_at IS NULL AND status = 'published'
+		  AND id IN (?`+strings.Repeat(", ?", len(ids)-1)+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		published[id] = true
+	}
+	return published, rows.Err()
+}
+
+// InsertEvents сохраняет пачку событий одной транзакцией
+func (r *AnalyticsRepository) InsertEvents(events []models.AnalyticsEvent) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO analytics_events (type, car_id, user_id, rating, occurred_at, day)
+		VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, e := range events {
+		at := e.OccurredAt.UTC()
+		if _, err := stmt.Exec(e.Type, e.CarID, e.UserID, e.Rating, at, at.Format(analyticsDayLayout)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// RefreshRollups пересчитывает дневные свёртки за дни, в которые с прошлого пересчёта
+// пришли новые события (в том числе опоздавшие), и возвращает число новых событий
+func (r *AnalyticsRepository) RefreshRollups() (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var lastID int64
+	err = tx.QueryRow(`SELECT last_event_id FROM analytics_rollup_state WHERE id = 1`).Scan(&lastID)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	var fresh int
+	var maxID sql.NullInt64
+	var fromDay sql.NullString
+	if err := tx.QueryRow(`SELECT COUNT(*), MAX(id), MIN(day) FROM analytics_events WHERE id > ?`, lastID).
+		Scan(&fresh, &maxID, &fromDay); err != nil {
+		return 0, err
+	}
+	if fresh == 0 {
+		return 0, nil
+	}
+
+	stmts := []string{
+		`DELETE FROM analytics_car_daily WHERE day >= ?`,
+		`INSERT INTO analytics_car_daily (day, car_id, views, cart_adds, leads)
+		 SELECT day, car_id,
+		        SUM(type = '` + models.EventCarViewed + `'),
+		        SUM(type = '` + models.EventAddedToCart + `'),
+		        SUM(type = '` + models.EventLeadCreated + `')
+		 FROM analytics_events
+		 WHERE day >= ? AND car_id <> '' AND type IN ('` + models.EventCarViewed + `', '` +
+			models.EventAddedToCart + `', '` + models.EventLeadCreated + `')
+		 GROUP BY day, car_id`,
+		`DELETE FROM analytics_rating_daily WHERE day >= ?`,
+		`INSERT INTO analytics_rating_daily (day, rating, reviews)
+		 SELECT day, rating, COUNT(*) FROM analytics_events
+		 WHERE day >= ? AND type = '` + models.EventReviewSubmitted + `'
+		 GROUP BY day, rating`,
+	}
+	for _, q := range stmts {
+		if _, err := tx.Exec(q, fromDay.String); err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO analytics_rollup_state (id, last_event_id) VALUES (1, ?)
+		ON CONFLICT (id) DO UPDATE SET last_event_id = excluded.last_event_id`, maxID.Int64); err != nil {
+		return 0, err
+	}
+	return fresh, tx.Commit()
+}
+
+// ViewsPerCar — просмотры моделей по дням; carID — только одна модель
+func (r *AnalyticsRepository) ViewsPerCar(rng models.AnalyticsRange, carID string) ([]models.CarViewsDay, error) {
+	query := `
+		SELECT d.day, d.car_id, COALESCE(c.title, d.car_id), d.views
+		FROM analytics_car_daily d
+		LEFT JOIN catalog.cars c ON c.id = d.car_id
+		WHERE d.day BETWEEN ? AND ? AND d.views > 0`
+	args := []any{rng.From, rng.To}
+	if carID != "" {
+		query += ` AND d.car_id = ?`
+		args = append(args, carID)
+	}
+	rows, err := r.db.Query(query+` ORDER BY d.day, d.views DESC, d.car_id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []models.CarViewsDay{}
+	for rows.Next() {
+		var v models.CarViewsDay
+		if err := rows.Scan(&v.Day, &v.CarID, &v.Title, &v.Views); err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+	}
+	return result, rows.Err()
+}
+
+// Funnel — воронка «просмотр → корзина → заявка» за период, итог и по моделям
+func (r *AnalyticsRepository) Funnel(rng models.AnalyticsRange) (*models.ConversionFunnel, error) {
+	rows, err := r.db.Query(`
+		SELECT d.car_id, COALESCE(c.title, d.car_id), SUM(d.views), SUM(d.cart_adds), SUM(d.leads)
+		FROM analytics_car_daily d
+		LEFT JOIN catalog.cars c ON c.id = d.car_id
+		WHERE d.day BETWEEN ? AND ?
+		GROUP BY d.car_id
+		ORDER BY SUM(d.views) DESC, d.car_id`, rng.From, rng.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	funnel := &models.ConversionFunnel{From: rng.From, To: rng.To, ByCar: []models.FunnelStep{}}
+	for rows.Next() {
+		var s models.FunnelStep
+		if err := rows.Scan(&s.CarID, &s.Title, &s.Views, &s.CartAdds, &s.Leads); err != nil {
+			return nil, err
+		}
+		funnel.Total.Views += s.Views
+		funnel.Total.CartAdds += s.CartAdds
+		funnel.Total.Leads += s.Leads
+		funnel.ByCar = append(funnel.ByCar, s.WithRates())
+	}
+	funnel.Total = funnel.Total.WithRates()
+	return funnel, rows.Err()
+}
+
+// TopCategories — категории каталога по числу просмотров за период
+func (r *AnalyticsRepository) TopCategories(rng models.AnalyticsRange, limit int) ([]models.CategoryStats, error) {
+	rows, err := r.db.Query(`
+		SELECT COALESCE(c.category, ''), SUM(d.views), SUM(d.cart_adds), SUM(d.leads)
+		FROM analytics_car_daily d
+		JOIN catalog.cars c ON c.id = d.car_id
+		WHERE d.day BETWEEN ? AND ?
+		GROUP BY 1
+		ORDER BY 2 DESC, 3 DESC, 1
+		LIMIT ?`, rng.From, rng.To, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []models.CategoryStats{}
+	for rows.Next() {
+		var s models.CategoryStats
+		if err := rows.Scan(&s.Category, &s.Views, &s.CartAdds, &s.Leads); err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+	return result, rows.Err()
+}
+
+// Ratings — отзывы по дням со средней оценкой (отзывы без оценки в среднее не входят)
+func (r *AnalyticsRepository) Ratings(rng models.AnalyticsRange) ([]models.RatingsDay, error) {
+	rows, err := r.db.Query(`
+		SELECT day, rating, reviews FROM analytics_rating_daily
+		WHERE day BETWEEN ? AND ?
+		ORDER BY day, rating`, rng.From, rng.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []models.RatingsDay{}
+	sum := 0
+	for rows.Next() {
+		var day string
+		var rating, reviews int
+		if err := rows.Scan(&day, &rating, &reviews); err != nil {
+			return nil, err
+		}
+		if n := len(result); n == 0 || result[n-1].Day != day {
+			sum = 0
+			result = append(result, models.RatingsDay{Day: day, Distribution: map[int]int{}})
+		}
+		d := &result[len(result)-1]
+		d.Reviews += reviews
+		if rating > 0 {
+			d.Rated += reviews
+			d.Distribution[rating] += reviews
+			sum += rating * reviews
+			d.Average = float64(sum) / float64(d.Rated)
+		}
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"renault-backend/models"
+	"time"
+)
+
+func createAuditTables(logger *slog.Logger) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS audit_log (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		actor TEXT NOT NULL,
+		action TEXT NOT NULL,
+		entity TEXT NOT NULL,
+		entity_id TEXT NOT NULL DEFAULT '',
+		before_json TEXT,
+		after_json TEXT,
+		diff_json TEXT,
+		ip TEXT NOT NULL DEFAULT '',
+		path TEXT NOT NULL DEFAULT '',
+		status INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMP NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log (created_at);
+	CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log (entity, entity_id);
+
+	-- журнал только дописывается; удаление разрешено лишь для очистки по сроку хранения
+	CREATE TRIGGER IF NOT EXISTS audit_log_append_only
+	BEFORE UPDATE ON audit_log
+	BEGIN
+		SELECT RAISE(ABORT, 'audit_log is append-only');
+	END`
+	_, err := DB.Exec(query)
+	if err != nil {
+		return fmt.Errorf("error creating audit_log table: %v", err)
+	}
+	logger.Debug("audit log table ready")
+	return nil
+}
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository() *AuditRepository {
+	return &AuditRepository{db: DB}
+}
+
+func nullableJSON(b []byte) any {
+	if len(b) == 0 {
+		return nil
+	}
+	return string(b)
+}
+
+// Record дописывает запись в журнал
+func (r *AuditRepository) Record(e *models.AuditEntry) error {
+	if e.At.IsZero() {
+		e.At = time.Now().UTC()
+	}
+	res, err := r.db.Exec(`
+		INSERT INTO audit_log (actor, action, entity, entity_id, before_json, after_json, diff_json, ip, path, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.Actor, e.Action, e.Entity, e.EntityID,
+		nullableJSON(e.Before), nullableJSON(e.After), nullableJSON(e.Diff),
+		e.IP, e.Path, e.Status, e.At.UTC())
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	e.ID = int(id)
+	return err
+}
+
+// List возвращает записи журнала, новые первыми
+func (r *AuditRepository) List(f models.AuditFilter) ([]models.AuditEntry, error) {
+	query := `
+		SELECT id, actor, action, entity, entity_id, before_json, after_json, diff_json, ip, path, status, created_at
+		FROM audit_log WHERE 1 = 1`
+	var args []any
+
+	if f.Actor != "" {
+		query += ` AND actor = ?`
+		args = append(args, f.Actor)
+	}
+	if f.Action != "" {
+		query += ` AND action = ?`
+		args = append(args, f.Action)
+	}
+	if f.Entity != "" {
+		query += ` AND entity = ?`
+		args = append(args, f.Entity)
+	}
+	if f.EntityID != "" {
+		query += ` AND entity_id = ?`
+		args = append(args, f.EntityID)
+	}
+	if f.From != nil {
+		query += ` AND created_at >= ?`
+		args = append(args, f.From.UTC())
+	}
+	if f.To != nil {
+		query += ` AND created_at < ?`
+		args = append(args, f.To.UTC())
+	}
+	query += ` ORDER BY id DESC LIMIT ? OFFSET ?`
+	args = append(args, f.Limit, f.Offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var e models.AuditEntry
+		var before, after, diff sql.NullString
+		if err := rows.Scan(&e.ID, &e.Actor, &e.Action, &e.Entity, &e.EntityID,
+			&before, &after, &diff, &e.IP, &e.Path, &e.Status, &e.At); err != nil {
+			return nil, err
+		}
+		if before.Valid {
+			e.Before = []byte(before.String)
+		}
+		if after.Valid {
+			e.After = []byte(after.String)
+		}
+		if diff.Valid {
+			e.Diff = []byte(diff.String)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// Purge удаляет записи старше cutoff (срок хранения журнала)
+func (r *AuditRepository) Purge(cutoff time.Time) (int64, error) {
+	res, err := r.db.Exec(`DELETE FROM audit_log WHERE created_at < ?`, cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"database/sql"
+	"renault-backend/models"
+	"time"
+)
+
+// CarPublishingRepository — статус публикации автомобилей и расписание (БД каталога)
+type CarPublishingRepository struct {
+	db *sql.DB
+}
+
+func NewCarPublishingRepository(db *sql.DB) *CarPublishingRepository {
+	return &CarPublishingRepository{db: db}
+}
+
+// InitTables добавляет в cars колонки статуса; уже существующие автомобили считаются опубликованными
+func (r *CarPublishingRepository) InitTables() error {
+	if err := EnsureColumn(r.db, "cars", "status", "TEXT NOT NULL DEFAULT 'published'"); err != nil {
+		return err
+	}
+	if err := EnsureColumn(r.db, "cars", "publish_at", "TIMESTAMP"); err != nil {
+		return err
+	}
+	return EnsureColumn(r.db, "cars", "unpublish_at", "TIMESTAMP")
+}
+
+// Get возвращает статус автомобиля; nil — если его нет или он удалён
+func (r *CarPublishingRepository) Get(carID string) (*models.CarPublishing, error) {
+	var p models.CarPublishing
+	var publishAt, unpublishAt sql.NullTime
+	err := r.db.QueryRow(`
+		SELECT status, publish_at, unpublish_at FROM cars
+		WHERE id = ? AND deleted_at IS NULL`, carID).Scan(&p.Status, &publishAt, &unpublishAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if publishAt.Valid {
+		p.PublishAt = &publishAt.Time
+	}
+	if unpublishAt.Valid {
+		p.UnpublishAt = &unpublishAt.Time
+	}
+	return &p, nil
+}
+
+// Set меняет статус и расписание; false — если автомобиля нет
+func (r *CarPublishingRepository) Set(carID string, p models.CarPublishing) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE cars SET status = ?, publish_at = ?, unpublish_at = ?, version = version + 1
+		WHERE id = ? AND deleted_at IS NULL`,
+		p.Status, utcOrNil(p.PublishAt), utcOrNil(p.UnpublishAt), carID)
+	if err != nil {
+		return false, err
+	}
+	affected, _ := res.RowsAffected()
+	return affected > 0, nil
+}
+
+func utcOrNil(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+// ApplyDue публикует и снимает с публикации автомобили, у которых наступило время.
+// Возвращает количество опубликованных и снятых.
+func (r *CarPublishingRepository) ApplyDue(now time.Time) (published, unpublished int64, err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE cars SET status = ?, publish_at = NULL, version = version + 1
+		WHERE status <> ? AND publish_at IS NOT NULL AND publish_at <= ? AND deleted_at IS NULL`,
+		models.CarPublished, models.CarPublished, now.UTC())
+	if err != nil {
+		return 0, 0, err
+	}
+	published, _ = res.RowsAffected()
+
+	res, err = tx.Exec(`
+		UPDATE cars SET status = ?, unpublish_at = NULL, version = version + 1
+		WHERE status = ? AND unpublish_at IS NOT NULL AND unpublish_at <= ? AND deleted_at IS NULL`,
+		models.CarArchived, models.CarPublished, now.UTC())
+	if err != nil {
+		return 0, 0, err
+	}
+	unpublished, _ = res.RowsAffected()
+
+	return published, unpublished, tx.Commit()
+}
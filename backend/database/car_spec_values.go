@@ -0,0 +1,140 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"renault-backend/models"
+	"strings"
+)
+
+// CarSpecValuesRepository — типизированные характеристики для фильтров (БД каталога).
+// Значения выводятся из текстовых характеристик (models.ParseSpecValues) и
+// пересчитываются при каждом сохранении автомобиля.
+type CarSpecValuesRepository struct {
+	db *sql.DB
+}
+
+func NewCarSpecValuesRepository(db *sql.DB) *CarSpecValuesRepository {
+	return &CarSpecValuesRepository{db: db}
+}
+
+// InitTables создаёт таблицу значений и заново разбирает характеристики всех автомобилей:
+// так заполняются записи, созданные до появления схемы, и подхватываются улучшения разбора
+func (r *CarSpecValuesRepository) InitTables() error {
+	_, err := r.db.Exec(`
+	CREATE TABLE IF NOT EXISTS car_spec_values (
+		car_id TEXT NOT NULL,
+		key TEXT NOT NULL,
+		num_value REAL,
+		text_value TEXT,
+		PRIMARY KEY (car_id, key)
+	);
+	CREATE INDEX IF NOT EXISTS idx_car_spec_values_key ON car_spec_values (key, num_value, text_value);
+	`)
+	if err != nil {
+		return fmt.Errorf("error creating car_spec_values table: %v", err)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	ids, err := queryStrings(tx, `SELECT id FROM cars`)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := RefreshCarSpecValues(tx, id); err != nil {
+			return fmt.Errorf("parse specs of %s: %v", id, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// RefreshCarSpecValues пересчитывает типизированные значения автомобиля
+// по его текущим особенностям и техническим характеристикам
+func RefreshCarSpecValues(tx *sql.Tx, carID string) error {
+	var category sql.NullString
+	if err := tx.QueryRow(`SELECT category FROM cars WHERE id = ?`, carID).Scan(&category); err != nil {
+		return err
+	}
+	features, err := queryStrings(tx, `SELECT name FROM car_features WHERE car_id = ? ORDER BY id`, carID)
+	if err != nil {
+		return err
+	}
+	techSpecs, err := LoadCarSpecs(tx, carID, SpecKindTech)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM car_spec_values WHERE car_id = ?`, carID); err != nil {
+		return err
+	}
+	for key, v := range models.ParseSpecValues(category.String, features, techSpecs) {
+		var numValue, textValue any
+		switch v := v.(type) {
+		case float64:
+			numValue = v
+		case string:
+			textValue = v
+		}
+		if _, err := tx.Exec(`INSERT INTO car_spec_values (car_id, key, num_value, text_value) VALUES (?, ?, ?, ?)`,
+			carID, key, numValue, textValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadCarSpecValues читает типизированные значения автомобиля
+func LoadCarSpecValues(q queryer, carID string) (models.SpecValues, error) {
+	rows, err := q.Query(`SELECT key, num_value, text_value FROM car_spec_values WHERE car_id = ?`, carID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := models.SpecValues{}
+	for rows.Next() {
+		var key string
+		var numValue sql.NullFloat64
+		var textValue sql.NullString
+		if err := rows.Scan(&key, &numValue, &textValue); err != nil {
+			return nil, err
+		}
+		if numValue.Valid {
+			values[key] = numValue.Float64
+		} else {
+			values[key] = textValue.String
+		}
+	}
+	return values, rows.Err()
+}
+
+// SpecFiltersSQL превращает фильтры в условие для WHERE по таблице cars
+func SpecFiltersSQL(filters []models.SpecFilter) (string, []any) {
+	var conds []string
+	var args []any
+	for _, f := range filters {
+		cond := `EXISTS (SELECT 1 FROM car_spec_values v WHERE v.car_id = cars.id AND v.key = ?`
+		args = append(args, f.Key)
+		if f.Min != nil {
+			cond += ` AND v.num_value >= ?`
+			args = append(args, *f.Min)
+		}
+		if f.Max != nil {
+			cond += ` AND v.num_value <= ?`
+			args = append(args, *f.Max)
+		}
+		if len(f.Values) > 0 {
+			cond += ` AND v.text_value IN (?` + strings.Repeat(`, ?`, len(f.Values)-1) + `)`
+			for _, v := range f.Values {
+				args = append(args, v)
+			}
+		}
+		conds = append(conds, cond+`)`)
+	}
+	return strings.Join(conds, ` AND `), args
+}
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// TrashedCar — автомобиль в корзине админки (мягко удалённый)
+type TrashedCar struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Category  string    `json:"category"`
+	Price     int       `json:"price"`
+	DeletedAt time.Time `json:"deletedAt"`
+	DeletedBy string    `json:"deletedBy"`
+	PurgeAt   time.Time `json:"purgeAt"` // после этого момента удаляется окончательно
+}
+
+// CarTrashRepository — мягкое удаление автомобилей каталога (БД каталога)
+type CarTrashRepository struct {
+	db *sql.DB
+}
+
+func NewCarTrashRepository(db *sql.DB) *CarTrashRepository {
+	return &CarTrashRepository{db: db}
+}
+
+// InitTables добавляет в cars колонки мягкого удаления
+func (r *CarTrashRepository) InitTables() error {
+	if err := EnsureColumn(r.db, "cars", "deleted_at", "TIMESTAMP"); err != nil {
+		return err
+	}
+	return EnsureColumn(r.db, "cars", "deleted_by", "TEXT NOT NULL DEFAULT ''")
+}
+
+// Restore возвращает автомобиль из корзины; false — если его там нет
+func (r *CarTrashRepository) Restore(id string) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE cars SET deleted_at = NULL, deleted_by = '', version = version + 1
+		WHERE id = ? AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return false, err
+	}
+	affected, _ := res.RowsAffected()
+	return affected > 0, nil
+}
+
+// List — содержимое корзины, недавно удалённые первыми
+func (r *CarTrashRepository) List(retention time.Duration) ([]TrashedCar, error) {
+	rows, err := r.db.Query(`
+		SELECT id, title, COALESCE(category, ''), COALESCE(base_price, 0), deleted_at, deleted_by
+		FROM cars
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cars := []TrashedCar{}
+	for rows.Next() {
+		var c TrashedCar
+		if err := rows.Scan(&c.ID, &c.Title, &c.Category, &c.Price, &c.DeletedAt, &c.DeletedBy); err != nil {
+			return nil, err
+		}
+		c.PurgeAt = c.DeletedAt.Add(retention)
+		cars = append(cars, c)
+	}
+	return cars, rows.Err()
+}
+
+// Purge окончательно удаляет автомобили, лежащие в корзине с момента cutoff,
+// вместе с характеристиками, изображениями и расписанием цен.
+// Возвращает id удалённых — по ним нужно почистить корзины покупателей.
+func (r *CarTrashRepository) Purge(cutoff time.Time) ([]string, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT id FROM cars WHERE deleted_at IS NOT NULL AND deleted_at < ?`, cutoff.UTC())
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// foreign_keys в SQLite выключены, поэтому ON DELETE CASCADE не срабатывает — чистим сами
+	now := time.Now().UTC()
+	for _, id := range ids {
+		if _, err := tx.Exec(`
+			UPDATE car_price_schedule SET cancelled_at = ?
+			WHERE car_id = ? AND applied_at IS NULL AND cancelled_at IS NULL`, now, id); err != nil {
+			return nil, err
+		}
+		for _, q := range []string{
+			`DELETE FROM car_features WHERE car_id = ?`,
+			`DELETE FROM car_images WHERE car_id = ?`,
+			`DELETE FROM car_specs WHERE car_id = ?`,
+			`DELETE FROM car_spec_values WHERE car_id = ?`,
+			`DELETE FROM cars WHERE id = ?`,
+		} {
+			if _, err := tx.Exec(q, id); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return ids, tx.Commit()
+}
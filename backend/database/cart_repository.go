@@ -1,6 +1,10 @@
 package database
 
-import "database/sql"
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
 
 type CartItem struct {
 	ID       int `json:"id"`
@@ -9,13 +13,18 @@ type CartItem struct {
 	Quantity int `json:"quantity"`
 }
 
+// CartItemResponse — позиция корзины вместе с данными автомобиля из каталога
 type CartItemResponse struct {
-	ID       int    `json:"id"`
-	CarID    int    `json:"car_id"`
-	Title    string `json:"title"`
-	Image    string `json:"image"`
-	Price    string `json:"price"`
-	Quantity int    `json:"quantity"`
+	ID           int    `json:"id"`
+	CarID        string `json:"carId"`
+	Title        string `json:"title"`
+	Image        string `json:"image"`
+	Category     string `json:"category"`
+	UnitPrice    int    `json:"unitPrice"`    // цена на момент добавления в корзину
+	CurrentPrice int    `json:"currentPrice"` // действующая base_price
+	Quantity     int    `json:"quantity"`
+	CarDeleted   bool   `json:"carDeleted"`   // автомобиль удалён или снят с публикации
+	PriceChanged bool   `json:"priceChanged"` // цена изменилась после добавления
 }
 
 type CartRepository struct {
@@ -48,6 +57,42 @@ func (r *CartRepository) GetCart(userID int) ([]CartItem, error) {
 	return items, nil
 }
 
+// GetCartDetailed возвращает корзину пользователя, соединённую с каталогом
+// (catalog.cars подключён к соединению через ATTACH, см. CatalogPath)
+func (r *CartRepository) GetCartDetailed(userID string) ([]CartItemResponse, error) {
+	rows, err := r.db.Query(`
+        SELECT ci.id, ci.car_id, ci.quantity, ci.unit_price,
+               c.id IS NULL OR c.deleted_at IS NOT NULL OR c.status <> 'published', COALESCE(c.title, ''), COALESCE(c.image, ''),
+               COALESCE(c.category, ''), COALESCE(c.base_price, 0)
+        FROM cart_items ci
+        LEFT JOIN catalog.cars c ON c.id = ci.car_id
+        WHERE ci.user_id = ?
+        ORDER BY ci.id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []CartItemResponse{}
+	for rows.Next() {
+		var it CartItemResponse
+		var unitPrice sql.NullInt64
+		if err := rows.Scan(&it.ID, &it.CarID, &it.Quantity, &unitPrice,
+			&it.CarDeleted, &it.Title, &it.Image, &it.Category, &it.CurrentPrice); err != nil {
+			return nil, err
+		}
+
+		// у старых записей цена не сохранялась — считаем её текущей
+		it.UnitPrice = it.CurrentPrice
+		if unitPrice.Valid {
+			it.UnitPrice = int(unitPrice.Int64)
+		}
+		it.PriceChanged = !it.CarDeleted && it.UnitPrice != it.CurrentPrice
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
 // Найти запись по userId + carId
 func (r *CartRepository) FindItem(userID, carID int) (*CartItem, error) {
 	row := r.db.QueryRow(`
@@ -67,17 +112,17 @@ func (r *CartRepository) FindItem(userID, carID int) (*CartItem, error) {
 // Добавить новую запись
 func (r *CartRepository) AddItem(userID, carID, quantity int) error {
 	_, err := r.db.Exec(`
-        INSERT INTO cart_items (user_id, car_id, quantity) 
-        VALUES (?, ?, ?)`,
-		userID, carID, quantity)
+        INSERT INTO cart_items (user_id, car_id, quantity, created_at, updated_at) 
+        VALUES (?, ?, ?, ?, ?)`,
+		userID, carID, quantity, time.Now().UTC(), time.Now().UTC())
 	return err
 }
 
 // Обновить количество
 func (r *CartRepository) UpdateQuantity(id, quantity int) error {
 	_, err := r.db.Exec(`
-        UPDATE cart_items SET quantity = ? WHERE id = ?`,
-		quantity, id)
+        UPDATE cart_items SET quantity = ?, updated_at = ? WHERE id = ?`,
+		quantity, time.Now().UTC(), id)
 	return err
 }
 
@@ -92,3 +137,169 @@ func (r *CartRepository) ClearCart(userID int) error {
 	_, err := r.db.Exec(`DELETE FROM cart_items WHERE user_id = ?`, userID)
 	return err
 }
+
+// AbandonedCart — корзина, в которой давно не было активности
+type AbandonedCart struct {
+	UserID       string
+	LastActivity time.Time
+}
+
+// AbandonedModelStat — строка отчёта по брошенным корзинам в разрезе моделей
+type AbandonedModelStat struct {
+	CarID    string `json:"carId"`
+	Title    string `json:"title"`
+	Carts    int    `json:"carts"`    // сколько брошенных корзин содержат модель
+	Quantity int    `json:"quantity"` // суммарное количество
+	Value    int    `json:"value"`    // сумма по ценам на момент добавления
+}
+
+// ExpireStale удаляет корзины без активности с момента cutoff и возвращает их владельцев
+func (r *CartRepository) ExpireStale(cutoff time.Time) ([]string, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+        SELECT user_id FROM cart_items
+        GROUP BY user_id
+        HAVING MAX(updated_at) < ?`, cutoff.UTC())
+	if err != nil {
+		return nil, err
+	}
+	var users []string
+	for rows.Next() {
+		var u string
+		if err := rows.Scan(&u); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, u := range users {
+		if _, err := tx.Exec(`DELETE FROM cart_items WHERE user_id = ?`, u); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(`DELETE FROM cart_reminders WHERE user_id = ?`, u); err != nil {
+			return nil, err
+		}
+	}
+	return users, tx.Commit()
+}
+
+// FindAbandoned возвращает корзины без активности с момента cutoff,
+// по которым ещё не отправлялось напоминание за текущий период бездействия
+func (r *CartRepository) FindAbandoned(cutoff time.Time) ([]AbandonedCart, error) {
+	rows, err := r.db.Query(`
+        SELECT ci.user_id, MAX(ci.updated_at) AS last_activity
+        FROM cart_items ci
+        GROUP BY ci.user_id
+        HAVING last_activity < ?
+           AND NOT EXISTS (
+               SELECT 1 FROM cart_reminders cr
+               WHERE cr.user_id = ci.user_id AND cr.last_activity_at >= last_activity
+           )`, cutoff.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var carts []AbandonedCart
+	for rows.Next() {
+		var c AbandonedCart
+		var last string
+		if err := rows.Scan(&c.UserID, &last); err != nil {
+			return nil, err
+		}
+		// MAX() теряет тип колонки, поэтому время приходит строкой
+		c.LastActivity, err = parseSQLiteTime(last)
+		if err != nil {
+			return nil, err
+		}
+		carts = append(carts, c)
+	}
+	return carts, rows.Err()
+}
+
+// MarkReminded запоминает, что по этому периоду бездействия напоминание уже поставлено в очередь
+func (r *CartRepository) MarkReminded(userID string, lastActivity time.Time) error {
+	_, err := r.db.Exec(`
+        INSERT INTO cart_reminders (user_id, last_activity_at, reminded_at)
+        VALUES (?, ?, ?)
+        ON CONFLICT (user_id) DO UPDATE SET
+            last_activity_at = excluded.last_activity_at,
+            reminded_at = excluded.reminded_at`,
+		userID, lastActivity.UTC(), time.Now().UTC())
+	return err
+}
+
+// AbandonedReport — брошенные корзины (без активности с cutoff) в разрезе моделей
+func (r *CartRepository) AbandonedReport(cutoff time.Time) ([]AbandonedModelStat, error) {
+	rows, err := r.db.Query(`
+        WITH abandoned AS (
+            SELECT user_id FROM cart_items
+            GROUP BY user_id
+            HAVING MAX(updated_at) < ?
+        )
+        SELECT ci.car_id, COALESCE(c.title, ci.car_id),
+               COUNT(DISTINCT ci.user_id), SUM(ci.quantity),
+               SUM(ci.quantity * COALESCE(ci.unit_price, c.base_price, 0))
+        FROM cart_items ci
+        JOIN abandoned a ON a.user_id = ci.user_id
+        LEFT JOIN catalog.cars c ON c.id = ci.car_id
+        GROUP BY ci.car_id
+        ORDER BY 3 DESC, 5 DESC`, cutoff.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []AbandonedModelStat{}
+	for rows.Next() {
+		var s AbandonedModelStat
+		if err := rows.Scan(&s.CarID, &s.Title, &s.Carts, &s.Quantity, &s.Value); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// parseSQLiteTime разбирает время в формате, в котором go-sqlite3 сохраняет time.Time
+func parseSQLiteTime(s string) (time.Time, error) {
+	for _, layout := range sqlite3TimeFormats {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unexpected time format %q", s)
+}
+
+var sqlite3TimeFormats = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	time.RFC3339Nano,
+}
+
+// RemoveCars удаляет из всех корзин позиции с окончательно удалёнными автомобилями
+func (r *CartRepository) RemoveCars(carIDs []string) (int64, error) {
+	var removed int64
+	for _, id := range carIDs {
+		res, err := r.db.Exec(`DELETE FROM cart_items WHERE car_id = ?`, id)
+		if err != nil {
+			return removed, err
+		}
+		n, _ := res.RowsAffected()
+		removed += n
+	}
+	return removed, nil
+}
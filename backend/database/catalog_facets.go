@@ -0,0 +1,189 @@
+package database
+
+import (
+	"database/sql"
+	"renault-backend/models"
+	"strings"
+)
+
+// CatalogFacetsRepository считает фасеты публичного каталога агрегатами SQL (БД каталога)
+type CatalogFacetsRepository struct {
+	db *sql.DB
+}
+
+func NewCatalogFacetsRepository(db *sql.DB) *CatalogFacetsRepository {
+	return &CatalogFacetsRepository{db: db}
+}
+
+const publishedCarsWhere = `cars.deleted_at IS NULL AND cars.status = 'published'`
+
+// CatalogFilterSQL превращает фильтр каталога в условие для WHERE по таблице cars;
+// пустая строка — условий нет. Диапазон цен — [price_min, price_max).
+func CatalogFilterSQL(f models.CatalogFilter) (string, []any) {
+	var conds []string
+	var args []any
+	if len(f.Categories) > 0 {
+		conds = append(conds, `cars.category IN (?`+strings.Repeat(`, ?`, len(f.Categories)-1)+`)`)
+		for _, c := range f.Categories {
+			args = append(args, c)
+		}
+	}
+	if f.PriceMin != nil {
+		conds = append(conds, `cars.base_price >= ?`)
+		args = append(args, *f.PriceMin)
+	}
+	if f.PriceMax != nil {
+		conds = append(conds, `cars.base_price < ?`)
+		args = append(args, *f.PriceMax)
+	}
+	if specWhere, specArgs := SpecFiltersSQL(f.Specs); specWhere != "" {
+		conds = append(conds, specWhere)
+		args = append(args, specArgs...)
+	}
+	return strings.Join(conds, ` AND `), args
+}
+
+// publishedWhere — опубликованные автомобили с фильтром f
+func publishedWhere(f models.CatalogFilter) (string, []any) {
+	where, args := CatalogFilterSQL(f)
+	if where == "" {
+		return publishedCarsWhere, args
+	}
+	return publishedCarsWhere + ` AND ` + where, args
+}
+
+// Facets считает автомобили по категориям, ценам и характеристикам. Счётчики каждого
+// фасета учитывают все фильтры, кроме его собственного, — как в сайдбаре интернет-магазина.
+func (r *CatalogFacetsRepository) Facets(f models.CatalogFilter) (*models.CatalogFacets, error) {
+	facets := &models.CatalogFacets{Specs: map[string][]models.FacetValue{}}
+
+	where, args := publishedWhere(f)
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM cars WHERE `+where, args...).Scan(&facets.Total); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if facets.Categories, err = r.categoryFacet(f); err != nil {
+		return nil, err
+	}
+	if facets.Price, err = r.priceFacet(f); err != nil {
+		return nil, err
+	}
+	for _, key := range models.FacetSpecKeys {
+		if facets.Specs[key], err = r.specFacet(f, key); err != nil {
+			return nil, err
+		}
+	}
+	return facets, nil
+}
+
+// countBy выполняет запрос вида SELECT значение, COUNT(*) ... GROUP BY значение
+func (r *CatalogFacetsRepository) countBy(query string, args ...any) (map[string]int, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var value string
+		var count int
+		if err := rows.Scan(&value, &count); err != nil {
+			return nil, err
+		}
+		counts[value] = count
+	}
+	return counts, rows.Err()
+}
+
+func (r *CatalogFacetsRepository) categoryFacet(f models.CatalogFilter) ([]models.FacetValue, error) {
+	where, args := publishedWhere(f.Without(models.FacetCategory))
+	rows, err := r.db.Query(`
+		SELECT COALESCE(category, ''), COUNT(*) FROM cars
+		WHERE `+where+`
+		GROUP BY 1 ORDER BY 1`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := []models.FacetValue{}
+	seen := map[string]bool{}
+	for rows.Next() {
+		var v models.FacetValue
+		if err := rows.Scan(&v.Value, &v.Count); err != nil {
+			return nil, err
+		}
+		v.Label = v.Value
+		v.Selected = f.Selected(models.FacetCategory, v.Value)
+		seen[v.Value] = true
+		values = append(values, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// выбранная категория остаётся в списке, даже если в ней ничего не нашлось
+	for _, c := range f.Categories {
+		if !seen[c] {
+			values = append(values, models.FacetValue{Value: c, Label: c, Selected: true})
+		}
+	}
+	return values, nil
+}
+
+func (r *CatalogFacetsRepository) priceFacet(f models.CatalogFilter) (models.PriceFacet, error) {
+	where, args := publishedWhere(f.Without(models.FacetPrice))
+
+	// все диапазоны и границы — одним проходом по таблице
+	cols := []string{`COALESCE(MIN(base_price), 0)`, `COALESCE(MAX(base_price), 0)`}
+	var bucketArgs []any
+	for _, b := range models.PriceBuckets {
+		if b.Max != nil {
+			cols = append(cols, `COALESCE(SUM(base_price >= ? AND base_price < ?), 0)`)
+			bucketArgs = append(bucketArgs, b.Min, *b.Max)
+		} else {
+			cols = append(cols, `COALESCE(SUM(base_price >= ?), 0)`)
+			bucketArgs = append(bucketArgs, b.Min)
+		}
+	}
+
+	facet := models.PriceFacet{Buckets: make([]models.PriceBucket, len(models.PriceBuckets))}
+	copy(facet.Buckets, models.PriceBuckets)
+	dest := []any{&facet.Min, &facet.Max}
+	for i := range facet.Buckets {
+		b := &facet.Buckets[i]
+		dest = append(dest, &b.Count)
+		b.Selected = f.PriceMin != nil && *f.PriceMin == b.Min &&
+			((b.Max == nil && f.PriceMax == nil) || (b.Max != nil && f.PriceMax != nil && *f.PriceMax == *b.Max))
+	}
+
+	err := r.db.QueryRow(`SELECT `+strings.Join(cols, ", ")+` FROM cars WHERE `+where,
+		append(bucketArgs, args...)...).Scan(dest...)
+	return facet, err
+}
+
+func (r *CatalogFacetsRepository) specFacet(f models.CatalogFilter, key string) ([]models.FacetValue, error) {
+	where, args := publishedWhere(f.Without(key))
+	counts, err := r.countBy(`
+		SELECT sv.text_value, COUNT(*) FROM cars
+		JOIN car_spec_values sv ON sv.car_id = cars.id AND sv.key = ?
+		WHERE `+where+`
+		GROUP BY 1`, append([]any{key}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	// все значения из схемы в её порядке, включая нулевые — чтобы сайдбар не прыгал
+	values := []models.FacetValue{}
+	if def := models.SpecDefByKey(key); def != nil {
+		for _, o := range def.Options {
+			values = append(values, models.FacetValue{
+				Value: o.Value, Label: o.Label, Count: counts[o.Value],
+				Selected: f.Selected(key, o.Value),
+			})
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,329 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"renault-backend/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	SpecKindTech      = "tech"
+	SpecKindEquipment = "equipment"
+)
+
+// CatalogIORepository — выгрузка и загрузка всего каталога (БД каталога)
+type CatalogIORepository struct {
+	db *sql.DB
+}
+
+func NewCatalogIORepository(db *sql.DB) *CatalogIORepository {
+	return &CatalogIORepository{db: db}
+}
+
+// queryer — общее у *sql.DB и *sql.Tx
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// Export выгружает все не удалённые автомобили в любом статусе
+func (r *CatalogIORepository) Export() (*models.CatalogExport, error) {
+	rows, err := r.db.Query(`SELECT id FROM cars WHERE deleted_at IS NULL ORDER BY category, id`)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	export := &models.CatalogExport{ExportedAt: time.Now().UTC(), Categories: []string{}, Cars: []models.CatalogCar{}}
+	seen := map[string]bool{}
+	for _, id := range ids {
+		c, err := loadCatalogCar(r.db, id)
+		if err != nil {
+			return nil, err
+		}
+		export.Cars = append(export.Cars, *c)
+		if c.Category != "" && !seen[c.Category] {
+			seen[c.Category] = true
+			export.Categories = append(export.Categories, c.Category)
+		}
+	}
+	sort.Strings(export.Categories)
+	return export, nil
+}
+
+// loadCatalogCar читает автомобиль со всеми списками; nil — если его нет
+func loadCatalogCar(q queryer, id string) (*models.CatalogCar, error) {
+	var c models.CatalogCar
+	var desc, category, image sql.NullString
+	var price sql.NullInt64
+	err := q.QueryRow(`
+		SELECT id, title, description, category, image, base_price, status
+		FROM cars WHERE id = ? AND deleted_at IS NULL`, id).
+		Scan(&c.ID, &c.Title, &desc, &category, &image, &price, &c.Status)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.Description, c.Category, c.Image, c.Price = desc.String, category.String, image.String, int(price.Int64)
+
+	if c.Features, err = queryStrings(q, `SELECT name FROM car_features WHERE car_id = ? ORDER BY id`, id); err != nil {
+		return nil, err
+	}
+	if c.Images, err = queryStrings(q, `SELECT image_path FROM car_images WHERE car_id = ? ORDER BY id`, id); err != nil {
+		return nil, err
+	}
+	if c.TechSpecs, err = LoadCarSpecs(q, id, SpecKindTech); err != nil {
+		return nil, err
+	}
+	if c.Equipment, err = LoadCarSpecs(q, id, SpecKindEquipment); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func queryStrings(q queryer, query string, args ...any) ([]string, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []string{}
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+// LoadCarSpecs возвращает характеристики автомобиля вида kind в порядке ввода
+func LoadCarSpecs(q queryer, carID, kind string) ([]models.CatalogSpec, error) {
+	rows, err := q.Query(`SELECT name, value FROM car_specs WHERE car_id = ? AND kind = ? ORDER BY position, id`, carID, kind)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	specs := []models.CatalogSpec{}
+	for rows.Next() {
+		var s models.CatalogSpec
+		if err := rows.Scan(&s.Name, &s.Value); err != nil {
+			return nil, err
+		}
+		specs = append(specs, s)
+	}
+	return specs, rows.Err()
+}
+
+// ReplaceCarSpecs заменяет характеристики автомобиля вида kind
+func ReplaceCarSpecs(tx *sql.Tx, carID, kind string, specs []models.CatalogSpec) error {
+	if _, err := tx.Exec(`DELETE FROM car_specs WHERE car_id = ? AND kind = ?`, carID, kind); err != nil {
+		return err
+	}
+	for i, s := range specs {
+		if _, err := tx.Exec(`INSERT INTO car_specs (car_id, kind, name, value, position) VALUES (?, ?, ?, ?, ?)`,
+			carID, kind, s.Name, s.Value, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Import загружает автомобили с upsert по id. rows — номера строк файла для отчёта,
+// parseErrs — строки, которые не удалось разобрать (попадают в отчёт как ошибки).
+func (r *CatalogIORepository) Import(cars []models.CatalogCar, rows []int, parseErrs map[int]string, opts models.ImportOptions) (*models.ImportReport, error) {
+	report := &models.ImportReport{DryRun: opts.DryRun, Atomic: opts.Atomic, Rows: []models.ImportRowResult{}}
+	for row, msg := range parseErrs {
+		report.Rows = append(report.Rows, models.ImportRowResult{Row: row, Action: models.ImportError, Error: msg})
+		report.Failed++
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	seen := map[string]int{}
+	for i, c := range cars {
+		res := models.ImportRowResult{Row: rows[i], ID: c.ID}
+
+		// каждая строка — в своей точке сохранения, чтобы ошибка откатывала только её
+		if _, err := tx.Exec(`SAVEPOINT import_row`); err != nil {
+			return nil, err
+		}
+		action, diff, rowErr := importCar(tx, c, seen, opts.Author)
+		if rowErr != nil {
+			if _, err := tx.Exec(`ROLLBACK TO import_row`); err != nil {
+				return nil, err
+			}
+			res.Action, res.Error = models.ImportError, rowErr.Error()
+			report.Failed++
+		} else {
+			res.Action, res.Diff = action, diff
+			switch action {
+			case models.ImportCreate:
+				report.Created++
+			case models.ImportUpdate:
+				report.Updated++
+			default:
+				report.Unchanged++
+			}
+			seen[c.ID] = rows[i]
+		}
+		if _, err := tx.Exec(`RELEASE import_row`); err != nil {
+			return nil, err
+		}
+		report.Rows = append(report.Rows, res)
+	}
+	sort.Slice(report.Rows, func(i, j int) bool { return report.Rows[i].Row < report.Rows[j].Row })
+
+	if opts.DryRun || (opts.Atomic && report.Failed > 0) {
+		return report, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	report.Applied = true
+	return report, nil
+}
+
+// importCar применяет одну строку внутри транзакции
+func importCar(tx *sql.Tx, c models.CatalogCar, seen map[string]int, author string) (string, json.RawMessage, error) {
+	c.ID = strings.TrimSpace(c.ID)
+	if errs := models.ValidateCatalogCar(c); len(errs) > 0 {
+		return "", nil, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	if row, dup := seen[c.ID]; dup {
+		return "", nil, fmt.Errorf("id %s уже встречался в строке %d", c.ID, row)
+	}
+
+	var trashed bool
+	err := tx.QueryRow(`SELECT deleted_at IS NOT NULL FROM cars WHERE id = ?`, c.ID).Scan(&trashed)
+	if err != nil && err != sql.ErrNoRows {
+		return "", nil, err
+	}
+	if trashed {
+		return "", nil, fmt.Errorf("автомобиль %s в корзине — сначала восстановите его", c.ID)
+	}
+
+	existing, err := loadCatalogCar(tx, c.ID)
+	if err != nil {
+		return "", nil, err
+	}
+	normalizeCatalogCar(&c)
+
+	if existing == nil {
+		if c.Status == "" {
+			c.Status = models.CarDraft
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO cars (id, title, description, category, image, base_price, status)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			c.ID, c.Title, c.Description, c.Category, c.Image, c.Price, c.Status); err != nil {
+			return "", nil, err
+		}
+		if err := RecordPriceChange(tx, c.ID, nil, c.Price, author, nil); err != nil {
+			return "", nil, err
+		}
+		if err := replaceCarLists(tx, c); err != nil {
+			return "", nil, err
+		}
+		if err := RefreshCarSpecValues(tx, c.ID); err != nil {
+			return "", nil, err
+		}
+		return models.ImportCreate, nil, nil
+	}
+
+	if c.Status == "" {
+		c.Status = existing.Status
+	}
+	normalizeCatalogCar(existing)
+	before, _ := json.Marshal(existing)
+	after, _ := json.Marshal(c)
+	diff := models.AuditDiff(before, after)
+	if string(diff) == "{}" {
+		return models.ImportUnchanged, nil, nil
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE cars SET title = ?, description = ?, category = ?, image = ?, base_price = ?, status = ?,
+			version = version + 1
+		WHERE id = ?`,
+		c.Title, c.Description, c.Category, c.Image, c.Price, c.Status, c.ID); err != nil {
+		return "", nil, err
+	}
+	if existing.Price != c.Price {
+		oldPrice := existing.Price
+		if err := RecordPriceChange(tx, c.ID, &oldPrice, c.Price, author, nil); err != nil {
+			return "", nil, err
+		}
+	}
+	if err := replaceCarLists(tx, c); err != nil {
+		return "", nil, err
+	}
+	if err := RefreshCarSpecValues(tx, c.ID); err != nil {
+		return "", nil, err
+	}
+	return models.ImportUpdate, diff, nil
+}
+
+// пустые списки и nil считаем одинаковыми, чтобы не получать ложных изменений
+func normalizeCatalogCar(c *models.CatalogCar) {
+	if c.Features == nil {
+		c.Features = []string{}
+	}
+	if c.Images == nil {
+		c.Images = []string{}
+	}
+	if c.TechSpecs == nil {
+		c.TechSpecs = []models.CatalogSpec{}
+	}
+	if c.Equipment == nil {
+		c.Equipment = []models.CatalogSpec{}
+	}
+}
+
+func replaceCarLists(tx *sql.Tx, c models.CatalogCar) error {
+	if _, err := tx.Exec(`DELETE FROM car_features WHERE car_id = ?`, c.ID); err != nil {
+		return err
+	}
+	for _, f := range c.Features {
+		if _, err := tx.Exec(`INSERT INTO car_features (car_id, name) VALUES (?, ?)`, c.ID, f); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM car_images WHERE car_id = ?`, c.ID); err != nil {
+		return err
+	}
+	for _, img := range c.Images {
+		if _, err := tx.Exec(`INSERT INTO car_images (car_id, image_path) VALUES (?, ?)`, c.ID, img); err != nil {
+			return err
+		}
+	}
+	if err := ReplaceCarSpecs(tx, c.ID, SpecKindTech, c.TechSpecs); err != nil {
+		return err
+	}
+	return ReplaceCarSpecs(tx, c.ID, SpecKindEquipment, c.Equipment)
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+)
+
+// InitCatalog создаёт схему БД каталога и применяет миграции репозиториев.
+// Вызывается при старте сервера и в NewTestCatalog для тестов на фикстурах.
+func InitCatalog(db *sql.DB, logger *slog.Logger) error {
+	_, err := db.Exec(`
+	CREATE TABLE IF NOT EXISTS cars (
+		id TEXT PRIMARY KEY,
+		title TEXT NOT NULL,
+		description TEXT,
+		category TEXT,
+		image TEXT,
+		base_price INTEGER
+	);
+
+	CREATE TABLE IF NOT EXISTS car_features (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		car_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		FOREIGN KEY (car_id) REFERENCES cars(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS car_images (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		car_id TEXT NOT NULL,
+		image_path TEXT NOT NULL,
+		FOREIGN KEY (car_id) REFERENCES cars(id) ON DELETE CASCADE
+	);
+
+	-- характеристики: kind = tech (технические) или equipment (комплектация)
+	CREATE TABLE IF NOT EXISTS car_specs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		car_id TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		name TEXT NOT NULL,
+		value TEXT NOT NULL DEFAULT '',
+		position INTEGER NOT NULL DEFAULT 0,
+		FOREIGN KEY (car_id) REFERENCES cars(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_car_specs_car ON car_specs (car_id, kind);
+
+	-- ТАБЛИЦА ОТЗЫВОВ
+	CREATE TABLE IF NOT EXISTS reviews (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		email TEXT NOT NULL,
+		model TEXT,
+		rating INTEGER,
+		text TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	`)
+	if err != nil {
+		return fmt.Errorf("error creating catalog tables: %v", err)
+	}
+
+	// версия записи для оптимистичной блокировки (ETag / If-Match)
+	if err := EnsureColumn(db, "cars", "version", "INTEGER NOT NULL DEFAULT 1"); err != nil {
+		return err
+	}
+
+	migrations := []func() error{
+		NewCarTrashRepository(db).InitTables,
+		NewCarPublishingRepository(db).InitTables,
+		NewPriceRepository(db).InitTables,
+		NewPromotionRepository(db).InitTables,
+		NewFinanceRepository(db).InitTables,
+		NewCarSpecValuesRepository(db).InitTables,
+		// индекс поиска — последним: триггеры ссылаются на таблицы выше
+		NewSearchRepository(db).InitTables,
+	}
+	for _, m := range migrations {
+		if err := m(); err != nil {
+			return err
+		}
+	}
+	if !NewSearchRepository(db).Available() {
+		logger.Warn("catalog search disabled: SQLite built without FTS5 (go build -tags sqlite_fts5)")
+	}
+	logger.Debug("catalog tables ready")
+	return nil
+}
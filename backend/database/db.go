@@ -2,19 +2,45 @@ package database
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"renault-backend/models"
+	"time"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 )
 
 var DB *sql.DB
 
+// CatalogPath — файл БД каталога автомобилей. Он подключается (ATTACH) к каждому
+// соединению DB под именем catalog, чтобы корзину можно было джойнить с cars одним запросом.
+var CatalogPath = "cars.db"
+
+// busyTimeoutMs — сколько соединение ждёт, пока другой писатель отпустит файл, прежде чем
+// вернуть "database is locked". Каталог пишут два пула (DB через ATTACH и carDB), так что ожидание нужно обоим.
+const busyTimeoutMs = 5000
+
+// DSN — строка подключения к файлу SQLite с общими для приложения параметрами.
+// _txlock=immediate берёт блокировку записи в начале транзакции: иначе транзакция, начавшая
+// с чтения, получает SQLITE_BUSY при первой записи сразу, не дожидаясь busy_timeout.
+func DSN(path string) string {
+	return fmt.Sprintf("%s?_busy_timeout=%d&_txlock=immediate", path, busyTimeoutMs)
+}
+
+func init() {
+	sql.Register("sqlite3_with_catalog", &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			_, err := conn.Exec(`ATTACH DATABASE ? AS catalog`, []driver.Value{CatalogPath})
+			return err
+		},
+	})
+}
+
 // InitDB инициализирует SQLite базу данных
-func InitDB() error {
+func InitDB(logger *slog.Logger) error {
 	// Создаем директорию для базы данных, если её нет
 	dataDir := "data"
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
@@ -24,7 +50,7 @@ func InitDB() error {
 	// Открываем базу данных
 	dbPath := filepath.Join(dataDir, "renault.db")
 	var err error
-	DB, err = sql.Open("sqlite3", dbPath)
+	DB, err = sql.Open("sqlite3_with_catalog", DSN(dbPath))
 	if err != nil {
 		return fmt.Errorf("error opening database: %v", err)
 	}
@@ -35,38 +61,66 @@ func InitDB() error {
 		return fmt.Errorf("error connecting to database: %v", err)
 	}
 
-	log.Printf("Successfully connected to SQLite database: %s", dbPath)
+	logger.Info("connected to SQLite database", "path", dbPath)
 
+	return CreateTables(logger)
+}
+
+// CreateTables создаёт и мигрирует таблицы основной БД в уже открытом DB.
+// Тесты вызывают её на базе в памяти.
+func CreateTables(logger *slog.Logger) error {
 	// Создаем таблицу пользователей
-	err = createUsersTable()
+	err := createUsersTable(logger)
 	if err != nil {
 		return err
 	}
 
 	// Создаем таблицы для автомобилей
-	err = createCarsTables()
+	err = createCarsTables(logger)
 	if err != nil {
 		return err
 	}
 
-	if err := createAdminsTable(); err != nil {
+	if err := createAdminsTable(logger); err != nil {
 		return err
 	}
 
-	if err := createCartTables(); err != nil {
+	if err := createCartTables(logger); err != nil {
 		return err
 	}
 
-	// Заполняем данными автомобилей
-	err = SeedCarsData()
-	if err != nil {
+	if err := createTradeInTables(logger); err != nil {
+		return err
+	}
+
+	if err := createOrderTables(logger); err != nil {
+		return err
+	}
+
+	if err := createLeadTables(logger); err != nil {
+		return err
+	}
+
+	if err := createNotificationsTable(logger); err != nil {
+		return err
+	}
+
+	if err := createAuditTables(logger); err != nil {
+		return err
+	}
+
+	if err := createAnalyticsTables(logger); err != nil {
+		return err
+	}
+
+	if err := createModerationTables(logger); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func createCartTables() error {
+func createCartTables(logger *slog.Logger) error {
 	query := `
 	CREATE TABLE IF NOT EXISTS cart_items (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -78,11 +132,41 @@ func createCartTables() error {
 	if err != nil {
 		return fmt.Errorf("error creating admins table: %v", err)
 	}
-	log.Println("Admins table created or already exists")
+
+	// цена на момент добавления в корзину
+	if err := EnsureColumn(DB, "cart_items", "unit_price", "INTEGER"); err != nil {
+		return err
+	}
+
+	// время жизни корзины: SQLite не даёт добавить колонку с DEFAULT CURRENT_TIMESTAMP,
+	// поэтому колонки nullable, а старые записи проставляем текущим временем
+	if err := EnsureColumn(DB, "cart_items", "created_at", "TIMESTAMP"); err != nil {
+		return err
+	}
+	if err := EnsureColumn(DB, "cart_items", "updated_at", "TIMESTAMP"); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	if _, err := DB.Exec(`UPDATE cart_items SET created_at = ?, updated_at = ? WHERE updated_at IS NULL`, now, now); err != nil {
+		return err
+	}
+
+	// напоминания о брошенной корзине: одно на каждый период бездействия
+	_, err = DB.Exec(`
+	CREATE TABLE IF NOT EXISTS cart_reminders (
+		user_id TEXT PRIMARY KEY,
+		last_activity_at TIMESTAMP NOT NULL,
+		reminded_at TIMESTAMP NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("error creating cart_reminders table: %v", err)
+	}
+
+	logger.Debug("cart tables ready")
 	return nil
 }
 
-func createAdminsTable() error {
+func createAdminsTable(logger *slog.Logger) error {
 	query := `
 	CREATE TABLE IF NOT EXISTS admins (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -92,11 +176,11 @@ func createAdminsTable() error {
 	if err != nil {
 		return fmt.Errorf("error creating admins table: %v", err)
 	}
-	log.Println("Admins table created or already exists")
+	logger.Debug("admins table ready")
 	return nil
 }
 
-func createUsersTable() error {
+func createUsersTable(logger *slog.Logger) error {
 	query := `
 	CREATE TABLE IF NOT EXISTS users (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -113,7 +197,7 @@ func createUsersTable() error {
 		return fmt.Errorf("error creating users table: %v", err)
 	}
 
-	log.Println("Users table created or already exists")
+	logger.Debug("users table ready")
 	return nil
 }
 
@@ -195,7 +279,7 @@ func (r *UserRepository) GetAllUsers() ([]models.User, error) {
 	return users, nil
 }
 
-func createCarsTables() error {
+func createCarsTables(logger *slog.Logger) error {
 	// Таблица автомобилей
 	query := `
     CREATE TABLE IF NOT EXISTS cars (
@@ -259,7 +343,7 @@ func createCarsTables() error {
 		return fmt.Errorf("error creating car_features table: %v", err)
 	}
 
-	log.Println("Cars tables created or already exist")
+	logger.Debug("legacy cars tables ready")
 	return nil
 }
 
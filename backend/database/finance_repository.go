@@ -0,0 +1,182 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"renault-backend/models"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// ErrDuplicateProductCode — программа с таким code уже есть
+var ErrDuplicateProductCode = errors.New("программа с таким кодом уже существует")
+
+// isUniqueViolation — нарушено ограничение UNIQUE
+func isUniqueViolation(err error) bool {
+	var se sqlite3.Error
+	return errors.As(err, &se) && se.ExtendedCode == sqlite3.ErrConstraintUnique
+}
+
+// FinanceRepository работает с программами кредитования/лизинга.
+// Таблица живёт в БД каталога, потому что расчёт идёт от base_price.
+type FinanceRepository struct {
+	db *sql.DB
+}
+
+func NewFinanceRepository(db *sql.DB) *FinanceRepository {
+	return &FinanceRepository{db: db}
+}
+
+// InitTables создаёт таблицу программ и заполняет её значениями по умолчанию
+func (r *FinanceRepository) InitTables() error {
+	_, err := r.db.Exec(`
+	CREATE TABLE IF NOT EXISTS finance_products (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		code TEXT UNIQUE NOT NULL,
+		name TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		annual_rate REAL NOT NULL,
+		min_down_payment_pct REAL NOT NULL DEFAULT 0,
+		residual_pct REAL NOT NULL DEFAULT 0,
+		min_term_months INTEGER NOT NULL,
+		max_term_months INTEGER NOT NULL,
+		active BOOLEAN NOT NULL DEFAULT 1
+	)`)
+	if err != nil {
+		return fmt.Errorf("error creating finance_products table: %v", err)
+	}
+
+	var count int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM finance_products`).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	defaults := []models.FinanceProduct{
+		{Code: "credit-standard", Name: "Renault Кредит", Kind: models.FinanceKindCredit,
+			AnnualRate: 15.9, MinDownPaymentPct: 10, MinTermMonths: 12, MaxTermMonths: 84, Active: true},
+		{Code: "credit-promo", Name: "Кредит 0,01%", Kind: models.FinanceKindCredit,
+			AnnualRate: 0.01, MinDownPaymentPct: 50, MinTermMonths: 12, MaxTermMonths: 36, Active: true},
+		{Code: "leasing-business", Name: "Лизинг для бизнеса", Kind: models.FinanceKindLeasing,
+			AnnualRate: 12.5, MinDownPaymentPct: 20, ResidualPct: 10, MinTermMonths: 12, MaxTermMonths: 60, Active: true},
+	}
+	for i := range defaults {
+		if _, err := r.CreateProduct(&defaults[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const financeProductColumns = `id, code, name, kind, annual_rate, min_down_payment_pct,
+	residual_pct, min_term_months, max_term_months, active`
+
+func scanFinanceProduct(row interface{ Scan(...any) error }) (*models.FinanceProduct, error) {
+	var p models.FinanceProduct
+	err := row.Scan(&p.ID, &p.Code, &p.Name, &p.Kind, &p.AnnualRate, &p.MinDownPaymentPct,
+		&p.ResidualPct, &p.MinTermMonths, &p.MaxTermMonths, &p.Active)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ListProducts возвращает программы; onlyActive — только доступные клиентам
+func (r *FinanceRepository) ListProducts(onlyActive bool) ([]models.FinanceProduct, error) {
+	query := `SELECT ` + financeProductColumns + ` FROM finance_products`
+	if onlyActive {
+		query += ` WHERE active = 1`
+	}
+	query += ` ORDER BY kind, annual_rate`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []models.FinanceProduct{}
+	for rows.Next() {
+		p, err := scanFinanceProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, *p)
+	}
+	return products, rows.Err()
+}
+
+// GetProduct возвращает программу по id или nil, если её нет
+func (r *FinanceRepository) GetProduct(id int) (*models.FinanceProduct, error) {
+	row := r.db.QueryRow(`SELECT `+financeProductColumns+` FROM finance_products WHERE id = ?`, id)
+	p, err := scanFinanceProduct(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return p, err
+}
+
+// GetProductByCode возвращает программу по коду или nil, если её нет
+func (r *FinanceRepository) GetProductByCode(code string) (*models.FinanceProduct, error) {
+	row := r.db.QueryRow(`SELECT `+financeProductColumns+` FROM finance_products WHERE code = ?`, code)
+	p, err := scanFinanceProduct(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return p, err
+}
+
+func (r *FinanceRepository) CreateProduct(p *models.FinanceProduct) (int, error) {
+	res, err := r.db.Exec(`
+		INSERT INTO finance_products (code, name, kind, annual_rate, min_down_payment_pct,
+			residual_pct, min_term_months, max_term_months, active)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		p.Code, p.Name, p.Kind, p.AnnualRate, p.MinDownPaymentPct,
+		p.ResidualPct, p.MinTermMonths, p.MaxTermMonths, p.Active)
+	if isUniqueViolation(err) {
+		return 0, ErrDuplicateProductCode
+	}
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	return int(id), err
+}
+
+// UpdateProduct обновляет программу; возвращает false, если записи нет
+func (r *FinanceRepository) UpdateProduct(p *models.FinanceProduct) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE finance_products
+		SET code = ?, name = ?, kind = ?, annual_rate = ?, min_down_payment_pct = ?,
+			residual_pct = ?, min_term_months = ?, max_term_months = ?, active = ?
+		WHERE id = ?`,
+		p.Code, p.Name, p.Kind, p.AnnualRate, p.MinDownPaymentPct,
+		p.ResidualPct, p.MinTermMonths, p.MaxTermMonths, p.Active, p.ID)
+	if isUniqueViolation(err) {
+		return false, ErrDuplicateProductCode
+	}
+	if err != nil {
+		return false, err
+	}
+	affected, _ := res.RowsAffected()
+	return affected > 0, nil
+}
+
+func (r *FinanceRepository) DeleteProduct(id int) (bool, error) {
+	res, err := r.db.Exec(`DELETE FROM finance_products WHERE id = ?`, id)
+	if err != nil {
+		return false, err
+	}
+	affected, _ := res.RowsAffected()
+	return affected > 0, nil
+}
+
+// GetCarPrice возвращает base_price автомобиля из каталога
+func (r *FinanceRepository) GetCarPrice(carID string) (int, error) {
+	var price int
+	err := r.db.QueryRow(`SELECT base_price FROM cars WHERE id = ? AND deleted_at IS NULL AND status = 'published'`, carID).Scan(&price)
+	return price, err
+}
@@ -0,0 +1,252 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"renault-backend/models"
+	"time"
+)
+
+func createLeadTables(logger *slog.Logger) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS leads (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		type TEXT NOT NULL,
+		car_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		phone TEXT NOT NULL,
+		email TEXT NOT NULL DEFAULT '',
+		message TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL,
+		assigned_to TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL,
+		last_touched_at TIMESTAMP NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_leads_status ON leads (status, last_touched_at);
+
+	CREATE TABLE IF NOT EXISTS lead_activities (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		lead_id INTEGER NOT NULL,
+		actor TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		details TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL,
+		FOREIGN KEY (lead_id) REFERENCES leads (id) ON DELETE CASCADE
+	)`
+	_, err := DB.Exec(query)
+	if err != nil {
+		return fmt.Errorf("error creating leads tables: %v", err)
+	}
+	logger.Debug("leads tables ready")
+	return nil
+}
+
+type LeadRepository struct {
+	db *sql.DB
+}
+
+func NewLeadRepository() *LeadRepository {
+	return &LeadRepository{db: DB}
+}
+
+// CarPublished — есть ли автомобиль в публичном каталоге (не удалён и опубликован)
+func (r *LeadRepository) CarPublished(carID string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM catalog.cars WHERE id = ? AND deleted_at IS NULL AND status = 'published')`,
+		carID).Scan(&exists)
+	return exists, err
+}
+
+// Create сохраняет новый лид и первую запись в истории
+func (r *LeadRepository) Create(l *models.Lead) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	res, err := tx.Exec(`
+		INSERT INTO leads (type, car_id, name, phone, email, message, status, created_at, last_touched_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		l.Type, l.CarID, l.Name, l.Phone, l.Email, l.Message, models.LeadNew, now, now)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	if err := addLeadActivity(tx, int(id), "site", models.LeadActivityCreated, l.Type, now, false); err != nil {
+		return err
+	}
+
+	l.ID = int(id)
+	l.Status = models.LeadNew
+	l.CreatedAt = now
+	l.LastTouchedAt = now
+	return tx.Commit()
+}
+
+// addLeadActivity пишет событие в историю; touch продлевает SLA-таймер
+func addLeadActivity(tx *sql.Tx, leadID int, actor, kind, details string, at time.Time, touch bool) error {
+	_, err := tx.Exec(`
+		INSERT INTO lead_activities (lead_id, actor, kind, details, created_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		leadID, actor, kind, details, at)
+	if err != nil {
+		return err
+	}
+	if touch {
+		_, err = tx.Exec(`UPDATE leads SET last_touched_at = ? WHERE id = ?`, at, leadID)
+	}
+	return err
+}
+
+const leadColumns = `id, type, car_id, name, phone, email, message, status, assigned_to, created_at, last_touched_at`
+
+func scanLead(row interface{ Scan(...any) error }, slaCutoff time.Time) (*models.Lead, error) {
+	var l models.Lead
+	err := row.Scan(&l.ID, &l.Type, &l.CarID, &l.Name, &l.Phone, &l.Email, &l.Message,
+		&l.Status, &l.AssignedTo, &l.CreatedAt, &l.LastTouchedAt)
+	if err != nil {
+		return nil, err
+	}
+	l.SLABreached = models.IsLeadOpen(l.Status) && l.LastTouchedAt.Before(slaCutoff)
+	return &l, nil
+}
+
+// List возвращает лиды по фильтру; slaCutoff — момент, раньше которого лид считается просроченным
+func (r *LeadRepository) List(f models.LeadFilter, slaCutoff time.Time) ([]models.Lead, error) {
+	query := `SELECT ` + leadColumns + ` FROM leads WHERE 1 = 1`
+	var args []any
+
+	if f.Status != "" {
+		query += ` AND status = ?`
+		args = append(args, f.Status)
+	}
+	if f.AssignedTo != "" {
+		query += ` AND assigned_to = ?`
+		args = append(args, f.AssignedTo)
+	}
+	if f.CarID != "" {
+		query += ` AND car_id = ?`
+		args = append(args, f.CarID)
+	}
+	if f.Overdue {
+		query += ` AND status NOT IN (?, ?) AND last_touched_at < ?`
+		args = append(args, models.LeadWon, models.LeadLost, slaCutoff)
+	}
+	query += ` ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	leads := []models.Lead{}
+	for rows.Next() {
+		l, err := scanLead(rows, slaCutoff)
+		if err != nil {
+			return nil, err
+		}
+		leads = append(leads, *l)
+	}
+	return leads, rows.Err()
+}
+
+// GetByID возвращает лид или nil, если его нет
+func (r *LeadRepository) GetByID(id int, slaCutoff time.Time) (*models.Lead, error) {
+	l, err := scanLead(r.db.QueryRow(`SELECT `+leadColumns+` FROM leads WHERE id = ?`, id), slaCutoff)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return l, err
+}
+
+// UpdateStatus меняет статус лида, только если он всё ещё равен from
+func (r *LeadRepository) UpdateStatus(id int, from, to, actor string) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`UPDATE leads SET status = ? WHERE id = ? AND status = ?`, to, id, from)
+	if err != nil {
+		return false, err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return false, nil
+	}
+
+	details := from + " → " + to
+	if err := addLeadActivity(tx, id, actor, models.LeadActivityStatus, details, time.Now().UTC(), true); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// Assign назначает лид менеджеру
+func (r *LeadRepository) Assign(id int, manager, actor string) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`UPDATE leads SET assigned_to = ? WHERE id = ?`, manager, id)
+	if err != nil {
+		return false, err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return false, nil
+	}
+
+	if err := addLeadActivity(tx, id, actor, models.LeadActivityAssigned, manager, time.Now().UTC(), true); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// AddNote добавляет заметку менеджера
+func (r *LeadRepository) AddNote(id int, actor, text string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := addLeadActivity(tx, id, actor, models.LeadActivityNote, text, time.Now().UTC(), true); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Activities возвращает историю лида в хронологическом порядке
+func (r *LeadRepository) Activities(id int) ([]models.LeadActivity, error) {
+	rows, err := r.db.Query(`
+		SELECT id, lead_id, actor, kind, details, created_at
+		FROM lead_activities
+		WHERE lead_id = ?
+		ORDER BY created_at, id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.LeadActivity{}
+	for rows.Next() {
+		var a models.LeadActivity
+		if err := rows.Scan(&a.ID, &a.LeadID, &a.Actor, &a.Kind, &a.Details, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
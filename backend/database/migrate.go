@@ -0,0 +1,42 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// EnsureColumn добавляет колонку в существующую таблицу, если её ещё нет.
+// SQLite не умеет ADD COLUMN IF NOT EXISTS, поэтому смотрим в PRAGMA table_info.
+func EnsureColumn(db *sql.DB, table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf(`PRAGMA table_info(%s)`, table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name, typ  string
+			notNull    int
+			defaultVal sql.NullString
+			pk         int
+		)
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &defaultVal, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	_, err = db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition))
+	if err != nil {
+		return fmt.Errorf("error adding column %s.%s: %v", table, column, err)
+	}
+	return nil
+}
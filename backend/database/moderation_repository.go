@@ -0,0 +1,196 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"renault-backend/models"
+	"time"
+)
+
+// createModerationTables — очередь модерации подозрительных отзывов и заявок
+// и отпечатки недавних текстов для поиска повторов
+func createModerationTables(logger *slog.Logger) error {
+	_, err := DB.Exec(`
+	CREATE TABLE IF NOT EXISTS moderation_queue (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		kind TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		reasons TEXT NOT NULL,
+		client_ip TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		decided_by TEXT NOT NULL DEFAULT '',
+		decided_at TIMESTAMP,
+		result_id INTEGER NOT NULL DEFAULT 0
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_moderation_queue_status ON moderation_queue (status, created_at);
+
+	CREATE TABLE IF NOT EXISTS submission_fingerprints (
+		kind TEXT NOT NULL,
+		hash TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_submission_fingerprints_hash ON submission_fingerprints (kind, hash);
+	CREATE INDEX IF NOT EXISTS idx_submission_fingerprints_created ON submission_fingerprints (created_at)`)
+	if err != nil {
+		return fmt.Errorf("error creating moderation tables: %v", err)
+	}
+	logger.Debug("moderation tables ready")
+	return nil
+}
+
+type ModerationRepository struct {
+	db *sql.DB
+}
+
+func NewModerationRepository() *ModerationRepository {
+	return &ModerationRepository{db: DB}
+}
+
+// SeenText отмечает отпечаток текста и сообщает, встречался ли он с момента since.
+// Старые отпечатки удаляет PurgeFingerprints (jobs.FingerprintPurge).
+func (r *ModerationRepository) SeenText(kind, hash string, since time.Time) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var seen bool
+	if err := tx.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM submission_fingerprints WHERE kind = ? AND hash = ? AND created_at >= ?)`,
+		kind, hash, since.UTC()).Scan(&seen); err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec(`INSERT INTO submission_fingerprints (kind, hash, created_at) VALUES (?, ?, ?)`,
+		kind, hash, time.Now().UTC()); err != nil {
+		return false, err
+	}
+	return seen, tx.Commit()
+}
+
+// PurgeFingerprints удаляет отпечатки текстов старше cutoff — дальше окна повторы не ищем
+func (r *ModerationRepository) PurgeFingerprints(cutoff time.Time) (int64, error) {
+	res, err := r.db.Exec(`DELETE FROM submission_fingerprints WHERE created_at < ?`, cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Enqueue ставит отправку в очередь со статусом pending
+func (r *ModerationRepository) Enqueue(item *models.ModerationItem) error {
+	reasons, err := json.Marshal(item.Reasons)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	res, err := r.db.Exec(`
+		INSERT INTO moderation_queue (kind, payload, reasons, client_ip, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		item.Kind, string(item.Payload), string(reasons), item.ClientIP, models.ModerationPending, now)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	item.ID = int(id)
+	item.Status = models.ModerationPending
+	item.CreatedAt = now
+	return nil
+}
+
+const moderationColumns = `id, kind, payload, reasons, client_ip, status, created_at, decided_by, decided_at, result_id`
+
+func scanModerationItem(row interface{ Scan(...any) error }) (*models.ModerationItem, error) {
+	var item models.ModerationItem
+	var payload, reasons string
+	var decidedAt sql.NullTime
+	err := row.Scan(&item.ID, &item.Kind, &payload, &reasons, &item.ClientIP, &item.Status,
+		&item.CreatedAt, &item.DecidedBy, &decidedAt, &item.ResultID)
+	if err != nil {
+		return nil, err
+	}
+	item.Payload = json.RawMessage(payload)
+	if err := json.Unmarshal([]byte(reasons), &item.Reasons); err != nil {
+		return nil, err
+	}
+	if decidedAt.Valid {
+		item.DecidedAt = &decidedAt.Time
+	}
+	return &item, nil
+}
+
+// List — очередь по статусу и виду (пустые — все), старые записи первыми
+func (r *ModerationRepository) List(status, kind string, limit int) ([]models.ModerationItem, error) {
+	query := `SELECT ` + moderationColumns + ` FROM moderation_queue WHERE 1 = 1`
+	var args []any
+	if status != "" {
+		query += ` AND status = ?`
+		args = append(args, status)
+	}
+	if kind != "" {
+		query += ` AND kind = ?`
+		args = append(args, kind)
+	}
+	query += ` ORDER BY created_at, id LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.ModerationItem{}
+	for rows.Next() {
+		item, err := scanModerationItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	return items, rows.Err()
+}
+
+// GetByID возвращает запись очереди или nil, если её нет
+func (r *ModerationRepository) GetByID(id int) (*models.ModerationItem, error) {
+	item, err := scanModerationItem(r.db.QueryRow(`SELECT `+moderationColumns+` FROM moderation_queue WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return item, err
+}
+
+// Decide переводит запись из pending в status; false — запись уже разобрана другим администратором
+func (r *ModerationRepository) Decide(id int, status, actor string) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE moderation_queue SET status = ?, decided_by = ?, decided_at = ?
+		WHERE id = ? AND status = ?`,
+		status, actor, time.Now().UTC(), id, models.ModerationPending)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+// Reopen возвращает запись в pending, если одобренную отправку не удалось сохранить
+func (r *ModerationRepository) Reopen(id int) error {
+	_, err := r.db.Exec(`
+		UPDATE moderation_queue SET status = ?, decided_by = '', decided_at = NULL WHERE id = ?`,
+		models.ModerationPending, id)
+	return err
+}
+
+// SetResult запоминает id отзыва или лида, созданного при одобрении
+func (r *ModerationRepository) SetResult(id, resultID int) error {
+	_, err := r.db.Exec(`UPDATE moderation_queue SET result_id = ? WHERE id = ?`, resultID, id)
+	return err
+}
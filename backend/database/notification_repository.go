@@ -0,0 +1,108 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"renault-backend/notify"
+	"time"
+)
+
+const (
+	NotificationPending = "pending"
+	NotificationSent    = "sent"
+	NotificationFailed  = "failed"
+
+	// после стольких неудачных попыток уведомление больше не отправляем
+	maxNotificationAttempts = 5
+)
+
+func createNotificationsTable(logger *slog.Logger) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS notifications (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id TEXT NOT NULL,
+		email TEXT NOT NULL DEFAULT '',
+		kind TEXT NOT NULL,
+		subject TEXT NOT NULL,
+		payload TEXT NOT NULL DEFAULT '{}',
+		status TEXT NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		last_error TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL,
+		sent_at TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_notifications_status ON notifications (status, id)`
+	_, err := DB.Exec(query)
+	if err != nil {
+		return fmt.Errorf("error creating notifications table: %v", err)
+	}
+	logger.Debug("notifications table ready")
+	return nil
+}
+
+// NotificationRepository — очередь (outbox) уведомлений пользователям
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository() *NotificationRepository {
+	return &NotificationRepository{db: DB}
+}
+
+// Enqueue ставит уведомление в очередь; email подставляется из users, если user_id — id пользователя
+func (r *NotificationRepository) Enqueue(n notify.Notification) error {
+	if len(n.Payload) == 0 {
+		n.Payload = []byte("{}")
+	}
+	_, err := r.db.Exec(`
+		INSERT INTO notifications (user_id, email, kind, subject, payload, status, created_at)
+		VALUES (?, COALESCE((SELECT email FROM users WHERE CAST(id AS TEXT) = ?), ''), ?, ?, ?, ?, ?)`,
+		n.UserID, n.UserID, n.Kind, n.Subject, string(n.Payload), NotificationPending, time.Now().UTC())
+	return err
+}
+
+// Pending возвращает уведомления, ожидающие отправки
+func (r *NotificationRepository) Pending(limit int) ([]notify.Notification, error) {
+	rows, err := r.db.Query(`
+		SELECT id, user_id, email, kind, subject, payload, created_at
+		FROM notifications
+		WHERE status = ?
+		ORDER BY id
+		LIMIT ?`, NotificationPending, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []notify.Notification
+	for rows.Next() {
+		var n notify.Notification
+		var payload string
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Email, &n.Kind, &n.Subject, &payload, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		n.Payload = []byte(payload)
+		list = append(list, n)
+	}
+	return list, rows.Err()
+}
+
+func (r *NotificationRepository) MarkSent(id int) error {
+	_, err := r.db.Exec(`
+		UPDATE notifications SET status = ?, attempts = attempts + 1, sent_at = ?, last_error = ''
+		WHERE id = ?`, NotificationSent, time.Now().UTC(), id)
+	return err
+}
+
+// MarkFailed фиксирует ошибку; после maxNotificationAttempts попыток уведомление помечается failed
+func (r *NotificationRepository) MarkFailed(id int, sendErr error) error {
+	_, err := r.db.Exec(`
+		UPDATE notifications
+		SET attempts = attempts + 1,
+			last_error = ?,
+			status = CASE WHEN attempts + 1 >= ? THEN ? ELSE status END
+		WHERE id = ?`, sendErr.Error(), maxNotificationAttempts, NotificationFailed, id)
+	return err
+}
@@ -0,0 +1,132 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"renault-backend/models"
+	"time"
+)
+
+// ErrTradeInConsumed — trade-in уже зачтён в другой заказ, корзину нужно пересчитать
+var ErrTradeInConsumed = errors.New("trade-in уже использован в другом заказе")
+
+func createOrderTables(logger *slog.Logger) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS orders (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id TEXT NOT NULL,
+		subtotal INTEGER NOT NULL,
+		discount INTEGER NOT NULL,
+		trade_in_credit INTEGER NOT NULL,
+		total INTEGER NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS order_items (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		order_id INTEGER NOT NULL,
+		car_id TEXT NOT NULL,
+		title TEXT NOT NULL,
+		quantity INTEGER NOT NULL,
+		unit_price INTEGER NOT NULL,
+		line_total INTEGER NOT NULL,
+		FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE
+	)`
+	_, err := DB.Exec(query)
+	if err != nil {
+		return fmt.Errorf("error creating orders tables: %v", err)
+	}
+	logger.Debug("orders tables ready")
+	return nil
+}
+
+type OrderRepository struct {
+	db *sql.DB
+}
+
+func NewOrderRepository() *OrderRepository {
+	return &OrderRepository{db: DB}
+}
+
+// Create оформляет заказ в одной транзакции: сохраняет позиции, привязывает к заказу
+// зачтённые trade-in и промокоды и очищает корзину. Если trade-in уже привязан к другому
+// заказу, возвращает ErrTradeInConsumed, если лимит промокода выбран — ErrPromoExhausted;
+// в обоих случаях ничего не меняется. Промокоды живут в БД каталога (catalog.*).
+func (r *OrderRepository) Create(o *models.Order) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	o.CreatedAt = time.Now().UTC()
+	res, err := tx.Exec(`
+		INSERT INTO orders (user_id, subtotal, discount, trade_in_credit, total, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		o.UserID, o.Subtotal, o.Discount, o.TradeInCredit, o.Total, o.CreatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	o.ID = int(id)
+
+	for _, it := range o.Items {
+		if _, err := tx.Exec(`
+			INSERT INTO order_items (order_id, car_id, title, quantity, unit_price, line_total)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			o.ID, it.CarID, it.Title, it.Quantity, it.UnitPrice, it.LineTotal); err != nil {
+			return err
+		}
+	}
+
+	for _, tid := range o.TradeInIDs {
+		res, err := tx.Exec(`
+			UPDATE trade_in_requests SET order_id = ?
+			WHERE id = ? AND user_id = ? AND status = ? AND order_id IS NULL`,
+			o.ID, tid, o.UserID, models.TradeInApplied)
+		if err != nil {
+			return err
+		}
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			return ErrTradeInConsumed
+		}
+	}
+
+	for _, code := range o.PromoCodes {
+		var promoID, limit, used int
+		err := tx.QueryRow(`
+			SELECT p.id, p.usage_limit,
+				(SELECT COUNT(*) FROM catalog.promo_redemptions pr WHERE pr.promotion_id = p.id AND pr.order_id IS NOT NULL)
+			FROM catalog.promotions p WHERE p.code = ?`, code).Scan(&promoID, &limit, &used)
+		if err != nil {
+			return err
+		}
+		if limit > 0 && used >= limit {
+			return ErrPromoExhausted
+		}
+		if _, err := tx.Exec(`
+			UPDATE catalog.promo_redemptions SET order_id = ?
+			WHERE promotion_id = ? AND user_id = ? AND order_id IS NULL`,
+			o.ID, promoID, o.UserID); err != nil {
+			return err
+		}
+	}
+	// коды, которые не дали скидки, уходят вместе с корзиной
+	if _, err := tx.Exec(`DELETE FROM catalog.promo_redemptions WHERE user_id = ? AND order_id IS NULL`, o.UserID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM cart_items WHERE user_id = ?`, o.UserID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM cart_reminders WHERE user_id = ?`, o.UserID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,262 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"renault-backend/models"
+	"time"
+)
+
+// PriceRepository ведёт историю и расписание цен каталога (БД каталога)
+type PriceRepository struct {
+	db *sql.DB
+}
+
+func NewPriceRepository(db *sql.DB) *PriceRepository {
+	return &PriceRepository{db: db}
+}
+
+func (r *PriceRepository) InitTables() error {
+	_, err := r.db.Exec(`
+	CREATE TABLE IF NOT EXISTS car_price_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		car_id TEXT NOT NULL,
+		old_price INTEGER,
+		new_price INTEGER NOT NULL,
+		changed_by TEXT NOT NULL,
+		changed_at TIMESTAMP NOT NULL,
+		schedule_id INTEGER
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_price_history_car ON car_price_history (car_id, changed_at);
+
+	CREATE TABLE IF NOT EXISTS car_price_schedule (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		car_id TEXT NOT NULL,
+		new_price INTEGER NOT NULL,
+		effective_at TIMESTAMP NOT NULL,
+		created_by TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		applied_at TIMESTAMP,
+		cancelled_at TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_price_schedule_due ON car_price_schedule (effective_at)
+		WHERE applied_at IS NULL AND cancelled_at IS NULL;
+	`)
+	if err != nil {
+		return fmt.Errorf("error creating price tables: %v", err)
+	}
+	return nil
+}
+
+// RecordPriceChange пишет изменение цены в историю в рамках транзакции
+// изменения автомобиля. oldPrice == nil — первая цена нового автомобиля.
+func RecordPriceChange(tx *sql.Tx, carID string, oldPrice *int, newPrice int, author string, scheduleID *int) error {
+	_, err := tx.Exec(`
+		INSERT INTO car_price_history (car_id, old_price, new_price, changed_by, changed_at, schedule_id)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		carID, oldPrice, newPrice, author, time.Now().UTC(), scheduleID)
+	return err
+}
+
+// CurrentPrice возвращает действующую base_price автомобиля
+func (r *PriceRepository) CurrentPrice(carID string) (int, error) {
+	var price int
+	err := r.db.QueryRow(`SELECT base_price FROM cars WHERE id = ? AND deleted_at IS NULL`, carID).Scan(&price)
+	return price, err
+}
+
+// PublishedPrice — цена автомобиля, видимого в публичном каталоге;
+// для черновиков и снятых с публикации возвращает sql.ErrNoRows
+func (r *PriceRepository) PublishedPrice(carID string) (int, error) {
+	var price int
+	err := r.db.QueryRow(`SELECT base_price FROM cars WHERE id = ? AND deleted_at IS NULL AND status = 'published'`,
+		carID).Scan(&price)
+	return price, err
+}
+
+// History возвращает историю цен автомобиля, новые записи первыми
+func (r *PriceRepository) History(carID string) ([]models.PriceChange, error) {
+	rows, err := r.db.Query(`
+		SELECT id, car_id, old_price, new_price, changed_by, changed_at, schedule_id
+		FROM car_price_history
+		WHERE car_id = ?
+		ORDER BY changed_at DESC, id DESC`, carID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.PriceChange{}
+	for rows.Next() {
+		var c models.PriceChange
+		var oldPrice, scheduleID sql.NullInt64
+		if err := rows.Scan(&c.ID, &c.CarID, &oldPrice, &c.NewPrice, &c.ChangedBy, &c.ChangedAt, &scheduleID); err != nil {
+			return nil, err
+		}
+		if oldPrice.Valid {
+			v := int(oldPrice.Int64)
+			c.OldPrice = &v
+		}
+		if scheduleID.Valid {
+			v := int(scheduleID.Int64)
+			c.ScheduleID = &v
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+// Schedule планирует изменение цены на будущий момент
+func (r *PriceRepository) Schedule(s *models.ScheduledPriceChange) error {
+	now := time.Now().UTC()
+	res, err := r.db.Exec(`
+		INSERT INTO car_price_schedule (car_id, new_price, effective_at, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		s.CarID, s.NewPrice, s.EffectiveAt.UTC(), s.CreatedBy, now)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	s.ID = int(id)
+	s.CreatedAt = now
+	return nil
+}
+
+// ListScheduled возвращает расписание; pendingOnly — только ещё не применённые
+func (r *PriceRepository) ListScheduled(carID string, pendingOnly bool) ([]models.ScheduledPriceChange, error) {
+	query := `SELECT id, car_id, new_price, effective_at, created_by, created_at, applied_at, cancelled_at
+		FROM car_price_schedule WHERE 1 = 1`
+	var args []any
+	if carID != "" {
+		query += ` AND car_id = ?`
+		args = append(args, carID)
+	}
+	if pendingOnly {
+		query += ` AND applied_at IS NULL AND cancelled_at IS NULL`
+	}
+	query += ` ORDER BY effective_at, id`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.ScheduledPriceChange{}
+	for rows.Next() {
+		var s models.ScheduledPriceChange
+		var appliedAt, cancelledAt sql.NullTime
+		if err := rows.Scan(&s.ID, &s.CarID, &s.NewPrice, &s.EffectiveAt, &s.CreatedBy, &s.CreatedAt,
+			&appliedAt, &cancelledAt); err != nil {
+			return nil, err
+		}
+		if appliedAt.Valid {
+			s.AppliedAt = &appliedAt.Time
+		}
+		if cancelledAt.Valid {
+			s.CancelledAt = &cancelledAt.Time
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+// CancelScheduled отменяет ещё не применённое изменение
+func (r *PriceRepository) CancelScheduled(id int) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE car_price_schedule SET cancelled_at = ?
+		WHERE id = ? AND applied_at IS NULL AND cancelled_at IS NULL`,
+		time.Now().UTC(), id)
+	if err != nil {
+		return false, err
+	}
+	affected, _ := res.RowsAffected()
+	return affected > 0, nil
+}
+
+// ApplyDue применяет все изменения, время которых наступило к моменту now.
+// Каждое изменение применяется в своей транзакции; возвращает число применённых.
+func (r *PriceRepository) ApplyDue(now time.Time) (int, error) {
+	due, err := r.dueChanges(now)
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, s := range due {
+		if err := r.applyScheduled(s, now); err != nil {
+			return applied, fmt.Errorf("apply price schedule %d: %v", s.ID, err)
+		}
+		applied++
+	}
+	return applied, nil
+}
+
+func (r *PriceRepository) dueChanges(now time.Time) ([]models.ScheduledPriceChange, error) {
+	rows, err := r.db.Query(`
+		SELECT id, car_id, new_price, created_by
+		FROM car_price_schedule
+		WHERE applied_at IS NULL AND cancelled_at IS NULL AND effective_at <= ?
+		ORDER BY effective_at, id`, now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.ScheduledPriceChange
+	for rows.Next() {
+		var s models.ScheduledPriceChange
+		if err := rows.Scan(&s.ID, &s.CarID, &s.NewPrice, &s.CreatedBy); err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+func (r *PriceRepository) applyScheduled(s models.ScheduledPriceChange, now time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// помечаем как применённое сразу, чтобы параллельный запуск не применил его дважды
+	res, err := tx.Exec(`
+		UPDATE car_price_schedule SET applied_at = ?
+		WHERE id = ? AND applied_at IS NULL AND cancelled_at IS NULL`,
+		now.UTC(), s.ID)
+	if err != nil {
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return nil
+	}
+
+	var oldPrice int
+	err = tx.QueryRow(`SELECT base_price FROM cars WHERE id = ? AND deleted_at IS NULL`, s.CarID).Scan(&oldPrice)
+	if err == sql.ErrNoRows {
+		// автомобиль удалён (или в корзине) — расписание закрываем без изменения цены
+		return tx.Commit()
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE cars SET base_price = ?, version = version + 1 WHERE id = ?`, s.NewPrice, s.CarID); err != nil {
+		return err
+	}
+	if oldPrice != s.NewPrice {
+		scheduleID := s.ID
+		if err := RecordPriceChange(tx, s.CarID, &oldPrice, s.NewPrice, s.CreatedBy, &scheduleID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
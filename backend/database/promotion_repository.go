@@ -0,0 +1,303 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"renault-backend/models"
+	"strings"
+	"time"
+)
+
+var (
+	ErrPromoNotFound      = errors.New("промокод не найден или не действует")
+	ErrPromoExhausted     = errors.New("лимит использования промокода исчерпан")
+	ErrPromoUsed          = errors.New("промокод уже использован")
+	ErrPromoLoginRequired = errors.New("промокод с ограниченным числом использований доступен только после входа")
+)
+
+// PromotionRepository хранит акции и применённые промокоды (БД каталога)
+type PromotionRepository struct {
+	db *sql.DB
+}
+
+func NewPromotionRepository(db *sql.DB) *PromotionRepository {
+	return &PromotionRepository{db: db}
+}
+
+func (r *PromotionRepository) InitTables() error {
+	_, err := r.db.Exec(`
+	CREATE TABLE IF NOT EXISTS promotions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		code TEXT,
+		kind TEXT NOT NULL,
+		value INTEGER NOT NULL,
+		scope TEXT NOT NULL,
+		target TEXT NOT NULL DEFAULT '',
+		min_cart_total INTEGER NOT NULL DEFAULT 0,
+		starts_at TIMESTAMP,
+		ends_at TIMESTAMP,
+		usage_limit INTEGER NOT NULL DEFAULT 0,
+		stackable BOOLEAN NOT NULL DEFAULT 0,
+		active BOOLEAN NOT NULL DEFAULT 1
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_promotions_code ON promotions (code) WHERE code IS NOT NULL;
+
+	-- промокоды, применённые пользователями к корзине
+	CREATE TABLE IF NOT EXISTS promo_redemptions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		promotion_id INTEGER NOT NULL,
+		user_id TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL,
+		UNIQUE (promotion_id, user_id),
+		FOREIGN KEY (promotion_id) REFERENCES promotions (id) ON DELETE CASCADE
+	);
+	`)
+	if err != nil {
+		return fmt.Errorf("error creating promotions tables: %v", err)
+	}
+
+	// заказ, в котором промокод использован; лимит считается только по таким записям,
+	// пока order_id пуст — код просто лежит в корзине и лимит не занимает
+	if err := EnsureColumn(r.db, "promo_redemptions", "order_id", "INTEGER"); err != nil {
+		return err
+	}
+	// аккаунт, под которым применён код с лимитом: один такой код — одно использование на аккаунт
+	return EnsureColumn(r.db, "promo_redemptions", "account", "TEXT NOT NULL DEFAULT ''")
+}
+
+const promotionSelect = `
+	SELECT p.id, p.name, p.description, COALESCE(p.code, ''), p.kind, p.value, p.scope, p.target,
+		p.min_cart_total, p.starts_at, p.ends_at, p.usage_limit, p.stackable, p.active,
+		(SELECT COUNT(*) FROM promo_redemptions pr WHERE pr.promotion_id = p.id AND pr.order_id IS NOT NULL)
+	FROM promotions p`
+
+func scanPromotion(row interface{ Scan(...any) error }) (*models.Promotion, error) {
+	var p models.Promotion
+	var startsAt, endsAt sql.NullTime
+	err := row.Scan(&p.ID, &p.Name, &p.Description, &p.Code, &p.Kind, &p.Value, &p.Scope, &p.Target,
+		&p.MinCartTotal, &startsAt, &endsAt, &p.UsageLimit, &p.Stackable, &p.Active, &p.UsedCount)
+	if err != nil {
+		return nil, err
+	}
+	if startsAt.Valid {
+		p.StartsAt = &startsAt.Time
+	}
+	if endsAt.Valid {
+		p.EndsAt = &endsAt.Time
+	}
+	return &p, nil
+}
+
+func (r *PromotionRepository) queryPromotions(query string, args ...any) ([]models.Promotion, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.Promotion{}
+	for rows.Next() {
+		p, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *p)
+	}
+	return list, rows.Err()
+}
+
+// List возвращает все акции (для админки)
+func (r *PromotionRepository) List() ([]models.Promotion, error) {
+	return r.queryPromotions(promotionSelect + ` ORDER BY p.id DESC`)
+}
+
+// GetByID возвращает акцию или nil, если её нет
+func (r *PromotionRepository) GetByID(id int) (*models.Promotion, error) {
+	p, err := scanPromotion(r.db.QueryRow(promotionSelect+` WHERE p.id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return p, err
+}
+
+// Automatic возвращает действующие акции без промокода — они видны всем в каталоге
+func (r *PromotionRepository) Automatic(now time.Time) ([]models.Promotion, error) {
+	all, err := r.queryPromotions(promotionSelect + ` WHERE p.active = 1 AND p.code IS NULL`)
+	if err != nil {
+		return nil, err
+	}
+	return filterValid(all, now), nil
+}
+
+// ForUser — автоматические акции плюс промокоды, применённые пользователем
+func (r *PromotionRepository) ForUser(userID string, now time.Time) ([]models.Promotion, error) {
+	all, err := r.queryPromotions(promotionSelect+`
+		WHERE p.active = 1 AND (p.code IS NULL OR p.id IN (
+			SELECT promotion_id FROM promo_redemptions WHERE user_id = ? AND order_id IS NULL
+		))`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// для уже применённых кодов лимит проверяется при оформлении заказа
+	valid := all[:0]
+	for _, p := range all {
+		if p.Code != "" {
+			p.UsageLimit = 0
+		}
+		if p.IsValidAt(now) {
+			valid = append(valid, p)
+		}
+	}
+	return valid, nil
+}
+
+func filterValid(list []models.Promotion, now time.Time) []models.Promotion {
+	valid := list[:0]
+	for _, p := range list {
+		if p.IsValidAt(now) {
+			valid = append(valid, p)
+		}
+	}
+	return valid
+}
+
+func nullableCode(code string) any {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return nil
+	}
+	return code
+}
+
+func (r *PromotionRepository) Create(p *models.Promotion) error {
+	res, err := r.db.Exec(`
+		INSERT INTO promotions (name, description, code, kind, value, scope, target, min_cart_total,
+			starts_at, ends_at, usage_limit, stackable, active)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		p.Name, p.Description, nullableCode(p.Code), p.Kind, p.Value, p.Scope, p.Target, p.MinCartTotal,
+		p.StartsAt, p.EndsAt, p.UsageLimit, p.Stackable, p.Active)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	p.ID = int(id)
+	return err
+}
+
+func (r *PromotionRepository) Update(p *models.Promotion) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE promotions
+		SET name = ?, description = ?, code = ?, kind = ?, value = ?, scope = ?, target = ?,
+			min_cart_total = ?, starts_at = ?, ends_at = ?, usage_limit = ?, stackable = ?, active = ?
+		WHERE id = ?`,
+		p.Name, p.Description, nullableCode(p.Code), p.Kind, p.Value, p.Scope, p.Target,
+		p.MinCartTotal, p.StartsAt, p.EndsAt, p.UsageLimit, p.Stackable, p.Active, p.ID)
+	if err != nil {
+		return false, err
+	}
+	affected, _ := res.RowsAffected()
+	return affected > 0, nil
+}
+
+// UpsertByName создаёт акцию или обновляет существующую с тем же названием;
+// используется фикстурами, чтобы повторная загрузка не плодила дубликаты
+func (r *PromotionRepository) UpsertByName(p *models.Promotion) (created bool, err error) {
+	err = r.db.QueryRow(`SELECT id FROM promotions WHERE name = ? ORDER BY id LIMIT 1`, p.Name).Scan(&p.ID)
+	if err == sql.ErrNoRows {
+		return true, r.Create(p)
+	}
+	if err != nil {
+		return false, err
+	}
+	_, err = r.Update(p)
+	return false, err
+}
+
+func (r *PromotionRepository) Delete(id int) (bool, error) {
+	res, err := r.db.Exec(`DELETE FROM promotions WHERE id = ?`, id)
+	if err != nil {
+		return false, err
+	}
+	affected, _ := res.RowsAffected()
+	return affected > 0, nil
+}
+
+// Redeem применяет промокод к корзине userID. Лимит при этом не расходуется — использование
+// засчитывается при оформлении заказа (OrderRepository.Create). Код с лимитом можно применить
+// только под аккаунтом (account — имя пользователя из JWT) и только один раз на аккаунт.
+func (r *PromotionRepository) Redeem(code, userID, account string, now time.Time) (*models.Promotion, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	p, err := scanPromotion(tx.QueryRow(promotionSelect+` WHERE p.code = ?`, nullableCode(code)))
+	if err == sql.ErrNoRows {
+		return nil, ErrPromoNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var pending, used int
+	if err := tx.QueryRow(`
+		SELECT COUNT(*) FILTER (WHERE order_id IS NULL), COUNT(*) FILTER (WHERE order_id IS NOT NULL)
+		FROM promo_redemptions WHERE promotion_id = ? AND user_id = ?`,
+		p.ID, userID).Scan(&pending, &used); err != nil {
+		return nil, err
+	}
+	if pending > 0 {
+		return p, nil
+	}
+	if used > 0 {
+		return nil, ErrPromoUsed
+	}
+
+	if p.UsageLimit > 0 {
+		if account == "" {
+			return nil, ErrPromoLoginRequired
+		}
+		var byAccount int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM promo_redemptions WHERE promotion_id = ? AND account = ?`,
+			p.ID, account).Scan(&byAccount); err != nil {
+			return nil, err
+		}
+		if byAccount > 0 {
+			return nil, ErrPromoUsed
+		}
+	}
+
+	if p.UsageLimit > 0 && p.UsedCount >= p.UsageLimit {
+		return nil, ErrPromoExhausted
+	}
+	if !p.IsValidAt(now) {
+		return nil, ErrPromoNotFound
+	}
+
+	if _, err := tx.Exec(`INSERT INTO promo_redemptions (promotion_id, user_id, account, applied_at) VALUES (?, ?, ?, ?)`,
+		p.ID, userID, account, now.UTC()); err != nil {
+		return nil, err
+	}
+	return p, tx.Commit()
+}
+
+// Release снимает промокод с корзины пользователя; использованные в заказах не трогает
+func (r *PromotionRepository) Release(code, userID string) error {
+	_, err := r.db.Exec(`
+		DELETE FROM promo_redemptions
+		WHERE user_id = ? AND order_id IS NULL AND promotion_id IN (SELECT id FROM promotions WHERE code = ?)`,
+		userID, nullableCode(code))
+	return err
+}
+
+// ReleaseAll снимает все промокоды с корзины пользователя (например, при её очистке)
+func (r *PromotionRepository) ReleaseAll(userID string) error {
+	_, err := r.db.Exec(`DELETE FROM promo_redemptions WHERE user_id = ? AND order_id IS NULL`, userID)
+	return err
+}
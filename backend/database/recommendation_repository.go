@@ -0,0 +1,127 @@
+package database
+
+import (
+	"database/sql"
+	"renault-backend/models"
+)
+
+// RecommendationRepository — данные для рекомендаций: профили опубликованных автомобилей
+// и совместные просмотры и корзины покупателей (каталог подключён через ATTACH)
+type RecommendationRepository struct {
+	db *sql.DB
+}
+
+func NewRecommendationRepository() *RecommendationRepository {
+	return &RecommendationRepository{db: DB}
+}
+
+// Profiles возвращает профили всех опубликованных автомобилей для расчёта похожести
+func (r *RecommendationRepository) Profiles() ([]models.CarProfile, error) {
+	rows, err := r.db.Query(`
+		SELECT id, title, COALESCE(category, ''), COALESCE(image, ''), base_price
+		FROM catalog.cars
+		WHERE deleted_at IS NULL AND status = 'published'
+		ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	profiles := []models.CarProfile{}
+	index := map[string]int{}
+	for rows.Next() {
+		p := models.CarProfile{Specs: models.SpecValues{}}
+		if err := rows.Scan(&p.ID, &p.Title, &p.Category, &p.Image, &p.Price); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		index[p.ID] = len(profiles)
+		profiles = append(profiles, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// особенности и характеристики — двумя запросами на весь каталог, а не по автомобилю
+	rows, err = r.db.Query(`SELECT car_id, name FROM catalog.car_features ORDER BY car_id, id`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var carID, name string
+		if err := rows.Scan(&carID, &name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if i, ok := index[carID]; ok {
+			profiles[i].Features = append(profiles[i].Features, name)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = r.db.Query(`SELECT car_id, key, num_value, text_value FROM catalog.car_spec_values`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var carID, key string
+		var numValue sql.NullFloat64
+		var textValue sql.NullString
+		if err := rows.Scan(&carID, &key, &numValue, &textValue); err != nil {
+			return nil, err
+		}
+		i, ok := index[carID]
+		if !ok {
+			continue
+		}
+		if numValue.Valid {
+			profiles[i].Specs[key] = numValue.Float64
+		} else {
+			profiles[i].Specs[key] = textValue.String
+		}
+	}
+	return profiles, rows.Err()
+}
+
+// AlsoViewed — автомобили, которые смотрели покупатели, смотревшие carID.
+// Просмотры берутся из событий car_viewed; анонимные (без X-User-Id) не связывают автомобили.
+func (r *RecommendationRepository) AlsoViewed(carID string, limit int) ([]models.CoOccurrence, error) {
+	return r.coOccurrences(`(SELECT DISTINCT user_id, car_id FROM analytics_events
+		WHERE type = '`+models.EventCarViewed+`' AND user_id <> '')`, carID, limit)
+}
+
+// AlsoAddedToCart — автомобили, лежащие в корзинах вместе с carID
+func (r *RecommendationRepository) AlsoAddedToCart(carID string, limit int) ([]models.CoOccurrence, error) {
+	return r.coOccurrences(`cart_items`, carID, limit)
+}
+
+// coOccurrences считает, сколько покупателей из table (таблица или подзапрос с колонками user_id и car_id)
+// взаимодействовали и с carID, и с другим опубликованным автомобилем
+func (r *RecommendationRepository) coOccurrences(table, carID string, limit int) ([]models.CoOccurrence, error) {
+	rows, err := r.db.Query(`
+		SELECT other.car_id, COUNT(DISTINCT other.user_id) AS customers
+		FROM `+table+` base
+		JOIN `+table+` other ON other.user_id = base.user_id AND other.car_id <> base.car_id
+		JOIN catalog.cars c ON c.id = other.car_id AND c.deleted_at IS NULL AND c.status = 'published'
+		WHERE base.car_id = ?
+		GROUP BY other.car_id
+		ORDER BY customers DESC, other.car_id
+		LIMIT ?`, carID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []models.CoOccurrence{}
+	for rows.Next() {
+		var c models.CoOccurrence
+		if err := rows.Scan(&c.CarID, &c.Customers); err != nil {
+			return nil, err
+		}
+		result = append(result, c)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,32 @@
+package database
+
+import (
+	"database/sql"
+	"renault-backend/models"
+)
+
+// ReviewRepository — отзывы покупателей в БД каталога
+type ReviewRepository struct {
+	db *sql.DB
+}
+
+func NewReviewRepository(db *sql.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+// Create сохраняет отзыв и проставляет ему id
+func (r *ReviewRepository) Create(rev *models.Review) error {
+	res, err := r.db.Exec(`
+		INSERT INTO reviews (email, model, rating, text)
+		VALUES (?, ?, ?, ?)`,
+		rev.Email, rev.Model, rev.Rating, rev.Text)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	rev.ID = int(id)
+	return nil
+}
@@ -0,0 +1,230 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"renault-backend/models"
+	"strings"
+	"sync"
+)
+
+// ErrSearchUnavailable — SQLite собран без FTS5 (нужен go build -tags sqlite_fts5)
+var ErrSearchUnavailable = errors.New("полнотекстовый поиск недоступен: SQLite собран без FTS5")
+
+// SearchRepository — полнотекстовый индекс каталога на FTS5 (БД каталога).
+// Индекс обновляется триггерами на cars, car_features и car_specs, поэтому
+// его не нужно синхронизировать вручную ни в хендлерах, ни при импорте.
+type SearchRepository struct {
+	db *sql.DB
+
+	// словарь подсказок строится по всему индексу, поэтому кешируется до следующего
+	// изменения индекса (см. car_search_state)
+	mu       sync.Mutex
+	vocab    models.Vocabulary
+	vocabGen int64
+}
+
+func NewSearchRepository(db *sql.DB) *SearchRepository {
+	return &SearchRepository{db: db}
+}
+
+// Available — собран ли SQLite с поддержкой FTS5
+func (r *SearchRepository) Available() bool {
+	var used bool
+	err := r.db.QueryRow(`SELECT sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&used)
+	return err == nil && used
+}
+
+// ё в индексе заменяется на е, как и в запросе (см. models.SearchTokens)
+func searchText(expr string) string {
+	return fmt.Sprintf(`replace(replace(COALESCE(%s, ''), 'ё', 'е'), 'Ё', 'Е')`, expr)
+}
+
+// searchDocument — строка индекса для автомобилей, отобранных условием {where}
+var searchDocument = `
+	INSERT INTO car_search (car_id, title, category, description, features, specs)
+	SELECT c.id, ` + searchText("c.title") + `, ` + searchText("c.category") + `, ` + searchText("c.description") + `,
+		` + searchText("(SELECT group_concat(f.name, ' · ') FROM car_features f WHERE f.car_id = c.id)") + `,
+		` + searchText("(SELECT group_concat(s.name || ': ' || s.value, ' · ') FROM car_specs s WHERE s.car_id = c.id)") + `
+	FROM cars c WHERE {where};`
+
+// bumpGeneration отмечает изменение индекса: по поколению сбрасывается кеш словаря подсказок
+const bumpGeneration = `UPDATE car_search_state SET generation = generation + 1;`
+
+func reindexCar(idExpr string) string {
+	return `DELETE FROM car_search WHERE car_id = ` + idExpr + `;` +
+		strings.Replace(searchDocument, "{where}", "c.id = "+idExpr, 1) + bumpGeneration
+}
+
+// триггеры синхронизации: имя → определение
+var searchTriggers = [][2]string{
+	{"car_search_cars_ai", `AFTER INSERT ON cars BEGIN ` + reindexCar("NEW.id") + ` END`},
+	{"car_search_cars_au", `AFTER UPDATE OF id, title, category, description ON cars BEGIN
+		DELETE FROM car_search WHERE car_id = OLD.id; ` + reindexCar("NEW.id") + ` END`},
+	{"car_search_cars_ad", `AFTER DELETE ON cars BEGIN DELETE FROM car_search WHERE car_id = OLD.id; ` + bumpGeneration + ` END`},
+	// публикация и корзина не меняют документ, но меняют набор слов для подсказок
+	{"car_search_cars_visibility", `AFTER UPDATE OF status, deleted_at ON cars BEGIN ` + bumpGeneration + ` END`},
+	{"car_search_features_ai", `AFTER INSERT ON car_features BEGIN ` + reindexCar("NEW.car_id") + ` END`},
+	{"car_search_features_ad", `AFTER DELETE ON car_features BEGIN ` + reindexCar("OLD.car_id") + ` END`},
+	{"car_search_specs_ai", `AFTER INSERT ON car_specs BEGIN ` + reindexCar("NEW.car_id") + ` END`},
+	{"car_search_specs_au", `AFTER UPDATE ON car_specs BEGIN ` + reindexCar("NEW.car_id") + ` END`},
+	{"car_search_specs_ad", `AFTER DELETE ON car_specs BEGIN ` + reindexCar("OLD.car_id") + ` END`},
+}
+
+// InitTables создаёт индекс и триггеры и перестраивает индекс целиком.
+// Без FTS5 триггеры удаляются, чтобы запись в каталог не падала на ссылке
+// на виртуальную таблицу; поиск в этом случае отвечает ErrSearchUnavailable.
+func (r *SearchRepository) InitTables() error {
+	available := r.Available()
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, t := range searchTriggers {
+		if _, err := tx.Exec(`DROP TRIGGER IF EXISTS ` + t[0]); err != nil {
+			return err
+		}
+	}
+	if !available {
+		return tx.Commit()
+	}
+
+	_, err = tx.Exec(`
+	CREATE VIRTUAL TABLE IF NOT EXISTS car_search USING fts5(
+		car_id UNINDEXED, title, category, description, features, specs,
+		tokenize = 'unicode61 remove_diacritics 2'
+	)`)
+	if err != nil {
+		return fmt.Errorf("error creating search index: %v", err)
+	}
+	_, err = tx.Exec(`
+	CREATE TABLE IF NOT EXISTS car_search_state (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		generation INTEGER NOT NULL
+	);
+	INSERT OR IGNORE INTO car_search_state (id, generation) VALUES (1, 0)`)
+	if err != nil {
+		return fmt.Errorf("error creating search state: %v", err)
+	}
+	for _, t := range searchTriggers {
+		if _, err := tx.Exec(`CREATE TRIGGER ` + t[0] + ` ` + t[1]); err != nil {
+			return fmt.Errorf("error creating trigger %s: %v", t[0], err)
+		}
+	}
+
+	// индекс мог отстать, пока сервер работал без FTS5, — перестраиваем на старте
+	if _, err := tx.Exec(`DELETE FROM car_search`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(strings.Replace(searchDocument, "{where}", "1 = 1", 1)); err != nil {
+		return fmt.Errorf("error building search index: %v", err)
+	}
+	if _, err := tx.Exec(bumpGeneration); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// в поиске участвуют только опубликованные и не удалённые автомобили
+const searchWhere = `car_search MATCH ? AND c.deleted_at IS NULL AND c.status = 'published'`
+
+// Search ищет опубликованные автомобили по запросу FTS5 (см. models.SearchMatchQuery);
+// возвращает страницу результатов и общее число совпадений
+func (r *SearchRepository) Search(match string, limit int) ([]models.SearchResult, int, error) {
+	if !r.Available() {
+		return nil, 0, ErrSearchUnavailable
+	}
+
+	var total int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM car_search s JOIN cars c ON c.id = s.car_id
+		WHERE `+searchWhere, match).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// char(2)/char(3) — временные маркеры: фрагмент сначала экранируется, потом они становятся <mark>;
+	// веса bm25 по колонкам: car_id, title, category, description, features, specs
+	rows, err := r.db.Query(`
+		SELECT c.id, c.title, COALESCE(c.category, ''), COALESCE(c.image, ''), COALESCE(c.base_price, 0),
+			snippet(car_search, -1, char(2), char(3), '…', 12),
+			bm25(car_search, 0, 10.0, 4.0, 1.0, 2.0, 2.0) AS rank
+		FROM car_search s JOIN cars c ON c.id = s.car_id
+		WHERE `+searchWhere+`
+		ORDER BY rank, c.id
+		LIMIT ?`, match, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	results := []models.SearchResult{}
+	for rows.Next() {
+		var res models.SearchResult
+		var rank float64
+		if err := rows.Scan(&res.ID, &res.Title, &res.Category, &res.Image, &res.Price, &res.Snippet, &rank); err != nil {
+			return nil, 0, err
+		}
+		res.Snippet = highlightSnippet(res.Snippet)
+		res.Score = -rank // bm25 отрицателен: чем меньше, тем лучше
+		results = append(results, res)
+	}
+	return results, total, rows.Err()
+}
+
+var snippetMarks = strings.NewReplacer("\x02", "<mark>", "\x03", "</mark>")
+
+func highlightSnippet(s string) string {
+	return snippetMarks.Replace(html.EscapeString(s))
+}
+
+// Vocabulary — слова опубликованных автомобилей для подсказок. Словарь перечитывается,
+// только если индекс изменился с прошлого вызова; вызывающий не должен его менять.
+func (r *SearchRepository) Vocabulary() (models.Vocabulary, error) {
+	if !r.Available() {
+		return nil, ErrSearchUnavailable
+	}
+
+	var gen int64
+	if err := r.db.QueryRow(`SELECT generation FROM car_search_state`).Scan(&gen); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.vocab != nil && r.vocabGen == gen {
+		return r.vocab, nil
+	}
+	vocab, err := r.loadVocabulary()
+	if err != nil {
+		return nil, err
+	}
+	r.vocab, r.vocabGen = vocab, gen
+	return vocab, nil
+}
+
+func (r *SearchRepository) loadVocabulary() (models.Vocabulary, error) {
+	rows, err := r.db.Query(`
+		SELECT s.title, s.category, s.description, s.features, s.specs
+		FROM car_search s JOIN cars c ON c.id = s.car_id
+		WHERE c.deleted_at IS NULL AND c.status = 'published'`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	vocab := models.Vocabulary{}
+	for rows.Next() {
+		var title, category, description, features, specs string
+		if err := rows.Scan(&title, &category, &description, &features, &specs); err != nil {
+			return nil, err
+		}
+		vocab.Add(title, category, description, features, specs)
+	}
+	return vocab, rows.Err()
+}
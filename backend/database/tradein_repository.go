@@ -0,0 +1,380 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"path"
+	"renault-backend/models"
+	"time"
+)
+
+func createTradeInTables(logger *slog.Logger) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS trade_in_requests (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id TEXT NOT NULL,
+		make TEXT NOT NULL,
+		model TEXT NOT NULL,
+		year INTEGER NOT NULL,
+		mileage INTEGER NOT NULL,
+		condition TEXT NOT NULL,
+		comment TEXT NOT NULL DEFAULT '',
+		estimate_min INTEGER NOT NULL,
+		estimate_max INTEGER NOT NULL,
+		final_offer INTEGER,
+		manager_note TEXT NOT NULL DEFAULT '',
+		reviewed_by TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		reviewed_at TIMESTAMP,
+		applied_at TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS trade_in_photos (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		trade_in_id INTEGER NOT NULL,
+		path TEXT NOT NULL,
+		FOREIGN KEY (trade_in_id) REFERENCES trade_in_requests (id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS trade_in_depreciation (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		make TEXT NOT NULL,
+		model TEXT NOT NULL DEFAULT '',
+		base_price INTEGER NOT NULL,
+		annual_depreciation_pct REAL NOT NULL,
+		mileage_penalty_per_10k INTEGER NOT NULL DEFAULT 0,
+		UNIQUE (make, model)
+	)`
+	_, err := DB.Exec(query)
+	if err != nil {
+		return fmt.Errorf("error creating trade-in tables: %v", err)
+	}
+
+	var count int
+	if err := DB.QueryRow(`SELECT COUNT(*) FROM trade_in_depreciation`).Scan(&count); err != nil {
+		return err
+	}
+	if count == 0 {
+		// стартовые значения, дальше таблицу ведут менеджеры через админку
+		_, err = DB.Exec(`
+		INSERT INTO trade_in_depreciation (make, model, base_price, annual_depreciation_pct, mileage_penalty_per_10k)
+		VALUES
+			('renault', '', 1300000, 12, 15000),
+			('renault', 'logan', 950000, 11, 12000),
+			('renault', 'duster', 1450000, 10, 15000),
+			('lada', '', 900000, 13, 10000),
+			('kia', '', 1600000, 11, 18000),
+			('hyundai', '', 1600000, 11, 18000)`)
+		if err != nil {
+			return fmt.Errorf("error seeding trade-in depreciation: %v", err)
+		}
+	}
+
+	// заказ, в который зачтён trade-in; пока NULL, зачёт переходит в следующую корзину
+	if err := EnsureColumn(DB, "trade_in_requests", "order_id", "INTEGER"); err != nil {
+		return err
+	}
+
+	// аккаунт владельца (JWT): user_id — анонимная корзина из X-User-Id, его задаёт сам клиент.
+	// У старых заявок аккаунта нет, клиенту они не видны — только менеджерам.
+	if err := EnsureColumn(DB, "trade_in_requests", "account", "TEXT"); err != nil {
+		return err
+	}
+
+	logger.Debug("trade-in tables ready")
+	return nil
+}
+
+type TradeInRepository struct {
+	db *sql.DB
+}
+
+func NewTradeInRepository() *TradeInRepository {
+	return &TradeInRepository{db: DB}
+}
+
+const depreciationSelect = `
+	SELECT id, make, model, base_price, annual_depreciation_pct, mileage_penalty_per_10k
+	FROM trade_in_depreciation`
+
+// queryDepreciationRule возвращает одно правило или nil, если его нет
+func (r *TradeInRepository) queryDepreciationRule(query string, args ...any) (*models.DepreciationRule, error) {
+	var d models.DepreciationRule
+	err := r.db.QueryRow(depreciationSelect+query, args...).
+		Scan(&d.ID, &d.Make, &d.Model, &d.BasePrice, &d.AnnualDepreciationPct, &d.MileagePenaltyPer10k)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// FindDepreciationRule ищет правило для марки и модели, с откатом на правило марки
+func (r *TradeInRepository) FindDepreciationRule(carMake, model string) (*models.DepreciationRule, error) {
+	return r.queryDepreciationRule(`
+		WHERE make = LOWER(?) AND (model = LOWER(?) OR model = '')
+		ORDER BY model DESC
+		LIMIT 1`, carMake, model)
+}
+
+// GetDepreciationRule возвращает правило ровно для этой пары марка/модель, без отката
+func (r *TradeInRepository) GetDepreciationRule(carMake, model string) (*models.DepreciationRule, error) {
+	return r.queryDepreciationRule(` WHERE make = LOWER(?) AND model = LOWER(?)`, carMake, model)
+}
+
+// GetDepreciationRuleByID возвращает правило по id или nil, если его нет
+func (r *TradeInRepository) GetDepreciationRuleByID(id int) (*models.DepreciationRule, error) {
+	return r.queryDepreciationRule(` WHERE id = ?`, id)
+}
+
+func (r *TradeInRepository) ListDepreciationRules() ([]models.DepreciationRule, error) {
+	rows, err := r.db.Query(depreciationSelect + ` ORDER BY make, model`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []models.DepreciationRule{}
+	for rows.Next() {
+		var d models.DepreciationRule
+		if err := rows.Scan(&d.ID, &d.Make, &d.Model, &d.BasePrice, &d.AnnualDepreciationPct, &d.MileagePenaltyPer10k); err != nil {
+			return nil, err
+		}
+		rules = append(rules, d)
+	}
+	return rules, rows.Err()
+}
+
+// SaveDepreciationRule добавляет или заменяет правило для пары марка/модель
+func (r *TradeInRepository) SaveDepreciationRule(d *models.DepreciationRule) error {
+	_, err := r.db.Exec(`
+		INSERT INTO trade_in_depreciation (make, model, base_price, annual_depreciation_pct, mileage_penalty_per_10k)
+		VALUES (LOWER(?), LOWER(?), ?, ?, ?)
+		ON CONFLICT (make, model) DO UPDATE SET
+			base_price = excluded.base_price,
+			annual_depreciation_pct = excluded.annual_depreciation_pct,
+			mileage_penalty_per_10k = excluded.mileage_penalty_per_10k`,
+		d.Make, d.Model, d.BasePrice, d.AnnualDepreciationPct, d.MileagePenaltyPer10k)
+	return err
+}
+
+func (r *TradeInRepository) DeleteDepreciationRule(id int) (bool, error) {
+	res, err := r.db.Exec(`DELETE FROM trade_in_depreciation WHERE id = ?`, id)
+	if err != nil {
+		return false, err
+	}
+	affected, _ := res.RowsAffected()
+	return affected > 0, nil
+}
+
+// Create сохраняет заявку вместе с путями к фотографиям
+func (r *TradeInRepository) Create(t *models.TradeInRequest) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		INSERT INTO trade_in_requests (user_id, account, make, model, year, mileage, condition, comment,
+			estimate_min, estimate_max, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.UserID, t.Account, t.Make, t.Model, t.Year, t.Mileage, t.Condition, t.Comment,
+		t.EstimateMin, t.EstimateMax, t.Status)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	t.ID = int(id)
+
+	for _, p := range t.Photos {
+		if _, err := tx.Exec(`INSERT INTO trade_in_photos (trade_in_id, path) VALUES (?, ?)`, t.ID, p); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+const tradeInColumns = `id, user_id, account, make, model, year, mileage, condition, comment,
+	estimate_min, estimate_max, final_offer, manager_note, reviewed_by, status,
+	created_at, reviewed_at, applied_at, order_id`
+
+func scanTradeIn(row interface{ Scan(...any) error }) (*models.TradeInRequest, error) {
+	var t models.TradeInRequest
+	var finalOffer, orderID sql.NullInt64
+	var reviewedAt, appliedAt sql.NullTime
+	var account sql.NullString
+
+	err := row.Scan(&t.ID, &t.UserID, &account, &t.Make, &t.Model, &t.Year, &t.Mileage, &t.Condition, &t.Comment,
+		&t.EstimateMin, &t.EstimateMax, &finalOffer, &t.ManagerNote, &t.ReviewedBy, &t.Status,
+		&t.CreatedAt, &reviewedAt, &appliedAt, &orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	t.Account = account.String
+	if finalOffer.Valid {
+		v := int(finalOffer.Int64)
+		t.FinalOffer = &v
+	}
+	if reviewedAt.Valid {
+		t.ReviewedAt = &reviewedAt.Time
+	}
+	if appliedAt.Valid {
+		t.AppliedAt = &appliedAt.Time
+	}
+	if orderID.Valid {
+		v := int(orderID.Int64)
+		t.OrderID = &v
+	}
+	t.Photos = []string{}
+	return &t, nil
+}
+
+func (r *TradeInRepository) loadPhotos(t *models.TradeInRequest) error {
+	rows, err := r.db.Query(`SELECT path FROM trade_in_photos WHERE trade_in_id = ? ORDER BY id`, t.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			return err
+		}
+		// раньше сохранялся путь uploads/trade-in/<имя>, теперь только имя файла
+		t.Photos = append(t.Photos, path.Base(p))
+	}
+	return rows.Err()
+}
+
+// GetByID возвращает заявку или nil, если её нет
+func (r *TradeInRepository) GetByID(id int) (*models.TradeInRequest, error) {
+	t, err := scanTradeIn(r.db.QueryRow(`SELECT `+tradeInColumns+` FROM trade_in_requests WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadPhotos(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// List возвращает заявки; пустые account/status — без фильтра
+func (r *TradeInRepository) List(account, status string) ([]models.TradeInRequest, error) {
+	query := `SELECT ` + tradeInColumns + ` FROM trade_in_requests WHERE 1 = 1`
+	var args []any
+	if account != "" {
+		query += ` AND account = ?`
+		args = append(args, account)
+	}
+	if status != "" {
+		query += ` AND status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	list := []models.TradeInRequest{}
+	for rows.Next() {
+		t, err := scanTradeIn(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		list = append(list, *t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range list {
+		if err := r.loadPhotos(&list[i]); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// SetOffer фиксирует финальное предложение менеджера
+func (r *TradeInRepository) SetOffer(id, offer int, note, reviewer string) error {
+	_, err := r.db.Exec(`
+		UPDATE trade_in_requests
+		SET final_offer = ?, manager_note = ?, reviewed_by = ?, status = ?, reviewed_at = ?
+		WHERE id = ?`,
+		offer, note, reviewer, models.TradeInOffered, time.Now().UTC(), id)
+	return err
+}
+
+// SetStatus переводит заявку в новый статус, только если текущий равен from.
+// Возвращает false, если заявка уже в другом статусе. Зачёт в корзину — Apply.
+func (r *TradeInRepository) SetStatus(id int, from, to string) (bool, error) {
+	res, err := r.db.Exec(`UPDATE trade_in_requests SET status = ? WHERE id = ? AND status = ?`, to, id, from)
+	if err != nil {
+		return false, err
+	}
+	affected, _ := res.RowsAffected()
+	return affected > 0, nil
+}
+
+// Apply зачитывает принятое предложение в корзину userID, только если заявка в статусе accepted.
+// Зачёт забирает следующий заказ из этой корзины.
+func (r *TradeInRepository) Apply(id int, userID string) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE trade_in_requests SET status = ?, applied_at = ?, user_id = ?
+		WHERE id = ? AND status = ?`,
+		models.TradeInApplied, time.Now().UTC(), userID, id, models.TradeInAccepted)
+	if err != nil {
+		return false, err
+	}
+	affected, _ := res.RowsAffected()
+	return affected > 0, nil
+}
+
+// AppliedCredit — сумма зачтённых в корзину userID, но ещё не использованных в заказе trade-in
+// и их id; непустой account оставляет только заявки этого аккаунта.
+// Заказ забирает trade-in целиком, см. OrderRepository.Create.
+func (r *TradeInRepository) AppliedCredit(userID, account string) (int, []int, error) {
+	query := `
+		SELECT id, final_offer
+		FROM trade_in_requests
+		WHERE user_id = ? AND status = ? AND order_id IS NULL AND final_offer IS NOT NULL`
+	args := []any{userID, models.TradeInApplied}
+	if account != "" {
+		query += ` AND account = ?`
+		args = append(args, account)
+	}
+	rows, err := r.db.Query(query+` ORDER BY id`, args...)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	total, ids := 0, []int{}
+	for rows.Next() {
+		var id, offer int
+		if err := rows.Scan(&id, &offer); err != nil {
+			return 0, nil, err
+		}
+		total += offer
+		ids = append(ids, id)
+	}
+	return total, ids, rows.Err()
+}
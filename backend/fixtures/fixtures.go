@@ -0,0 +1,162 @@
+// Package fixtures — версионируемые данные для начального заполнения каталога.
+// Файлы лежат в data/ и встраиваются в бинарник; формат каталога совпадает
+// с выгрузкой `server catalog export -format json`.
+package fixtures
+
+import (
+	"bytes"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"renault-backend/database"
+	"renault-backend/models"
+	"sort"
+)
+
+//go:embed data
+var files embed.FS
+
+// Set — файлы фикстур одного окружения
+type Set struct {
+	Catalog    []string // каталоги автомобилей, загружаются по порядку
+	Promotions []string // акции, upsert по названию
+}
+
+// Environments — доступные окружения для `server seed -env`
+var Environments = map[string]Set{
+	// полный каталог сайта; им же заполняется пустая БД при первом запуске
+	"dev": {Catalog: []string{"data/catalog.json"}},
+	// каталог плюс акции для показа заказчику
+	"demo": {
+		Catalog:    []string{"data/catalog.json"},
+		Promotions: []string{"data/demo/promotions.json"},
+	},
+	// небольшой набор с известными данными: два опубликованных автомобиля и черновик
+	"test": {Catalog: []string{"data/test/catalog.json"}},
+}
+
+// EnvNames возвращает имена окружений по алфавиту
+func EnvNames() []string {
+	names := make([]string, 0, len(Environments))
+	for name := range Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Result — итог загрузки фикстур
+type Result struct {
+	Env               string                 `json:"env"`
+	Catalog           []*models.ImportReport `json:"catalog"`
+	PromotionsCreated int                    `json:"promotionsCreated"`
+	PromotionsUpdated int                    `json:"promotionsUpdated"`
+}
+
+// Load загружает фикстуры окружения. Автомобили импортируются с upsert по id,
+// акции — по названию, поэтому повторный запуск ничего не дублирует.
+// Каталог грузится атомарно: при ошибке в любой строке файл не применяется.
+func Load(env string, catalog *database.CatalogIORepository, promos *database.PromotionRepository, dryRun bool) (*Result, error) {
+	set, ok := Environments[env]
+	if !ok {
+		return nil, fmt.Errorf("неизвестное окружение %q", env)
+	}
+	res := &Result{Env: env}
+
+	for _, path := range set.Catalog {
+		data, err := files.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		cars, rows, err := models.ReadCatalogJSON(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		report, err := catalog.Import(cars, rows, nil, models.ImportOptions{
+			DryRun: dryRun, Atomic: true, Author: "seed:" + env,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		res.Catalog = append(res.Catalog, report)
+		if report.Failed > 0 {
+			return res, fmt.Errorf("%s: %d строк(и) с ошибками", path, report.Failed)
+		}
+	}
+
+	for _, path := range set.Promotions {
+		if err := loadPromotions(path, promos, dryRun, res); err != nil {
+			return res, fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	return res, nil
+}
+
+func loadPromotions(path string, repo *database.PromotionRepository, dryRun bool, res *Result) error {
+	data, err := files.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var list []models.Promotion
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	for i := range list {
+		if errs := models.ValidatePromotion(list[i]); len(errs) > 0 {
+			return fmt.Errorf("%q: %v", list[i].Name, errs)
+		}
+	}
+	if dryRun {
+		return nil
+	}
+	for i := range list {
+		created, err := repo.UpsertByName(&list[i])
+		if err != nil {
+			return err
+		}
+		if created {
+			res.PromotionsCreated++
+		} else {
+			res.PromotionsUpdated++
+		}
+	}
+	return nil
+}
+
+// SeedIfEmpty заполняет каталог фикстурами dev, если в нём ещё нет ни одного автомобиля
+// (включая удалённые в корзину). Возвращает true, если заполнение выполнялось.
+func SeedIfEmpty(db *sql.DB) (bool, error) {
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM cars`).Scan(&count); err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, nil
+	}
+	_, err := Load("dev", database.NewCatalogIORepository(db), database.NewPromotionRepository(db), false)
+	return err == nil, err
+}
+
+// NewTestCatalog открывает БД каталога в памяти, создаёт схему и загружает
+// фикстуры test — чтобы тесты хендлеров стартовали с известных данных.
+// Каждый вызов возвращает отдельную пустую БД.
+func NewTestCatalog() (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+	// у каждой новой :memory:-связи своя БД, поэтому держим одно соединение
+	db.SetMaxOpenConns(1)
+
+	if err := database.InitCatalog(db, slog.New(slog.DiscardHandler)); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if _, err := Load("test", database.NewCatalogIORepository(db), database.NewPromotionRepository(db), false); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
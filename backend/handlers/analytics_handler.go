@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"renault-backend/database"
+	"renault-backend/models"
+	"strconv"
+	"time"
+)
+
+const (
+	maxEventsPerRequest    = 50
+	defaultAnalyticsDays   = 30
+	defaultTopCategories   = 10
+	analyticsDayLayout     = "2006-01-02"
+	maxClientEventLateness = 24 * time.Hour
+)
+
+// EventRecorder принимает события аналитики; запись асинхронная (см. jobs.AnalyticsWriter).
+// false — событие не принято (переполнен буфер).
+type EventRecorder interface {
+	Record(e models.AnalyticsEvent) bool
+}
+
+// AnalyticsHandler — приём событий и отчёты для админки
+type AnalyticsHandler struct {
+	repo   *database.AnalyticsRepository
+	events EventRecorder
+}
+
+func NewAnalyticsHandler(repo *database.AnalyticsRepository, events EventRecorder) *AnalyticsHandler {
+	return &AnalyticsHandler{repo: repo, events: events}
+}
+
+type ingestRequest struct {
+	Events []models.AnalyticsEvent `json:"events"`
+}
+
+// Ingest — POST /api/events {"events": [{"type": "car_viewed", "carId": "duster"}]}
+// Для клиентов, которые показывают каталог без запросов к API (кэш, приложения):
+// принимаются только просмотры, остальные события хендлеры записывают сами.
+func (h *AnalyticsHandler) Ingest(w http.ResponseWriter, r *http.Request) {
+	var req ingestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if len(req.Events) == 0 || len(req.Events) > maxEventsPerRequest {
+		respondWithError(w, http.StatusBadRequest, "events: от 1 до "+strconv.Itoa(maxEventsPerRequest)+" событий")
+		return
+	}
+
+	now := time.Now()
+	for i, e := range req.Events {
+		if err := e.Validate(); err != nil {
+			respondWithError(w, http.StatusBadRequest, "events["+strconv.Itoa(i)+"]: "+err.Error())
+			return
+		}
+		// корзину, заявки и отзывы записывают их хендлеры; от клиента такие события были бы подделкой
+		if e.Type != models.EventCarViewed {
+			respondWithError(w, http.StatusBadRequest, "events["+strconv.Itoa(i)+"]: принимается только "+models.EventCarViewed)
+			return
+		}
+		// покупатель — из заголовка, а не из тела; время клиента принимаем, только если оно правдоподобно
+		e.UserID = getUserID(r)
+		if e.OccurredAt.After(now) || e.OccurredAt.Before(now.Add(-maxClientEventLateness)) {
+			e.OccurredAt = now
+		}
+		req.Events[i] = e
+	}
+
+	// просмотр несуществующего или неопубликованного автомобиля попал бы в отчёты и рекомендации
+	ids := make([]string, len(req.Events))
+	for i, e := range req.Events {
+		ids[i] = e.CarID
+	}
+	published, err := h.repo.PublishedCars(ids)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	for i, e := range req.Events {
+		if !published[e.CarID] {
+			respondWithError(w, http.StatusBadRequest, "events["+strconv.Itoa(i)+"]: автомобиль "+e.CarID+" не найден")
+			return
+		}
+	}
+
+	accepted := 0
+	for _, e := range req.Events {
+		if h.events.Record(e) {
+			accepted++
+		}
+	}
+	respondWithJSON(w, http.StatusAccepted, ingestResponse{Accepted: accepted, Dropped: len(req.Events) - accepted})
+}
+
+// analyticsRange читает ?from=&to= (YYYY-MM-DD, включительно); по умолчанию — последние 30 дней
+func analyticsRange(w http.ResponseWriter, r *http.Request) (models.AnalyticsRange, bool) {
+	today := time.Now().UTC()
+	rng := models.AnalyticsRange{
+		From: today.AddDate(0, 0, -(defaultAnalyticsDays - 1)).Format(analyticsDayLayout),
+		To:   today.Format(analyticsDayLayout),
+	}
+	for _, p := range []struct {
+		param string
+		dst   *string
+	}{{"from", &rng.From}, {"to", &rng.To}} {
+		v := r.URL.Query().Get(p.param)
+		if v == "" {
+			continue
+		}
+		if _, err := time.Parse(analyticsDayLayout, v); err != nil {
+			respondWithError(w, http.StatusBadRequest, p.param+" должен быть датой YYYY-MM-DD")
+			return rng, false
+		}
+		*p.dst = v
+	}
+	if rng.From > rng.To {
+		respondWithError(w, http.StatusBadRequest, "from позже to")
+		return rng, false
+	}
+	return rng, true
+}
+
+// Views — просмотры моделей по дням: GET /api/admin/analytics/views?from=&to=&carId=
+func (h *AnalyticsHandler) Views(w http.ResponseWriter, r *http.Request) {
+	rng, ok := analyticsRange(w, r)
+	if !ok {
+		return
+	}
+	views, err := h.repo.ViewsPerCar(rng, r.URL.Query().Get("carId"))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, views)
+}
+
+// Funnel — воронка «просмотр → корзина → заявка»: GET /api/admin/analytics/funnel?from=&to=
+func (h *AnalyticsHandler) Funnel(w http.ResponseWriter, r *http.Request) {
+	rng, ok := analyticsRange(w, r)
+	if !ok {
+		return
+	}
+	funnel, err := h.repo.Funnel(rng)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, funnel)
+}
+
+// Categories — самые просматриваемые категории: GET /api/admin/analytics/categories?from=&to=&limit=
+func (h *AnalyticsHandler) Categories(w http.ResponseWriter, r *http.Request) {
+	rng, ok := analyticsRange(w, r)
+	if !ok {
+		return
+	}
+	limit := defaultTopCategories
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			respondWithError(w, http.StatusBadRequest, "limit должен быть положительным числом")
+			return
+		}
+		limit = n
+	}
+	stats, err := h.repo.TopCategories(rng, limit)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, stats)
+}
+
+// Ratings — оценки отзывов по дням: GET /api/admin/analytics/ratings?from=&to=
+func (h *AnalyticsHandler) Ratings(w http.ResponseWriter, r *http.Request) {
+	rng, ok := analyticsRange(w, r)
+	if !ok {
+		return
+	}
+	ratings, err := h.repo.Ratings(rng)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, ratings)
+}
+
+// RefreshRollups — пересчитать свёртки сразу, не дожидаясь фоновой задачи:
+// POST /api/admin/analytics/refresh
+func (h *AnalyticsHandler) RefreshRollups(w http.ResponseWriter, r *http.Request) {
+	n, err := h.repo.RefreshRollups()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, refreshResponse{Events: n})
+}
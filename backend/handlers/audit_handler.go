@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"renault-backend/database"
+	"renault-backend/logging"
+	"renault-backend/models"
+	"renault-backend/realip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const auditEntryKey contextKey = "audit_entry"
+
+// сколько байт тела запроса сохраняем в журнал, если хендлер не передал снимок сам
+const maxAuditBody = 64 << 10
+
+// AuditMiddleware пишет в журнал каждый успешный изменяющий запрос администратора.
+// Хендлер может уточнить действие и передать снимки до/после через SetAuditChange;
+// иначе сущность берётся из маршрута, а «после» — из тела запроса без паролей и токенов.
+func AuditMiddleware(repo *database.AuditRepository, ips *realip.Resolver) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			entry := &models.AuditEntry{
+				Actor: AdminUsername(r),
+				IP:    ips.ClientIP(r),
+				Path:  r.URL.Path,
+			}
+			entry.Entity, entry.Action = auditRouteAction(r)
+			entry.EntityID = mux.Vars(r)["id"]
+
+			if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+				body, err := io.ReadAll(io.LimitReader(r.Body, maxAuditBody+1))
+				if err == nil {
+					if len(body) <= maxAuditBody {
+						entry.After = redactAuditBody(body)
+					}
+					r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+				}
+			}
+
+			rec := logging.NewResponseRecorder(w)
+			next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), auditEntryKey, entry)))
+
+			// неудачные запросы ничего не меняли
+			if rec.Status >= 300 {
+				return
+			}
+			entry.Status = rec.Status
+			entry.Diff = models.AuditDiff(entry.Before, entry.After)
+			if err := repo.Record(entry); err != nil {
+				logging.FromContext(r.Context()).Error("audit: record", "action", entry.Action, "entity_id", entry.EntityID, "actor", entry.Actor, "err", err)
+			}
+		})
+	}
+}
+
+// SetAuditChange уточняет запись журнала для текущего запроса:
+// действие, сущность и снимки до/после (nil — снимка нет)
+func SetAuditChange(r *http.Request, action, entity, entityID string, before, after any) {
+	entry, ok := r.Context().Value(auditEntryKey).(*models.AuditEntry)
+	if !ok {
+		return
+	}
+	entry.Action = action
+	entry.Entity = entity
+	entry.EntityID = entityID
+	entry.Before = auditSnapshot(before)
+	entry.After = auditSnapshot(after)
+}
+
+func auditSnapshot(v any) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// части имён полей, значения которых не попадают в журнал из тела запроса
+var auditSecretFields = []string{"password", "token", "secret"}
+
+// redactAuditBody возвращает JSON тела запроса со скрытыми паролями и токенами;
+// nil, если тело не JSON
+func redactAuditBody(body []byte) json.RawMessage {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return nil
+	}
+	return auditSnapshot(redactAuditValue(v))
+}
+
+func redactAuditValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, field := range v {
+			if isAuditSecret(k) {
+				v[k] = "***"
+			} else {
+				v[k] = redactAuditValue(field)
+			}
+		}
+	case []any:
+		for i := range v {
+			v[i] = redactAuditValue(v[i])
+		}
+	}
+	return v
+}
+
+func isAuditSecret(field string) bool {
+	field = strings.ToLower(field)
+	for _, s := range auditSecretFields {
+		if strings.Contains(field, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// auditRouteAction выводит сущность и действие из шаблона маршрута:
+// PUT /api/admin/leads/{id}/status → ("leads", "leads.status"),
+// DELETE /api/admin/promotions/{id} → ("promotions", "promotions.delete")
+func auditRouteAction(r *http.Request) (entity, action string) {
+	path := r.URL.Path
+	if route := mux.CurrentRoute(r); route != nil {
+		if tpl, err := route.GetPathTemplate(); err == nil {
+			path = tpl
+		}
+	}
+	path = strings.TrimPrefix(path, "/api/admin")
+
+	var parts []string
+	for _, p := range strings.Split(strings.Trim(path, "/"), "/") {
+		if p != "" && !strings.HasPrefix(p, "{") {
+			parts = append(parts, p)
+		}
+	}
+	if len(parts) == 0 {
+		return "admin", strings.ToLower(r.Method)
+	}
+
+	entity = parts[0]
+	if len(parts) > 1 {
+		return entity, entity + "." + parts[len(parts)-1]
+	}
+	verb := map[string]string{
+		http.MethodPost:   "create",
+		http.MethodPut:    "update",
+		http.MethodPatch:  "update",
+		http.MethodDelete: "delete",
+	}[r.Method]
+	if verb == "" {
+		verb = strings.ToLower(r.Method)
+	}
+	return entity, entity + "." + verb
+}
+
+// AuditHandler — просмотр журнала действий
+type AuditHandler struct {
+	repo *database.AuditRepository
+}
+
+func NewAuditHandler(repo *database.AuditRepository) *AuditHandler {
+	return &AuditHandler{repo: repo}
+}
+
+// List — GET /api/admin/audit?actor=&action=&entity=&entityId=&from=&to=&limit=&offset=
+// from/to — RFC3339 или YYYY-MM-DD
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := models.AuditFilter{
+		Actor:    q.Get("actor"),
+		Action:   q.Get("action"),
+		Entity:   q.Get("entity"),
+		EntityID: q.Get("entityId"),
+		Limit:    100,
+	}
+
+	var err error
+	if f.From, err = parseAuditTime(q.Get("from")); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid from")
+		return
+	}
+	if f.To, err = parseAuditTime(q.Get("to")); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid to")
+		return
+	}
+	if v := q.Get("limit"); v != "" {
+		if f.Limit, err = strconv.Atoi(v); err != nil || f.Limit <= 0 || f.Limit > 1000 {
+			respondWithError(w, http.StatusBadRequest, "limit должен быть от 1 до 1000")
+			return
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if f.Offset, err = strconv.Atoi(v); err != nil || f.Offset < 0 {
+			respondWithError(w, http.StatusBadRequest, "invalid offset")
+			return
+		}
+	}
+
+	entries, err := h.repo.List(f)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, entries)
+}
+
+func parseAuditTime(v string) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"renault-backend/database"
+	"renault-backend/models"
+	"renault-backend/realip"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestRedactAuditBody(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"name":"Весна","value":10}`, `{"name":"Весна","value":10}`},
+		{`{"username":"bob","password":"secret1","confirm_password":"secret1"}`,
+			`{"confirm_password":"***","password":"***","username":"bob"}`},
+		{`{"items":[{"apiToken":"x","id":1}],"clientSecret":{"a":1}}`,
+			`{"clientSecret":"***","items":[{"apiToken":"***","id":1}]}`},
+		{`12345678901234567890`, `12345678901234567890`}, // числа не теряют точность
+		{`not json`, ``},
+		{`{"a":1} {"b":2}`, ``},
+	}
+	for _, tt := range tests {
+		if got := string(redactAuditBody([]byte(tt.body))); got != tt.want {
+			t.Errorf("redactAuditBody(%s) = %s, want %s", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestAuditMiddlewareRecordsBeforeAndAfter(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1) // у каждого соединения своя база в памяти
+	prev := database.DB
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = prev
+		db.Close()
+	})
+	if err := database.CreateTables(slog.New(slog.DiscardHandler)); err != nil {
+		t.Fatal(err)
+	}
+	promos := database.NewPromotionRepository(db)
+	if err := promos.InitTables(); err != nil {
+		t.Fatal(err)
+	}
+	p := models.Promotion{Name: "Весна", Kind: models.PromoKindPercent, Value: 5, Scope: models.PromoScopeCart, Active: true}
+	if err := promos.Create(&p); err != nil {
+		t.Fatal(err)
+	}
+
+	auditRepo := database.NewAuditRepository()
+	router := mux.NewRouter()
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(WithAdminUsername(r.Context(), "admin")))
+		})
+	}, AuditMiddleware(auditRepo, realip.New("")))
+	router.HandleFunc("/api/admin/promotions/{id:[0-9]+}", NewPromotionHandler(promos).Update).Methods("PUT")
+
+	id := strconv.Itoa(p.ID)
+	req := httptest.NewRequest(http.MethodPut, "/api/admin/promotions/"+id,
+		strings.NewReader(`{"name":"Весна","kind":"percent","value":15,"scope":"cart","active":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d (%s)", rec.Code, rec.Body)
+	}
+
+	entries, err := auditRepo.List(models.AuditFilter{EntityID: id, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Action != "promotion.update" || e.Entity != "promotion" || e.Actor != "admin" {
+		t.Errorf("entry = %s %s by %s", e.Action, e.Entity, e.Actor)
+	}
+	var diff map[string]models.FieldChange
+	if err := json.Unmarshal(e.Diff, &diff); err != nil {
+		t.Fatalf("diff %s: %v", e.Diff, err)
+	}
+	if len(diff) != 1 || string(diff["value"].Before) != "5" || string(diff["value"].After) != "15" {
+		t.Errorf("diff = %s, want only value 5 → 15", e.Diff)
+	}
+}
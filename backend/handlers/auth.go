@@ -83,10 +83,8 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	println(user.Username, "username")
 	// ЕЩЁ РАЗ читаем пользователя из БД, чтобы получить ID
 	createdUser, err := h.userRepo.GetUserByUsername(user.Username)
-	println(createdUser.Email, "email is register user")
 	if err != nil {
 		sendError(w, "Ошибка при получении данных пользователя", http.StatusInternalServerError, nil)
 		return
@@ -123,7 +121,6 @@ func (h *AuthHandler) isAdmin(username string) bool {
 		"SELECT COUNT(*) FROM admins WHERE username = ?",
 		username,
 	).Scan(&count)
-	println(count, "true or false on table admins")
 	if err != nil {
 		return false
 	}
@@ -202,11 +199,7 @@ func (h *AuthHandler) ValidatePassword(w http.ResponseWriter, r *http.Request) {
 
 	isValid, errors := models.ValidatePassword(req.Password, h.passwordValidation)
 
-	response := struct {
-		Valid  bool     `json:"valid"`
-		Errors []string `json:"errors,omitempty"`
-		Score  int      `json:"score"`
-	}{
+	response := passwordCheckResponse{
 		Valid:  isValid,
 		Errors: errors,
 		Score:  calculatePasswordScore(req.Password),
@@ -403,28 +396,15 @@ func (h *AuthHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(users)
 }
 
-// HealthCheck проверка работоспособности сервера
-func HealthCheck(w http.ResponseWriter, r *http.Request) {
-	response := map[string]interface{}{
-		"status":  "ok",
-		"service": "Renault Backend API",
-		"time":    time.Now().Format(time.RFC3339),
-		"version": "1.0.0",
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
-}
-
 // PasswordRules возвращает правила для пароля
 func (h *AuthHandler) PasswordRules(w http.ResponseWriter, r *http.Request) {
-	rules := map[string]interface{}{
-		"min_length":      h.passwordValidation.MinLength,
-		"require_upper":   h.passwordValidation.RequireUpper,
-		"require_lower":   h.passwordValidation.RequireLower,
-		"require_number":  h.passwordValidation.RequireNumber,
-		"require_special": h.passwordValidation.RequireSpecial,
-		"rules": []string{
+	rules := passwordRulesResponse{
+		MinLength:      h.passwordValidation.MinLength,
+		RequireUpper:   h.passwordValidation.RequireUpper,
+		RequireLower:   h.passwordValidation.RequireLower,
+		RequireNumber:  h.passwordValidation.RequireNumber,
+		RequireSpecial: h.passwordValidation.RequireSpecial,
+		Rules: []string{
 			"Минимум 8 символов",
 			"Хотя бы одна заглавная буква",
 			"Хотя бы одна строчная буква",
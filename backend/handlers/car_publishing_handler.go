@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"renault-backend/database"
+	"renault-backend/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// сколько живёт ссылка предпросмотра, если ttl не указан
+const defaultPreviewTTL = 24 * time.Hour
+
+// CarPublishingHandler — статусы публикации и предпросмотр черновиков
+type CarPublishingHandler struct {
+	repo          *database.CarPublishingRepository
+	previewSecret []byte
+}
+
+func NewCarPublishingHandler(repo *database.CarPublishingRepository, previewSecret []byte) *CarPublishingHandler {
+	return &CarPublishingHandler{repo: repo, previewSecret: previewSecret}
+}
+
+// SetStatus — статус и расписание: PUT /api/admin/cars/{id}/status
+// {"status": "draft", "publishAt": "2026-03-01T09:00:00+03:00", "unpublishAt": null}
+func (h *CarPublishingHandler) SetStatus(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var p models.CarPublishing
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if errs := models.ValidateCarPublishing(p); len(errs) > 0 {
+		respondWithError(w, http.StatusBadRequest, strings.Join(errs, "; "))
+		return
+	}
+	p = p.InUTC()
+
+	before, err := h.repo.Get(id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if before == nil {
+		respondWithError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	if _, err := h.repo.Set(id, p); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+
+	SetAuditChange(r, "car.status", "car", id, before, p)
+	respondWithJSON(w, http.StatusOK, p)
+}
+
+// PreviewLink — подписанная ссылка на карточку в любом статусе:
+// POST /api/admin/cars/{id}/preview-link?ttl=48h
+func (h *CarPublishingHandler) PreviewLink(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	ttl := defaultPreviewTTL
+	if v := r.URL.Query().Get("ttl"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 || d > 30*24*time.Hour {
+			respondWithError(w, http.StatusBadRequest, "ttl должен быть от 1s до 720h")
+			return
+		}
+		ttl = d
+	}
+
+	p, err := h.repo.Get(id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if p == nil {
+		respondWithError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	expires := time.Now().Add(ttl).Unix()
+	q := url.Values{}
+	q.Set("expires", strconv.FormatInt(expires, 10))
+	q.Set("sig", SignCarPreview(h.previewSecret, id, expires))
+
+	respondWithJSON(w, http.StatusOK, previewLinkResponse{
+		URL:       "/api/cars/" + url.PathEscape(id) + "/preview?" + q.Encode(),
+		ExpiresAt: time.Unix(expires, 0).UTC(),
+	})
+}
+
+// SignCarPreview — HMAC-подпись ссылки предпросмотра автомобиля до момента expires (unix)
+func SignCarPreview(secret []byte, carID string, expires int64) string {
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "car-preview:%s:%d", carID, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyCarPreview проверяет подпись и срок действия ссылки предпросмотра
+func VerifyCarPreview(secret []byte, carID string, expires int64, sig string, now time.Time) bool {
+	if expires <= now.Unix() {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	want, _ := hex.DecodeString(SignCarPreview(secret, carID, expires))
+	return hmac.Equal(got, want)
+}
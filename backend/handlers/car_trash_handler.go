@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+	"renault-backend/database"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// CarTrashHandler — корзина удалённых автомобилей в админке
+type CarTrashHandler struct {
+	repo      *database.CarTrashRepository
+	retention time.Duration
+}
+
+func NewCarTrashHandler(repo *database.CarTrashRepository, retention time.Duration) *CarTrashHandler {
+	return &CarTrashHandler{repo: repo, retention: retention}
+}
+
+// List — удалённые автомобили: GET /api/admin/cars/trash
+func (h *CarTrashHandler) List(w http.ResponseWriter, r *http.Request) {
+	cars, err := h.repo.List(h.retention)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, cars)
+}
+
+// Restore — вернуть автомобиль в каталог: POST /api/admin/cars/{id}/restore
+func (h *CarTrashHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	found, err := h.repo.Restore(id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if !found {
+		respondWithError(w, http.StatusNotFound, "автомобиль не найден в корзине")
+		return
+	}
+
+	SetAuditChange(r, "car.restore", "car", id, nil, nil)
+	respondWithJSON(w, http.StatusOK, restoredResponse{Status: "restored", ID: id})
+}
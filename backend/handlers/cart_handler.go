@@ -4,21 +4,39 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"time"
 
 	"renault-backend/database"
+	"renault-backend/models"
 
 	"github.com/gorilla/mux"
 )
 
 type CartHandler struct {
-	db *sql.DB
+	db       *sql.DB
+	repo     *database.CartRepository
+	orders   *database.OrderRepository
+	prices   *database.PriceRepository
+	promos   *database.PromotionRepository
+	tradeIns *database.TradeInRepository
+	events   EventRecorder
 }
 
-func NewCartHandler() *CartHandler {
-	h := &CartHandler{db: database.DB}
+func NewCartHandler(prices *database.PriceRepository, promos *database.PromotionRepository,
+	tradeIns *database.TradeInRepository, events EventRecorder, logger *slog.Logger) *CartHandler {
+	h := &CartHandler{
+		db:       database.DB,
+		repo:     database.NewCartRepository(),
+		orders:   database.NewOrderRepository(),
+		prices:   prices,
+		promos:   promos,
+		tradeIns: tradeIns,
+		events:   events,
+	}
 	if err := h.initCartTable(); err != nil {
-		fmt.Println("initCartTable error:", err)
+		logger.Error("cart: init table", "err", err)
 	}
 	return h
 }
@@ -37,13 +55,6 @@ func (h *CartHandler) initCartTable() error {
 	return err
 }
 
-type CartItem struct {
-	ID       int    `json:"id"`
-	UserID   string `json:"userId"`
-	CarID    string `json:"carId"`
-	Quantity int    `json:"quantity"`
-}
-
 type addToCartRequest struct {
 	CarID    string `json:"carId"`
 	Quantity int    `json:"quantity"`
@@ -57,6 +68,27 @@ func getUserID(r *http.Request) string {
 	return r.Header.Get("X-User-Id")
 }
 
+// cartLine — позиция корзины с данными автомобиля и ценой после акций
+type cartLine struct {
+	database.CartItemResponse
+	DiscountedUnitPrice int                       `json:"discountedUnitPrice"`
+	LineTotal           int                       `json:"lineTotal"`
+	Promotions          []models.AppliedPromotion `json:"promotions"`
+}
+
+type cartResponse struct {
+	Items          []cartLine                `json:"items"`
+	ItemCount      int                       `json:"itemCount"` // сумма количеств по доступным позициям
+	PromoCodes     []string                  `json:"promoCodes"`
+	Subtotal       int                       `json:"subtotal"` // по ценам на момент добавления
+	CartPromotions []models.AppliedPromotion `json:"cartPromotions"`
+	Discount       int                       `json:"discount"`      // скидки по позициям и на корзину
+	TradeInCredit  int                       `json:"tradeInCredit"` // зачтённые trade-in предложения
+	Total          int                       `json:"total"`
+
+	tradeInIDs []int // зачтённые trade-in; при оформлении привязываются к заказу
+}
+
 // ---------- GET /api/cart ----------
 func (h *CartHandler) GetCart(w http.ResponseWriter, r *http.Request) {
 	userID := getUserID(r)
@@ -65,30 +97,210 @@ func (h *CartHandler) GetCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := h.db.Query(`
-        SELECT id, user_id, car_id, quantity
-        FROM cart_items
-        WHERE user_id = ?
-        ORDER BY id
-    `, userID)
+	items, err := h.repo.GetCartDetailed(userID)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("db error GetCart: %v", err), http.StatusInternalServerError)
 		return
 	}
-	defer rows.Close()
 
-	var items []CartItem
-	for rows.Next() {
-		var it CartItem
-		if err := rows.Scan(&it.ID, &it.UserID, &it.CarID, &it.Quantity); err != nil {
-			http.Error(w, fmt.Sprintf("scan error GetCart: %v", err), http.StatusInternalServerError)
-			return
+	resp, err := h.priceCart(userID, items)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("db error GetCart: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// priceCart применяет акции, промокоды и trade-in пользователя к позициям корзины.
+// Удалённые из каталога автомобили остаются в ответе с флагом carDeleted, но в сумму не входят.
+func (h *CartHandler) priceCart(userID string, items []database.CartItemResponse) (*cartResponse, error) {
+	promos, err := h.promos.ForUser(userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	credit, tradeInIDs, err := h.tradeIns.AppliedCredit(userID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &cartResponse{
+		Items:          make([]cartLine, 0, len(items)),
+		PromoCodes:     []string{},
+		CartPromotions: []models.AppliedPromotion{},
+	}
+	for _, p := range promos {
+		if p.Code != "" {
+			resp.PromoCodes = append(resp.PromoCodes, p.Code)
+		}
+	}
+
+	discountedSubtotal := 0
+	for _, it := range items {
+		line := cartLine{CartItemResponse: it, Promotions: []models.AppliedPromotion{}}
+		if !it.CarDeleted {
+			line.DiscountedUnitPrice, line.Promotions = models.PriceForCar(it.CarID, it.Category, it.UnitPrice, promos)
+			line.LineTotal = line.DiscountedUnitPrice * it.Quantity
+
+			resp.ItemCount += it.Quantity
+			resp.Subtotal += it.UnitPrice * it.Quantity
+			discountedSubtotal += line.LineTotal
+		}
+		resp.Items = append(resp.Items, line)
+	}
+
+	cartDiscount, cartApplied := models.CartDiscount(discountedSubtotal, promos)
+	resp.CartPromotions = cartApplied
+	afterDiscounts := discountedSubtotal - cartDiscount
+	resp.Discount = resp.Subtotal - afterDiscounts
+
+	// trade-in зачитываем только если в корзине есть что оплачивать
+	if afterDiscounts > 0 && credit > 0 {
+		resp.TradeInCredit = credit
+		if resp.TradeInCredit > afterDiscounts {
+			resp.TradeInCredit = afterDiscounts
+		}
+		resp.tradeInIDs = tradeInIDs
+	}
+	resp.Total = afterDiscounts - resp.TradeInCredit
+
+	return resp, nil
+}
+
+// ---------- POST /api/cart/checkout ----------
+// Checkout оформляет корзину в заказ: фиксирует суммы, списывает зачтённые trade-in и очищает корзину
+func (h *CartHandler) Checkout(w http.ResponseWriter, r *http.Request) {
+	userID := getUserID(r)
+	if userID == "" {
+		http.Error(w, "missing X-User-Id", http.StatusBadRequest)
+		return
+	}
+
+	items, err := h.repo.GetCartDetailed(userID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("db error Checkout: %v", err), http.StatusInternalServerError)
+		return
+	}
+	cart, err := h.priceCart(userID, items)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("db error Checkout: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if cart.ItemCount == 0 {
+		http.Error(w, "cart is empty", http.StatusBadRequest)
+		return
+	}
+
+	order := models.Order{
+		UserID:        userID,
+		Items:         []models.OrderItem{},
+		Subtotal:      cart.Subtotal,
+		Discount:      cart.Discount,
+		TradeInCredit: cart.TradeInCredit,
+		Total:         cart.Total,
+		TradeInIDs:    cart.tradeInIDs,
+	}
+	if order.TradeInIDs == nil {
+		order.TradeInIDs = []int{}
+	}
+	order.PromoCodes = appliedCodes(cart)
+	for _, line := range cart.Items {
+		if line.CarDeleted {
+			continue
 		}
-		items = append(items, it)
+		order.Items = append(order.Items, models.OrderItem{
+			CarID:     line.CarID,
+			Title:     line.Title,
+			Quantity:  line.Quantity,
+			UnitPrice: line.DiscountedUnitPrice,
+			LineTotal: line.LineTotal,
+		})
+	}
+
+	err = h.orders.Create(&order)
+	if err == database.ErrTradeInConsumed || err == database.ErrPromoExhausted {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("db error Checkout: %v", err), http.StatusInternalServerError)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	json.NewEncoder(w).Encode(items)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(order)
+}
+
+// appliedCodes — промокоды, которые дали скидку хотя бы одной позиции или корзине
+func appliedCodes(cart *cartResponse) []string {
+	codes := []string{}
+	seen := map[string]bool{}
+	add := func(list []models.AppliedPromotion) {
+		for _, p := range list {
+			if p.Code != "" && !seen[p.Code] {
+				seen[p.Code] = true
+				codes = append(codes, p.Code)
+			}
+		}
+	}
+	for _, line := range cart.Items {
+		add(line.Promotions)
+	}
+	add(cart.CartPromotions)
+	return codes
+}
+
+type promoCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// ---------- POST /api/cart/promo ----------
+func (h *CartHandler) ApplyPromoCode(w http.ResponseWriter, r *http.Request) {
+	userID := getUserID(r)
+	if userID == "" {
+		http.Error(w, "missing X-User-Id", http.StatusBadRequest)
+		return
+	}
+
+	var req promoCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		http.Error(w, "code is required", http.StatusBadRequest)
+		return
+	}
+
+	_, err := h.promos.Redeem(req.Code, userID, Username(r), time.Now())
+	if err == database.ErrPromoLoginRequired {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if err == database.ErrPromoNotFound || err == database.ErrPromoExhausted || err == database.ErrPromoUsed {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("db promo error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.GetCart(w, r)
+}
+
+// ---------- DELETE /api/cart/promo/{code} ----------
+func (h *CartHandler) RemovePromoCode(w http.ResponseWriter, r *http.Request) {
+	userID := getUserID(r)
+	if userID == "" {
+		http.Error(w, "missing X-User-Id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.promos.Release(mux.Vars(r)["code"], userID); err != nil {
+		http.Error(w, fmt.Sprintf("db promo error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.GetCart(w, r)
 }
 
 // ---------- POST /api/cart ----------
@@ -112,6 +324,17 @@ func (h *CartHandler) AddToCart(w http.ResponseWriter, r *http.Request) {
 		req.Quantity = 1
 	}
 
+	// действующая цена; заодно проверяем, что автомобиль опубликован в каталоге
+	price, err := h.prices.PublishedPrice(req.CarID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "car not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("db price error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
 	// upsert без ON CONFLICT: сначала UPDATE, если не затронуло строк — INSERT
 	tx, err := h.db.Begin()
 	if err != nil {
@@ -119,12 +342,14 @@ func (h *CartHandler) AddToCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	now := time.Now().UTC()
+
 	// пробуем увеличить quantity
 	res, err := tx.Exec(`
         UPDATE cart_items
-        SET quantity = quantity + ?
+        SET quantity = quantity + ?, updated_at = ?
         WHERE user_id = ? AND car_id = ?
-    `, req.Quantity, userID, req.CarID)
+    `, req.Quantity, now, userID, req.CarID)
 	if err != nil {
 		tx.Rollback()
 		http.Error(w, fmt.Sprintf("db update error: %v", err), http.StatusInternalServerError)
@@ -139,11 +364,11 @@ func (h *CartHandler) AddToCart(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if rowsAffected == 0 {
-		// записи не было — вставляем новую
+		// записи не было — вставляем новую, зафиксировав действующую цену
 		_, err = tx.Exec(`
-            INSERT INTO cart_items (user_id, car_id, quantity)
-            VALUES (?, ?, ?)
-        `, userID, req.CarID, req.Quantity)
+            INSERT INTO cart_items (user_id, car_id, quantity, unit_price, created_at, updated_at)
+            VALUES (?, ?, ?, ?, ?, ?)
+        `, userID, req.CarID, req.Quantity, price, now, now)
 		if err != nil {
 			tx.Rollback()
 			http.Error(w, fmt.Sprintf("db insert error: %v", err), http.StatusInternalServerError)
@@ -156,6 +381,8 @@ func (h *CartHandler) AddToCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.events.Record(models.AnalyticsEvent{Type: models.EventAddedToCart, CarID: req.CarID, UserID: userID})
+
 	// вернём актуальную корзину
 	h.GetCart(w, r)
 }
@@ -193,9 +420,9 @@ func (h *CartHandler) UpdateQuantity(w http.ResponseWriter, r *http.Request) {
 	} else {
 		_, err := h.db.Exec(`
             UPDATE cart_items
-            SET quantity = ?
+            SET quantity = ?, updated_at = ?
             WHERE user_id = ? AND car_id = ?
-        `, req.Quantity, userID, carID)
+        `, req.Quantity, time.Now().UTC(), userID, carID)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("db update error: %v", err), http.StatusInternalServerError)
 			return
@@ -249,5 +476,11 @@ func (h *CartHandler) Clear(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// пустой корзине промокоды не нужны — освобождаем лимит
+	if err := h.promos.ReleaseAll(userID); err != nil {
+		http.Error(w, fmt.Sprintf("db promo error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
 	h.GetCart(w, r)
 }
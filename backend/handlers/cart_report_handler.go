@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"renault-backend/database"
+	"strconv"
+	"time"
+)
+
+// CartReportHandler — отчёты по корзинам для админки
+type CartReportHandler struct {
+	repo           *database.CartRepository
+	abandonedAfter time.Duration
+}
+
+func NewCartReportHandler(repo *database.CartRepository, abandonedAfter time.Duration) *CartReportHandler {
+	return &CartReportHandler{repo: repo, abandonedAfter: abandonedAfter}
+}
+
+// Abandoned — брошенные корзины по моделям: GET /api/admin/carts/abandoned?hours=24
+func (h *CartReportHandler) Abandoned(w http.ResponseWriter, r *http.Request) {
+	after := h.abandonedAfter
+	if v := r.URL.Query().Get("hours"); v != "" {
+		hours, err := strconv.Atoi(v)
+		if err != nil || hours < 0 {
+			respondWithError(w, http.StatusBadRequest, "invalid hours")
+			return
+		}
+		after = time.Duration(hours) * time.Hour
+	}
+
+	stats, err := h.repo.AbandonedReport(time.Now().Add(-after))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, abandonedCartsResponse{InactiveHours: int(after / time.Hour), Models: stats})
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"path/filepath"
+	"renault-backend/database"
+	"renault-backend/models"
+	"strings"
+	"time"
+)
+
+// максимальный размер загружаемого файла каталога
+const maxCatalogUpload = 10 << 20
+
+// CatalogIOHandler — выгрузка и загрузка каталога в админке
+type CatalogIOHandler struct {
+	repo *database.CatalogIORepository
+}
+
+func NewCatalogIOHandler(repo *database.CatalogIORepository) *CatalogIOHandler {
+	return &CatalogIOHandler{repo: repo}
+}
+
+// Export — GET /api/admin/catalog/export?format=json|csv
+func (h *CatalogIOHandler) Export(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" {
+		respondWithError(w, http.StatusBadRequest, "format должен быть json или csv")
+		return
+	}
+
+	export, err := h.repo.Export()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+
+	filename := "catalog-" + time.Now().Format("20060102-150405") + "." + format
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		models.WriteCatalogCSV(w, export.Cars)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, export)
+}
+
+// Import — POST /api/admin/catalog/import?format=json|csv&dryRun=true&atomic=true
+// Файл передаётся телом запроса или полем file в multipart/form-data.
+func (h *CatalogIOHandler) Import(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	opts := models.ImportOptions{
+		DryRun: isTrue(q.Get("dryRun")),
+		Atomic: isTrue(q.Get("atomic")),
+		Author: AdminUsername(r),
+	}
+	format := q.Get("format")
+
+	var body io.Reader = http.MaxBytesReader(w, r.Body, maxCatalogUpload)
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(maxCatalogUpload); err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid multipart form")
+			return
+		}
+		file, fh, err := r.FormFile("file")
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "file is required")
+			return
+		}
+		defer file.Close()
+		body = file
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fh.Filename)), ".")
+		}
+	}
+	if format == "" {
+		format = "json"
+		if strings.Contains(r.Header.Get("Content-Type"), "csv") {
+			format = "csv"
+		}
+	}
+
+	cars, rows, parseErrs, err := models.ReadCatalog(body, format)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := h.repo.Import(cars, rows, parseErrs, opts)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error: import")
+		return
+	}
+
+	// в журнал — только итоги, а не весь файл
+	action := "catalog.import"
+	if opts.DryRun {
+		action = "catalog.import-dry-run"
+	}
+	SetAuditChange(r, action, "catalog", "", nil, map[string]any{
+		"applied": report.Applied, "created": report.Created, "updated": report.Updated,
+		"unchanged": report.Unchanged, "failed": report.Failed,
+	})
+
+	// в режиме «всё или ничего» с ошибками ничего не применено
+	status := http.StatusOK
+	if opts.Atomic && report.Failed > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	respondWithJSON(w, status, report)
+}
+
+func isTrue(v string) bool {
+	return v == "1" || strings.EqualFold(v, "true") || strings.EqualFold(v, "yes")
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+)
+
+type contextKey string
+
+const (
+	adminUsernameKey contextKey = "admin_username"
+	usernameKey      contextKey = "username"
+)
+
+// WithAdminUsername кладёт имя администратора из JWT в контекст запроса
+func WithAdminUsername(ctx context.Context, username string) context.Context {
+	return context.WithValue(ctx, adminUsernameKey, username)
+}
+
+// AdminUsername возвращает имя администратора, выполнившего запрос
+func AdminUsername(r *http.Request) string {
+	username, _ := r.Context().Value(adminUsernameKey).(string)
+	return username
+}
+
+// WithUsername кладёт имя вошедшего пользователя из JWT в контекст запроса
+func WithUsername(ctx context.Context, username string) context.Context {
+	return context.WithValue(ctx, usernameKey, username)
+}
+
+// Username возвращает имя вошедшего пользователя или пустую строку для анонимного запроса
+func Username(r *http.Request) string {
+	username, _ := r.Context().Value(usernameKey).(string)
+	return username
+}
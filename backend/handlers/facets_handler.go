@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+	"renault-backend/database"
+	"renault-backend/models"
+	"strings"
+)
+
+// FacetsHandler — счётчики для фильтров каталога
+type FacetsHandler struct {
+	repo *database.CatalogFacetsRepository
+}
+
+func NewFacetsHandler(repo *database.CatalogFacetsRepository) *FacetsHandler {
+	return &FacetsHandler{repo: repo}
+}
+
+// Facets — GET /api/cars/facets?category=Кроссоверы&price_min=1000000&drive_type=awd
+// Принимает те же фильтры, что и GET /api/cars.
+func (h *FacetsHandler) Facets(w http.ResponseWriter, r *http.Request) {
+	filter, errs := models.ParseCatalogFilter(r.URL.Query())
+	if len(errs) > 0 {
+		respondWithError(w, http.StatusBadRequest, strings.Join(errs, "; "))
+		return
+	}
+
+	facets, err := h.repo.Facets(filter)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, facets)
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"renault-backend/database"
+	"renault-backend/models"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+type FinanceHandler struct {
+	repo *database.FinanceRepository
+}
+
+func NewFinanceHandler(repo *database.FinanceRepository) *FinanceHandler {
+	return &FinanceHandler{repo: repo}
+}
+
+// Quote считает кредит/лизинг: POST /api/finance/quote
+func (h *FinanceHandler) Quote(w http.ResponseWriter, r *http.Request) {
+	var req models.FinanceQuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if req.Product == "" {
+		respondWithError(w, http.StatusBadRequest, "product is required")
+		return
+	}
+
+	product, err := h.repo.GetProductByCode(req.Product)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if product == nil || !product.Active {
+		respondWithError(w, http.StatusNotFound, "finance product not found")
+		return
+	}
+
+	// цена конфигурации имеет приоритет над base_price из каталога
+	price := req.Price
+	if price == 0 {
+		if req.CarID == "" {
+			respondWithError(w, http.StatusBadRequest, "carId or price is required")
+			return
+		}
+		price, err = h.repo.GetCarPrice(req.CarID)
+		if err == sql.ErrNoRows {
+			respondWithError(w, http.StatusNotFound, "car not found")
+			return
+		}
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "db error")
+			return
+		}
+	}
+
+	quote, err := models.CalculateQuote(price, req.DownPayment, req.TermMonths, *product)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, quote)
+}
+
+// ListProducts возвращает активные программы: GET /api/finance/products
+func (h *FinanceHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
+	products, err := h.repo.ListProducts(true)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, products)
+}
+
+// AdminListProducts возвращает все программы, включая отключённые
+func (h *FinanceHandler) AdminListProducts(w http.ResponseWriter, r *http.Request) {
+	products, err := h.repo.ListProducts(false)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, products)
+}
+
+func (h *FinanceHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
+	var p models.FinanceProduct
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if errs := models.ValidateFinanceProduct(p); len(errs) > 0 {
+		respondWithError(w, http.StatusBadRequest, strings.Join(errs, "; "))
+		return
+	}
+
+	id, err := h.repo.CreateProduct(&p)
+	if errors.Is(err, database.ErrDuplicateProductCode) {
+		respondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error: insert finance product")
+		return
+	}
+	p.ID = id
+
+	SetAuditChange(r, "finance_product.create", "finance_product", strconv.Itoa(id), nil, p)
+	respondWithJSON(w, http.StatusCreated, p)
+}
+
+func (h *FinanceHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var p models.FinanceProduct
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	p.ID = id
+	if errs := models.ValidateFinanceProduct(p); len(errs) > 0 {
+		respondWithError(w, http.StatusBadRequest, strings.Join(errs, "; "))
+		return
+	}
+
+	before, err := h.repo.GetProduct(id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if before == nil {
+		respondWithError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	found, err := h.repo.UpdateProduct(&p)
+	if errors.Is(err, database.ErrDuplicateProductCode) {
+		respondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error: update finance product")
+		return
+	}
+	if !found {
+		respondWithError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	SetAuditChange(r, "finance_product.update", "finance_product", strconv.Itoa(id), before, p)
+	respondWithJSON(w, http.StatusOK, p)
+}
+
+func (h *FinanceHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	before, err := h.repo.GetProduct(id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if before == nil {
+		respondWithError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	found, err := h.repo.DeleteProduct(id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if !found {
+		respondWithError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	SetAuditChange(r, "finance_product.delete", "finance_product", strconv.Itoa(id), before, nil)
+	respondWithJSON(w, http.StatusOK, StatusResponse{Status: "deleted"})
+}
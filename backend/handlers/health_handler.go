@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"renault-backend/logging"
+	"time"
+)
+
+// сколько ждать ответа БД при проверке готовности
+const readinessTimeout = 2 * time.Second
+
+// BuildInfo — версия и коммит, подставляемые при сборке (-ldflags "-X main.version=… -X main.commit=…")
+type BuildInfo struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit"`
+}
+
+// HealthHandler — проверки живости и готовности для балансировщика и оркестратора
+type HealthHandler struct {
+	build   BuildInfo
+	started time.Time
+	dbs     map[string]*sql.DB // имя в ответе → БД, которую нужно пинговать
+}
+
+func NewHealthHandler(build BuildInfo, dbs map[string]*sql.DB) *HealthHandler {
+	return &HealthHandler{build: build, started: time.Now(), dbs: dbs}
+}
+
+// Livez — GET /livez: процесс жив и обслуживает запросы; БД не проверяются,
+// чтобы недоступная база не приводила к перезапуску сервера
+func (h *HealthHandler) Livez(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, livenessResponse{
+		Status:  "ok",
+		Version: h.build.Version,
+		Commit:  h.build.Commit,
+		Uptime:  time.Since(h.started).Round(time.Second).String(),
+	})
+}
+
+// Readyz — GET /readyz: обе БД отвечают; иначе 503 и unavailable у недоступной базы
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	code, body := h.readiness(r.Context())
+	respondWithJSON(w, code, body)
+}
+
+// Health — GET /api/health: проверка готовности в прежнем формате ответа
+func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
+	code, body := h.readiness(r.Context())
+	body.Service = "Renault Backend API"
+	body.Time = time.Now().Format(time.RFC3339)
+	respondWithJSON(w, code, body)
+}
+
+func (h *HealthHandler) readiness(ctx context.Context) (int, readinessResponse) {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	code, status := http.StatusOK, "ok"
+	checks := map[string]string{}
+	for name, db := range h.dbs {
+		if err := pingDB(ctx, db); err != nil {
+			// текст ошибки SQLite раскрывает пути и схему — наружу только статус, подробности в журнал
+			logging.FromContext(ctx).Error("readiness check failed", "db", name, "err", err)
+			checks[name] = "unavailable"
+			code, status = http.StatusServiceUnavailable, "unavailable"
+			continue
+		}
+		checks[name] = "ok"
+	}
+	return code, readinessResponse{
+		Status:  status,
+		Version: h.build.Version,
+		Commit:  h.build.Commit,
+		Checks:  checks,
+	}
+}
+
+// pingDB проверяет соединение и чтение схемы: у SQLite сам Ping почти всегда успешен,
+// а запрос к sqlite_master заметит недоступный или повреждённый файл
+func pingDB(ctx context.Context, db *sql.DB) error {
+	if err := db.PingContext(ctx); err != nil {
+		return err
+	}
+	var n int
+	return db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master`).Scan(&n)
+}
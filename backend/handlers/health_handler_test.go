@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReadyzHidesDBErrors(t *testing.T) {
+	up, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer up.Close()
+	down, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	down.Close()
+
+	h := NewHealthHandler(BuildInfo{}, map[string]*sql.DB{"catalog": up, "main": down})
+	rec := httptest.NewRecorder()
+	h.Readyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "closed") {
+		t.Errorf("response leaks the DB error: %s", rec.Body)
+	}
+	var body readinessResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"catalog": "ok", "main": "unavailable"}
+	for name, status := range want {
+		if body.Checks[name] != status {
+			t.Errorf("checks[%s] = %q, want %q", name, body.Checks[name], status)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"renault-backend/database"
+	"renault-backend/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type LeadHandler struct {
+	repo      *database.LeadRepository
+	adminRepo *database.AdminRepository
+	slaWindow time.Duration
+	events    EventRecorder
+	spam      *SpamGuard
+}
+
+// NewLeadHandler — slaWindow: сколько лид может пролежать без внимания менеджера
+func NewLeadHandler(repo *database.LeadRepository, adminRepo *database.AdminRepository, slaWindow time.Duration,
+	events EventRecorder, spam *SpamGuard) *LeadHandler {
+	return &LeadHandler{repo: repo, adminRepo: adminRepo, slaWindow: slaWindow, events: events, spam: spam}
+}
+
+func (h *LeadHandler) slaCutoff() time.Time {
+	return time.Now().UTC().Add(-h.slaWindow)
+}
+
+// Submit принимает заявку с сайта: POST /api/leads. Подозрительные заявки
+// уходят в очередь модерации (202) и попадают в воронку только после одобрения.
+func (h *LeadHandler) Submit(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		models.Lead
+		SpamFields
+	}
+	if !h.spam.DecodeForm(w, r, &req) {
+		return
+	}
+	l := req.Lead
+
+	l.Name = strings.TrimSpace(l.Name)
+	l.Phone = strings.TrimSpace(l.Phone)
+	l.Email = strings.TrimSpace(l.Email)
+	if errs := models.ValidateLead(l); len(errs) > 0 {
+		respondWithError(w, http.StatusBadRequest, strings.Join(errs, "; "))
+		return
+	}
+
+	published, err := h.repo.CarPublished(l.CarID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error: check car")
+		return
+	}
+	if !published {
+		respondWithError(w, http.StatusBadRequest, "carId: автомобиль не найден в каталоге")
+		return
+	}
+
+	reasons, err := h.spam.Screen(r, models.ModerationKindLead, req.SpamFields, l.Message, l.Name)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error: spam check")
+		return
+	}
+	if len(reasons) > 0 {
+		h.spam.Hold(w, r, models.ModerationKindLead, l, reasons)
+		return
+	}
+
+	if err := h.repo.Create(&l); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error: insert lead")
+		return
+	}
+	h.events.Record(models.AnalyticsEvent{Type: models.EventLeadCreated, CarID: l.CarID, UserID: getUserID(r)})
+
+	respondWithJSON(w, http.StatusCreated, CreatedResponse{Status: "ok", ID: l.ID})
+}
+
+func leadFilterFromQuery(r *http.Request) models.LeadFilter {
+	q := r.URL.Query()
+	return models.LeadFilter{
+		Status:     q.Get("status"),
+		AssignedTo: q.Get("assignedTo"),
+		CarID:      q.Get("carId"),
+		Overdue:    q.Get("overdue") == "true",
+	}
+}
+
+// List — воронка лидов: GET /api/admin/leads?status=&assignedTo=&carId=&overdue=true
+func (h *LeadHandler) List(w http.ResponseWriter, r *http.Request) {
+	leads, err := h.repo.List(leadFilterFromQuery(r), h.slaCutoff())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, leads)
+}
+
+// Get возвращает лид вместе с историей: GET /api/admin/leads/{id}
+func (h *LeadHandler) Get(w http.ResponseWriter, r *http.Request) {
+	lead := h.load(w, r)
+	if lead == nil {
+		return
+	}
+
+	activities, err := h.repo.Activities(lead.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, leadDetailsResponse{Lead: lead, Activities: activities})
+}
+
+func (h *LeadHandler) load(w http.ResponseWriter, r *http.Request) *models.Lead {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid id")
+		return nil
+	}
+
+	lead, err := h.repo.GetByID(id, h.slaCutoff())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return nil
+	}
+	if lead == nil {
+		respondWithError(w, http.StatusNotFound, "not found")
+		return nil
+	}
+	return lead
+}
+
+// UpdateStatus двигает лид по воронке: PUT /api/admin/leads/{id}/status
+func (h *LeadHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	lead := h.load(w, r)
+	if lead == nil {
+		return
+	}
+
+	var req struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if !models.CanTransitionLead(lead.Status, req.Status) {
+		respondWithError(w, http.StatusConflict, fmt.Sprintf("нельзя перевести лид из %s в %s", lead.Status, req.Status))
+		return
+	}
+
+	ok, err := h.repo.UpdateStatus(lead.ID, lead.Status, req.Status, AdminUsername(r))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if !ok {
+		respondWithError(w, http.StatusConflict, "лид изменён другим пользователем")
+		return
+	}
+
+	h.respondLead(w, r, "lead.status", lead)
+}
+
+// Assign назначает ответственного менеджера: PUT /api/admin/leads/{id}/assign
+func (h *LeadHandler) Assign(w http.ResponseWriter, r *http.Request) {
+	lead := h.load(w, r)
+	if lead == nil {
+		return
+	}
+
+	var req struct {
+		Manager string `json:"manager"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	isManager, err := h.adminRepo.IsAdmin(req.Manager)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if !isManager {
+		respondWithError(w, http.StatusBadRequest, "менеджер не найден")
+		return
+	}
+
+	if _, err := h.repo.Assign(lead.ID, req.Manager, AdminUsername(r)); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+
+	h.respondLead(w, r, "lead.assign", lead)
+}
+
+// AddNote добавляет заметку: POST /api/admin/leads/{id}/notes
+func (h *LeadHandler) AddNote(w http.ResponseWriter, r *http.Request) {
+	lead := h.load(w, r)
+	if lead == nil {
+		return
+	}
+
+	var req struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	req.Text = strings.TrimSpace(req.Text)
+	if req.Text == "" {
+		respondWithError(w, http.StatusBadRequest, "text is required")
+		return
+	}
+
+	if err := h.repo.AddNote(lead.ID, AdminUsername(r), req.Text); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+
+	h.respondLead(w, r, "lead.note", lead)
+}
+
+// respondLead отдаёт лид после изменения и пишет в журнал его состояние до и после
+func (h *LeadHandler) respondLead(w http.ResponseWriter, r *http.Request, action string, before *models.Lead) {
+	lead, err := h.repo.GetByID(before.ID, h.slaCutoff())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	SetAuditChange(r, action, "lead", strconv.Itoa(before.ID), before, lead)
+	respondWithJSON(w, http.StatusOK, lead)
+}
+
+// ExportCSV выгружает лиды для отдела продаж: GET /api/admin/leads/export (те же фильтры, что у List)
+func (h *LeadHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
+	leads, err := h.repo.List(leadFilterFromQuery(r), h.slaCutoff())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+
+	filename := fmt.Sprintf("leads-%s.csv", time.Now().Format("2006-01-02"))
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	// BOM, чтобы Excel корректно открыл кириллицу
+	w.Write([]byte("\xEF\xBB\xBF"))
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"id", "type", "car_id", "name", "phone", "email", "message",
+		"status", "assigned_to", "created_at", "last_touched_at", "sla_breached"})
+	for _, l := range leads {
+		cw.Write([]string{
+			strconv.Itoa(l.ID), l.Type, csvCell(l.CarID), csvCell(l.Name), csvCell(l.Phone), csvCell(l.Email),
+			csvCell(l.Message), l.Status, csvCell(l.AssignedTo),
+			l.CreatedAt.Format(time.RFC3339), l.LastTouchedAt.Format(time.RFC3339),
+			strconv.FormatBool(l.SLABreached),
+		})
+	}
+	cw.Flush()
+}
+
+// csvCell экранирует значения, которые Excel и LibreOffice выполнили бы как формулу:
+// такие ячейки начинаются с апострофа и остаются текстом
+func csvCell(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"renault-backend/database"
+	"renault-backend/logging"
+	"renault-backend/models"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// ModerationHandler — разбор очереди подозрительных отзывов и заявок администратором
+type ModerationHandler struct {
+	repo    *database.ModerationRepository
+	reviews *database.ReviewRepository
+	leads   *database.LeadRepository
+	events  EventRecorder
+}
+
+func NewModerationHandler(repo *database.ModerationRepository, reviews *database.ReviewRepository,
+	leads *database.LeadRepository, events EventRecorder) *ModerationHandler {
+	return &ModerationHandler{repo: repo, reviews: reviews, leads: leads, events: events}
+}
+
+// List — GET /api/admin/moderation?status=pending&kind=review|lead&limit=
+func (h *ModerationHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	status := q.Get("status")
+	if status == "" {
+		status = models.ModerationPending
+	}
+	if status == "all" {
+		status = ""
+	}
+	kind := q.Get("kind")
+	if kind != "" && !models.IsModerationKind(kind) {
+		respondWithError(w, http.StatusBadRequest, "kind должен быть review или lead")
+		return
+	}
+	limit := 100
+	if v := q.Get("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 || limit > 1000 {
+			respondWithError(w, http.StatusBadRequest, "limit должен быть от 1 до 1000")
+			return
+		}
+	}
+
+	items, err := h.repo.List(status, kind, limit)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, items)
+}
+
+// Approve публикует отзыв или создаёт лид из записи очереди: POST /api/admin/moderation/{id}/approve
+func (h *ModerationHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	item := h.claim(w, r, models.ModerationApproved)
+	if item == nil {
+		return
+	}
+
+	resultID, err := h.publish(r, item)
+	if err != nil {
+		// отправка осталась неопубликованной — возвращаем её в очередь
+		if rerr := h.repo.Reopen(item.ID); rerr != nil {
+			logging.FromContext(r.Context()).Error("moderation: reopen", "moderation_id", item.ID, "err", rerr)
+		}
+		respondWithError(w, http.StatusInternalServerError, "db error: publish "+item.Kind)
+		return
+	}
+	if err := h.repo.SetResult(item.ID, resultID); err != nil {
+		logging.FromContext(r.Context()).Error("moderation: set result", "moderation_id", item.ID, "err", err)
+	}
+
+	SetAuditChange(r, "moderation.approve", "moderation", strconv.Itoa(item.ID), nil, item)
+	h.respondItem(w, item.ID)
+}
+
+// Reject отклоняет запись очереди; она остаётся в истории: POST /api/admin/moderation/{id}/reject
+func (h *ModerationHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	item := h.claim(w, r, models.ModerationRejected)
+	if item == nil {
+		return
+	}
+	SetAuditChange(r, "moderation.reject", "moderation", strconv.Itoa(item.ID), nil, item)
+	h.respondItem(w, item.ID)
+}
+
+// claim переводит запись из pending в status; nil — ответ с ошибкой уже отправлен
+func (h *ModerationHandler) claim(w http.ResponseWriter, r *http.Request, status string) *models.ModerationItem {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid id")
+		return nil
+	}
+	item, err := h.repo.GetByID(id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return nil
+	}
+	if item == nil {
+		respondWithError(w, http.StatusNotFound, "not found")
+		return nil
+	}
+
+	ok, err := h.repo.Decide(id, status, AdminUsername(r))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return nil
+	}
+	if !ok {
+		respondWithError(w, http.StatusConflict, "запись уже разобрана")
+		return nil
+	}
+	return item
+}
+
+// publish сохраняет отправку так же, как её сохранил бы публичный хендлер
+func (h *ModerationHandler) publish(r *http.Request, item *models.ModerationItem) (int, error) {
+	switch item.Kind {
+	case models.ModerationKindReview:
+		var rev models.Review
+		if err := json.Unmarshal(item.Payload, &rev); err != nil {
+			return 0, err
+		}
+		if err := h.reviews.Create(&rev); err != nil {
+			return 0, err
+		}
+		h.events.Record(models.AnalyticsEvent{Type: models.EventReviewSubmitted, Rating: rev.Rating})
+		return rev.ID, nil
+	case models.ModerationKindLead:
+		var l models.Lead
+		if err := json.Unmarshal(item.Payload, &l); err != nil {
+			return 0, err
+		}
+		if err := h.leads.Create(&l); err != nil {
+			return 0, err
+		}
+		h.events.Record(models.AnalyticsEvent{Type: models.EventLeadCreated, CarID: l.CarID})
+		return l.ID, nil
+	}
+	return 0, fmt.Errorf("unknown moderation kind %q", item.Kind)
+}
+
+func (h *ModerationHandler) respondItem(w http.ResponseWriter, id int) {
+	item, err := h.repo.GetByID(id)
+	if err != nil || item == nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, item)
+}
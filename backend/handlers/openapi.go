@@ -0,0 +1,409 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"renault-backend/apidoc"
+	"renault-backend/database"
+	"renault-backend/models"
+)
+
+// Тела ответов без своей модели. Хендлеры кодируют именно эти типы, по ним же строится
+// спецификация, так что описание не расходится с ответом.
+
+// StatusResponse — итог действия без тела: {"status": "deleted"} и т.п.
+type StatusResponse struct {
+	Status string `json:"status"`
+}
+
+// CreatedResponse — запись создана
+type CreatedResponse struct {
+	Status string `json:"status"` // всегда "ok"
+	ID     int    `json:"id"`
+}
+
+// HeldResponse — запись отложена до решения модератора
+type HeldResponse struct {
+	Status string `json:"status"` // всегда "moderation"
+	ID     int    `json:"id"`     // номер в очереди модерации
+}
+
+type passwordCheckResponse struct {
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors,omitempty"`
+	Score  int      `json:"score"`
+}
+
+type passwordRulesResponse struct {
+	MinLength      int      `json:"min_length"`
+	RequireUpper   bool     `json:"require_upper"`
+	RequireLower   bool     `json:"require_lower"`
+	RequireNumber  bool     `json:"require_number"`
+	RequireSpecial bool     `json:"require_special"`
+	Rules          []string `json:"rules"`
+}
+
+type tradeInCreditResponse struct {
+	Credit int `json:"credit"`
+}
+
+type suggestResponse struct {
+	Query       string   `json:"query"`
+	Suggestions []string `json:"suggestions"`
+}
+
+type priceHistoryResponse struct {
+	CarID        string               `json:"carId"`
+	CurrentPrice int                  `json:"currentPrice"`
+	History      []models.PriceChange `json:"history"`
+}
+
+type leadDetailsResponse struct {
+	Lead       *models.Lead          `json:"lead"`
+	Activities []models.LeadActivity `json:"activities"`
+}
+
+type formTokenResponse struct {
+	Token string `json:"token"`
+}
+
+type ingestResponse struct {
+	Accepted int `json:"accepted"`
+	Dropped  int `json:"dropped"`
+}
+
+type refreshResponse struct {
+	Events int `json:"events"`
+}
+
+type restoredResponse struct {
+	Status string `json:"status"` // всегда "restored"
+	ID     string `json:"id"`
+}
+
+type previewLinkResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type abandonedCartsResponse struct {
+	InactiveHours int                           `json:"inactiveHours"`
+	Models        []database.AbandonedModelStat `json:"models"`
+}
+
+type livenessResponse struct {
+	Status  string `json:"status"`
+	Version string `json:"version"`
+	Commit  string `json:"commit"`
+	Uptime  string `json:"uptime"`
+}
+
+type readinessResponse struct {
+	Status  string            `json:"status"` // ok или unavailable (503)
+	Version string            `json:"version"`
+	Commit  string            `json:"commit"`
+	Checks  map[string]string `json:"checks"` // имя БД → ok или unavailable
+	// только у /api/health
+	Service string `json:"service,omitempty"`
+	Time    string `json:"time,omitempty"`
+}
+
+// параметры, общие для нескольких маршрутов
+var (
+	analyticsRangeParams = []apidoc.Param{
+		apidoc.Q("from", "string", "начало периода, YYYY-MM-DD (по умолчанию 30 дней назад)"),
+		apidoc.Q("to", "string", "конец периода включительно, YYYY-MM-DD (по умолчанию сегодня)"),
+	}
+	leadFilterParams = []apidoc.Param{
+		apidoc.Q("status", "string", "статус лида"),
+		apidoc.Q("assignedTo", "string", "менеджер"),
+		apidoc.Q("carId", "string", "автомобиль"),
+		apidoc.Q("overdue", "boolean", "только просроченные по SLA"),
+	}
+	// CatalogFilterParams — фильтры публичного каталога (GET /api/cars, /api/cars/facets)
+	CatalogFilterParams = []apidoc.Param{
+		apidoc.Q("category", "string", "категории через запятую"),
+		apidoc.Q("price_min", "integer", "минимальная цена"),
+		apidoc.Q("price_max", "integer", "максимальная цена"),
+	}
+)
+
+// CatalogFilterDescription — как задаются фильтры по характеристикам (см. models.ParseSpecFilters)
+const CatalogFilterDescription = "Фильтры по характеристикам из GET /api/cars/spec-schema: " +
+	"<key>_min и <key>_max для чисел, <key>=a,b для перечислений."
+
+// searchUnavailable — поиск без FTS5 (сервер собран без -tags sqlite_fts5)
+var searchUnavailable = apidoc.Response{Status: http.StatusServiceUnavailable,
+	Description: "Поисковый индекс недоступен: SQLite собран без FTS5", Body: apidoc.Error{}}
+
+// APIOperations — описание маршрутов, которые обслуживает пакет handlers.
+// Маршруты из main описаны там же; вместе их сверяет apidoc.Check.
+func APIOperations() []apidoc.Operation {
+	cart := func(method, path, summary string, req any) apidoc.Operation {
+		return apidoc.Operation{
+			Method: method, Path: path, Tag: "Корзина", Summary: summary, User: true,
+			Request: req, Responses: apidoc.OK(cartResponse{}), Error: apidoc.TextError{},
+		}
+	}
+	ops := []apidoc.Operation{
+		// ----- служебные -----
+		{Method: http.MethodGet, Path: "/livez", Tag: "Служебные", Summary: "Процесс жив",
+			Responses: apidoc.OK(livenessResponse{})},
+		{Method: http.MethodGet, Path: "/readyz", Tag: "Служебные", Summary: "Готовность: обе БД отвечают",
+			Description: "503, если какая-то БД недоступна; причина пишется в журнал сервера, а не в ответ.",
+			Responses: []apidoc.Response{
+				{Status: http.StatusOK, Body: readinessResponse{}},
+				{Status: http.StatusServiceUnavailable, Body: readinessResponse{}},
+			}},
+		{Method: http.MethodGet, Path: "/api/health", Tag: "Служебные", Summary: "Готовность в прежнем формате",
+			Responses: []apidoc.Response{
+				{Status: http.StatusOK, Body: readinessResponse{}},
+				{Status: http.StatusServiceUnavailable, Body: readinessResponse{}},
+			}},
+
+		// ----- авторизация -----
+		{Method: http.MethodPost, Path: "/api/register", Tag: "Авторизация", Summary: "Регистрация",
+			Description: "Ограничено по IP (RATE_LIMIT_REGISTER).",
+			Request:     models.RegisterRequest{},
+			Responses:   []apidoc.Response{{Status: http.StatusCreated, Body: models.AuthResponse{}}},
+			Error:       models.ErrorResponse{}},
+		{Method: http.MethodPost, Path: "/api/login", Tag: "Авторизация", Summary: "Вход, выдаёт JWT",
+			Description: "Ограничено по IP (RATE_LIMIT_LOGIN).",
+			Request:     models.LoginRequest{}, Responses: apidoc.OK(models.AuthResponse{}),
+			Error: models.ErrorResponse{}},
+		{Method: http.MethodPost, Path: "/api/validate-password", Tag: "Авторизация", Summary: "Проверка сложности пароля",
+			Request: struct {
+				Password string `json:"password"`
+			}{}, Responses: apidoc.OK(passwordCheckResponse{}), Error: models.ErrorResponse{}},
+		{Method: http.MethodGet, Path: "/api/password-rules", Tag: "Авторизация", Summary: "Правила для пароля",
+			Responses: apidoc.OK(passwordRulesResponse{})},
+		{Method: http.MethodGet, Path: "/api/users", Tag: "Авторизация", Summary: "Список пользователей",
+			Responses: apidoc.OK([]models.User{}), Error: models.ErrorResponse{}},
+
+		// ----- каталог -----
+		{Method: http.MethodGet, Path: "/api/cars/facets", Tag: "Каталог", Summary: "Фасеты фильтров каталога",
+			Description: "Принимает те же фильтры, что и GET /api/cars. " + CatalogFilterDescription,
+			Query:       CatalogFilterParams, Responses: apidoc.OK(models.CatalogFacets{})},
+		{Method: http.MethodGet, Path: "/api/cars/{id}/similar", Tag: "Каталог", Summary: "Похожие автомобили и «покупатели также смотрели»",
+			Query:     []apidoc.Param{apidoc.Q("limit", "integer", "сколько автомобилей в каждом списке")},
+			Responses: apidoc.OK(models.CarRecommendations{})},
+		{Method: http.MethodGet, Path: "/api/cars/{id}/price-history", Tag: "Каталог", Summary: "История цен",
+			Responses: apidoc.OK(priceHistoryResponse{})},
+		{Method: http.MethodGet, Path: "/api/search", Tag: "Каталог", Summary: "Полнотекстовый поиск",
+			Query: []apidoc.Param{
+				{Name: "q", Type: "string", Description: "строка поиска", Required: true},
+				apidoc.Q("limit", "integer", "сколько результатов"),
+			},
+			Responses: []apidoc.Response{
+				{Status: http.StatusOK, Body: models.SearchResponse{}},
+				searchUnavailable,
+			}},
+		{Method: http.MethodGet, Path: "/api/search/suggest", Tag: "Каталог", Summary: "Подсказки с учётом опечаток",
+			Query: []apidoc.Param{apidoc.Q("q", "string", "строка поиска")},
+			Responses: []apidoc.Response{
+				{Status: http.StatusOK, Body: suggestResponse{}},
+				searchUnavailable,
+			}},
+
+		// ----- финансирование -----
+		{Method: http.MethodGet, Path: "/api/finance/products", Tag: "Финансирование", Summary: "Активные кредитные и лизинговые продукты",
+			Responses: apidoc.OK([]models.FinanceProduct{})},
+		{Method: http.MethodPost, Path: "/api/finance/quote", Tag: "Финансирование", Summary: "Расчёт платежа",
+			Request: models.FinanceQuoteRequest{}, Responses: apidoc.OK(models.FinanceQuote{})},
+		{Method: http.MethodGet, Path: "/api/admin/finance/products", Tag: "Финансирование", Admin: true, Summary: "Все продукты",
+			Responses: apidoc.OK([]models.FinanceProduct{})},
+		{Method: http.MethodPost, Path: "/api/admin/finance/products", Tag: "Финансирование", Admin: true, Summary: "Создать продукт",
+			Description: "409, если продукт с таким code уже есть.",
+			Request:     models.FinanceProduct{}, Responses: []apidoc.Response{{Status: http.StatusCreated, Body: models.FinanceProduct{}}}},
+		{Method: http.MethodPut, Path: "/api/admin/finance/products/{id}", Tag: "Финансирование", Admin: true, Summary: "Изменить продукт",
+			Description: "409, если продукт с таким code уже есть.",
+			Request:     models.FinanceProduct{}, Responses: apidoc.OK(models.FinanceProduct{})},
+		{Method: http.MethodDelete, Path: "/api/admin/finance/products/{id}", Tag: "Финансирование", Admin: true, Summary: "Удалить продукт",
+			Responses: apidoc.OK(StatusResponse{})},
+
+		// ----- trade-in: заявки принадлежат аккаунту, чужие — 404 -----
+		{Method: http.MethodPost, Path: "/api/trade-in", Tag: "Trade-in", Login: true, Summary: "Заявка на оценку автомобиля",
+			Description: "Вместо JSON можно отправить multipart/form-data с теми же полями и фотографиями в поле photos " +
+				"(до 10 файлов по 10 МБ; 413, если запрос больше).",
+			Request:   models.TradeInRequest{},
+			Responses: []apidoc.Response{{Status: http.StatusCreated, Body: models.TradeInRequest{}}}},
+		{Method: http.MethodGet, Path: "/api/trade-in", Tag: "Trade-in", Login: true, Summary: "Мои заявки",
+			Responses: apidoc.OK([]models.TradeInRequest{})},
+		{Method: http.MethodGet, Path: "/api/trade-in/credit", Tag: "Trade-in", Login: true, User: true, Summary: "Сумма зачтённых trade-in, ещё не использованных в заказе",
+			Responses: apidoc.OK(tradeInCreditResponse{})},
+		{Method: http.MethodGet, Path: "/api/trade-in/{id:[0-9]+}", Tag: "Trade-in", Login: true, Summary: "Моя заявка",
+			Responses: apidoc.OK(models.TradeInRequest{})},
+		{Method: http.MethodGet, Path: "/api/trade-in/{id:[0-9]+}/photos/{name}", Tag: "Trade-in", Login: true, Summary: "Фотография своей заявки",
+			Responses: []apidoc.Response{{Status: http.StatusOK, Body: "", ContentType: "image/*"}}},
+		{Method: http.MethodPost, Path: "/api/trade-in/{id:[0-9]+}/accept", Tag: "Trade-in", Login: true, Summary: "Принять предложение",
+			Description: "409, если заявка не в статусе offered.", Responses: apidoc.OK(models.TradeInRequest{})},
+		{Method: http.MethodPost, Path: "/api/trade-in/{id:[0-9]+}/reject", Tag: "Trade-in", Login: true, Summary: "Отказаться от предложения",
+			Description: "409, если заявка не в статусе offered.", Responses: apidoc.OK(models.TradeInRequest{})},
+		{Method: http.MethodPost, Path: "/api/trade-in/{id:[0-9]+}/apply", Tag: "Trade-in", Login: true, User: true, Summary: "Зачесть в счёт покупки",
+			Description: "Зачёт уходит в корзину X-User-Id и списывается её следующим заказом. 409, если предложение ещё не принято.", Responses: apidoc.OK(models.TradeInRequest{})},
+		{Method: http.MethodGet, Path: "/api/admin/trade-in", Tag: "Trade-in", Admin: true, Summary: "Все заявки",
+			Query:     []apidoc.Param{apidoc.Q("status", "string", "статус заявки")},
+			Responses: apidoc.OK([]models.TradeInRequest{})},
+		{Method: http.MethodGet, Path: "/api/admin/trade-in/{id:[0-9]+}/photos/{name}", Tag: "Trade-in", Admin: true, Summary: "Фотография заявки",
+			Responses: []apidoc.Response{{Status: http.StatusOK, Body: "", ContentType: "image/*"}}},
+		{Method: http.MethodPut, Path: "/api/admin/trade-in/{id:[0-9]+}/offer", Tag: "Trade-in", Admin: true, Summary: "Финальное предложение",
+			Request: tradeInOfferRequest{}, Responses: apidoc.OK(models.TradeInRequest{})},
+		{Method: http.MethodGet, Path: "/api/admin/trade-in/depreciation", Tag: "Trade-in", Admin: true, Summary: "Правила амортизации",
+			Responses: apidoc.OK([]models.DepreciationRule{})},
+		{Method: http.MethodPut, Path: "/api/admin/trade-in/depreciation", Tag: "Trade-in", Admin: true, Summary: "Сохранить правило амортизации",
+			Request: models.DepreciationRule{}, Responses: apidoc.OK(StatusResponse{})},
+		{Method: http.MethodDelete, Path: "/api/admin/trade-in/depreciation/{id:[0-9]+}", Tag: "Trade-in", Admin: true, Summary: "Удалить правило амортизации",
+			Responses: apidoc.OK(StatusResponse{})},
+
+		// ----- лиды -----
+		{Method: http.MethodPost, Path: "/api/leads", Tag: "Лиды", Summary: "Заявка с сайта",
+			Description: "carId — опубликованный автомобиль, иначе 400. Подозрительные заявки уходят в очередь модерации (202). " +
+				"formToken берётся из GET /api/form-token, website — ловушка для ботов и должно быть пустым.",
+			Request: struct {
+				models.Lead
+				SpamFields
+			}{},
+			Responses: []apidoc.Response{
+				{Status: http.StatusCreated, Body: CreatedResponse{}},
+				{Status: http.StatusAccepted, Description: "Отложена до решения модератора", Body: HeldResponse{}},
+			}},
+		{Method: http.MethodGet, Path: "/api/admin/leads", Tag: "Лиды", Admin: true, Summary: "Воронка лидов",
+			Query: leadFilterParams, Responses: apidoc.OK([]models.Lead{})},
+		{Method: http.MethodGet, Path: "/api/admin/leads/export", Tag: "Лиды", Admin: true, Summary: "Выгрузка лидов в CSV",
+			Query:     leadFilterParams,
+			Responses: []apidoc.Response{{Status: http.StatusOK, Body: "", ContentType: "text/csv"}}},
+		{Method: http.MethodGet, Path: "/api/admin/leads/{id:[0-9]+}", Tag: "Лиды", Admin: true, Summary: "Лид с историей",
+			Responses: apidoc.OK(leadDetailsResponse{})},
+		{Method: http.MethodPut, Path: "/api/admin/leads/{id:[0-9]+}/status", Tag: "Лиды", Admin: true, Summary: "Сменить статус",
+			Description: "409 при недопустимом переходе.",
+			Request: struct {
+				Status string `json:"status"`
+			}{}, Responses: apidoc.OK(models.Lead{})},
+		{Method: http.MethodPut, Path: "/api/admin/leads/{id:[0-9]+}/assign", Tag: "Лиды", Admin: true, Summary: "Назначить менеджера",
+			Request: struct {
+				Manager string `json:"manager"`
+			}{}, Responses: apidoc.OK(models.Lead{})},
+		{Method: http.MethodPost, Path: "/api/admin/leads/{id:[0-9]+}/notes", Tag: "Лиды", Admin: true, Summary: "Добавить заметку",
+			Request: struct {
+				Text string `json:"text"`
+			}{}, Responses: apidoc.OK(models.Lead{})},
+
+		// ----- антиспам и модерация -----
+		{Method: http.MethodGet, Path: "/api/form-token", Tag: "Модерация", Summary: "Токен формы отзыва или заявки",
+			Responses: apidoc.OK(formTokenResponse{})},
+		{Method: http.MethodGet, Path: "/api/admin/moderation", Tag: "Модерация", Admin: true, Summary: "Очередь модерации",
+			Query: []apidoc.Param{
+				apidoc.Q("status", "string", "pending (по умолчанию), approved, rejected или all"),
+				apidoc.Q("kind", "string", "review или lead"),
+				apidoc.Q("limit", "integer", "сколько записей"),
+			},
+			Responses: apidoc.OK([]models.ModerationItem{})},
+		{Method: http.MethodPost, Path: "/api/admin/moderation/{id:[0-9]+}/approve", Tag: "Модерация", Admin: true, Summary: "Опубликовать отзыв или создать лид",
+			Description: "409, если решение по записи уже принято.", Responses: apidoc.OK(models.ModerationItem{})},
+		{Method: http.MethodPost, Path: "/api/admin/moderation/{id:[0-9]+}/reject", Tag: "Модерация", Admin: true, Summary: "Отклонить",
+			Description: "409, если решение по записи уже принято.", Responses: apidoc.OK(models.ModerationItem{})},
+
+		// ----- аналитика -----
+		{Method: http.MethodPost, Path: "/api/events", Tag: "Аналитика", Summary: "События от клиентов без запросов к API",
+			Description: "Принимаются только события car_viewed опубликованных автомобилей (иначе 400), не больше 50 за запрос. Ограничено по IP (RATE_LIMIT_EVENTS).",
+			Request:     ingestRequest{},
+			Responses:   []apidoc.Response{{Status: http.StatusAccepted, Body: ingestResponse{}}}},
+		{Method: http.MethodGet, Path: "/api/admin/analytics/views", Tag: "Аналитика", Admin: true, Summary: "Просмотры по дням",
+			Query:     append([]apidoc.Param{apidoc.Q("carId", "string", "один автомобиль")}, analyticsRangeParams...),
+			Responses: apidoc.OK([]models.CarViewsDay{})},
+		{Method: http.MethodGet, Path: "/api/admin/analytics/funnel", Tag: "Аналитика", Admin: true, Summary: "Воронка конверсии",
+			Query: analyticsRangeParams, Responses: apidoc.OK(models.ConversionFunnel{})},
+		{Method: http.MethodGet, Path: "/api/admin/analytics/categories", Tag: "Аналитика", Admin: true, Summary: "Популярные категории",
+			Query:     append([]apidoc.Param{apidoc.Q("limit", "integer", "сколько категорий")}, analyticsRangeParams...),
+			Responses: apidoc.OK([]models.CategoryStats{})},
+		{Method: http.MethodGet, Path: "/api/admin/analytics/ratings", Tag: "Аналитика", Admin: true, Summary: "Оценки отзывов по дням",
+			Query: analyticsRangeParams, Responses: apidoc.OK([]models.RatingsDay{})},
+		{Method: http.MethodPost, Path: "/api/admin/analytics/refresh", Tag: "Аналитика", Admin: true, Summary: "Пересчитать свёртки",
+			Responses: apidoc.OK(refreshResponse{})},
+
+		// ----- акции -----
+		{Method: http.MethodGet, Path: "/api/admin/promotions", Tag: "Акции", Admin: true, Summary: "Все акции",
+			Responses: apidoc.OK([]models.Promotion{})},
+		{Method: http.MethodPost, Path: "/api/admin/promotions", Tag: "Акции", Admin: true, Summary: "Создать акцию",
+			Request: models.Promotion{}, Responses: []apidoc.Response{{Status: http.StatusCreated, Body: models.Promotion{}}}},
+		{Method: http.MethodPut, Path: "/api/admin/promotions/{id:[0-9]+}", Tag: "Акции", Admin: true, Summary: "Изменить акцию",
+			Request: models.Promotion{}, Responses: apidoc.OK(models.Promotion{})},
+		{Method: http.MethodDelete, Path: "/api/admin/promotions/{id:[0-9]+}", Tag: "Акции", Admin: true, Summary: "Удалить акцию",
+			Responses: apidoc.OK(StatusResponse{})},
+
+		// ----- корзина -----
+		cart(http.MethodGet, "/api/cart", "Корзина с ценами после акций", nil),
+		cart(http.MethodPost, "/api/cart", "Добавить автомобиль", addToCartRequest{}),
+		cart(http.MethodDelete, "/api/cart", "Очистить корзину", nil),
+		cart(http.MethodPatch, "/api/cart/{id}", "Изменить количество", updateQuantityRequest{}),
+		cart(http.MethodDelete, "/api/cart/{id}", "Удалить позицию", nil),
+		{Method: http.MethodPost, Path: "/api/cart/checkout", Tag: "Корзина", User: true, Summary: "Оформить заказ",
+			Description: "Фиксирует суммы, очищает корзину и списывает зачтённые trade-in (заказ забирает их целиком). " +
+				"Промокоды, давшие скидку, засчитываются в лимит, остальные снимаются. " +
+				"400 — корзина пуста, 409 — trade-in уже ушёл в другой заказ или лимит промокода выбран.",
+			Responses: []apidoc.Response{{Status: http.StatusCreated, Body: models.Order{}}}, Error: apidoc.TextError{}},
+		{Method: http.MethodPost, Path: "/api/cart/promo", Tag: "Корзина", User: true, Summary: "Применить промокод",
+			Description: "Лимит промокода расходуется при оформлении заказа. Код с лимитом применяется только " +
+				"с JWT пользователя (Authorization: Bearer, иначе 401) и один раз на аккаунт; 422 — код не найден, исчерпан или уже использован.",
+			Request: promoCodeRequest{}, Responses: apidoc.OK(cartResponse{}), Error: apidoc.TextError{}},
+		cart(http.MethodDelete, "/api/cart/promo/{code}", "Убрать промокод", nil),
+
+		// ----- администрирование каталога -----
+		{Method: http.MethodGet, Path: "/api/admin/audit", Tag: "Администрирование", Admin: true, Summary: "Журнал действий администраторов",
+			Query: []apidoc.Param{
+				apidoc.Q("actor", "string", ""),
+				apidoc.Q("action", "string", ""),
+				apidoc.Q("entity", "string", ""),
+				apidoc.Q("entityId", "string", ""),
+				apidoc.Q("from", "string", "RFC3339 или YYYY-MM-DD"),
+				apidoc.Q("to", "string", "RFC3339 или YYYY-MM-DD"),
+				apidoc.Q("limit", "integer", "от 1 до 1000, по умолчанию 100"),
+				apidoc.Q("offset", "integer", ""),
+			},
+			Responses: apidoc.OK([]models.AuditEntry{})},
+		{Method: http.MethodGet, Path: "/api/admin/cars/trash", Tag: "Администрирование", Admin: true, Summary: "Удалённые автомобили",
+			Responses: apidoc.OK([]database.TrashedCar{})},
+		{Method: http.MethodPost, Path: "/api/admin/cars/{id}/restore", Tag: "Администрирование", Admin: true, Summary: "Восстановить из корзины",
+			Responses: apidoc.OK(restoredResponse{})},
+		{Method: http.MethodPut, Path: "/api/admin/cars/{id}/status", Tag: "Администрирование", Admin: true, Summary: "Статус публикации и расписание",
+			Request: models.CarPublishing{}, Responses: apidoc.OK(models.CarPublishing{})},
+		{Method: http.MethodPost, Path: "/api/admin/cars/{id}/preview-link", Tag: "Администрирование", Admin: true, Summary: "Подписанная ссылка предпросмотра",
+			Query:     []apidoc.Param{apidoc.Q("ttl", "string", "срок действия, например 24h")},
+			Responses: apidoc.OK(previewLinkResponse{})},
+		{Method: http.MethodPost, Path: "/api/admin/cars/{id}/price-schedule", Tag: "Администрирование", Admin: true, Summary: "Запланировать изменение цены",
+			Request:   schedulePriceRequest{},
+			Responses: []apidoc.Response{{Status: http.StatusCreated, Body: models.ScheduledPriceChange{}}}},
+		{Method: http.MethodGet, Path: "/api/admin/price-schedule", Tag: "Администрирование", Admin: true, Summary: "Запланированные изменения цен",
+			Query: []apidoc.Param{
+				apidoc.Q("carId", "string", "один автомобиль"),
+				apidoc.Q("pending", "boolean", "только ещё не применённые"),
+			},
+			Responses: apidoc.OK([]models.ScheduledPriceChange{})},
+		{Method: http.MethodDelete, Path: "/api/admin/price-schedule/{id:[0-9]+}", Tag: "Администрирование", Admin: true, Summary: "Отменить изменение цены",
+			Responses: apidoc.OK(StatusResponse{})},
+		{Method: http.MethodGet, Path: "/api/admin/carts/abandoned", Tag: "Администрирование", Admin: true, Summary: "Брошенные корзины по моделям",
+			Query:     []apidoc.Param{apidoc.Q("hours", "integer", "сколько часов без активности")},
+			Responses: apidoc.OK(abandonedCartsResponse{})},
+		{Method: http.MethodGet, Path: "/api/admin/catalog/export", Tag: "Администрирование", Admin: true, Summary: "Выгрузка каталога",
+			Query: []apidoc.Param{apidoc.Q("format", "string", "json (по умолчанию) или csv")},
+			Responses: []apidoc.Response{
+				{Status: http.StatusOK, Body: models.CatalogExport{}},
+				{Status: http.StatusOK, Body: "", ContentType: "text/csv"},
+			}},
+		{Method: http.MethodPost, Path: "/api/admin/catalog/import", Tag: "Администрирование", Admin: true, Summary: "Загрузка каталога",
+			Description: "Тело — выгрузка в JSON или CSV (format=csv), либо поле file в multipart/form-data. " +
+				"При atomic=true и ошибках ничего не применяется и ответ 422.",
+			Query: []apidoc.Param{
+				apidoc.Q("format", "string", "json или csv"),
+				apidoc.Q("dryRun", "boolean", "только проверить"),
+				apidoc.Q("atomic", "boolean", "всё или ничего"),
+			},
+			Request: models.CatalogExport{},
+			Responses: []apidoc.Response{
+				{Status: http.StatusOK, Body: models.ImportReport{}},
+				{Status: http.StatusUnprocessableEntity, Body: models.ImportReport{}},
+			}},
+	}
+	return ops
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"renault-backend/database"
+	"renault-backend/models"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type PriceHandler struct {
+	repo *database.PriceRepository
+}
+
+func NewPriceHandler(repo *database.PriceRepository) *PriceHandler {
+	return &PriceHandler{repo: repo}
+}
+
+// History — история цен: GET /api/cars/{id}/price-history
+func (h *PriceHandler) History(w http.ResponseWriter, r *http.Request) {
+	carID := mux.Vars(r)["id"]
+
+	current, err := h.repo.PublishedPrice(carID)
+	if err == sql.ErrNoRows {
+		respondWithError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+
+	history, err := h.repo.History(carID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, priceHistoryResponse{CarID: carID, CurrentPrice: current, History: history})
+}
+
+type schedulePriceRequest struct {
+	NewPrice    int       `json:"newPrice"`
+	EffectiveAt time.Time `json:"effectiveAt"` // RFC 3339, например 2026-11-01T00:00:00+03:00
+}
+
+// Schedule планирует смену цены: POST /api/admin/cars/{id}/price-schedule
+func (h *PriceHandler) Schedule(w http.ResponseWriter, r *http.Request) {
+	carID := mux.Vars(r)["id"]
+
+	var req schedulePriceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if req.NewPrice <= 0 {
+		respondWithError(w, http.StatusBadRequest, "newPrice должен быть больше нуля")
+		return
+	}
+	if !req.EffectiveAt.After(time.Now()) {
+		respondWithError(w, http.StatusBadRequest, "effectiveAt должен быть в будущем")
+		return
+	}
+
+	if _, err := h.repo.CurrentPrice(carID); err == sql.ErrNoRows {
+		respondWithError(w, http.StatusNotFound, "car not found")
+		return
+	} else if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+
+	s := models.ScheduledPriceChange{
+		CarID:       carID,
+		NewPrice:    req.NewPrice,
+		EffectiveAt: req.EffectiveAt.UTC(),
+		CreatedBy:   AdminUsername(r),
+	}
+	if err := h.repo.Schedule(&s); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error: schedule price")
+		return
+	}
+
+	SetAuditChange(r, "price.schedule", "car", carID, nil, s)
+	respondWithJSON(w, http.StatusCreated, s)
+}
+
+// ListScheduled — расписание цен: GET /api/admin/price-schedule?carId=&pending=true
+func (h *PriceHandler) ListScheduled(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	list, err := h.repo.ListScheduled(q.Get("carId"), q.Get("pending") == "true")
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, list)
+}
+
+// CancelScheduled отменяет изменение: DELETE /api/admin/price-schedule/{id}
+func (h *PriceHandler) CancelScheduled(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	ok, err := h.repo.CancelScheduled(id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if !ok {
+		respondWithError(w, http.StatusNotFound, "not found or already applied")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, StatusResponse{Status: "cancelled"})
+}
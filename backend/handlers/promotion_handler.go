@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"renault-backend/database"
+	"renault-backend/models"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// PromotionHandler — управление акциями в админке
+type PromotionHandler struct {
+	repo *database.PromotionRepository
+}
+
+func NewPromotionHandler(repo *database.PromotionRepository) *PromotionHandler {
+	return &PromotionHandler{repo: repo}
+}
+
+// List — все акции: GET /api/admin/promotions
+func (h *PromotionHandler) List(w http.ResponseWriter, r *http.Request) {
+	list, err := h.repo.List()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, list)
+}
+
+func (h *PromotionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var p models.Promotion
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	p.Code = strings.ToUpper(strings.TrimSpace(p.Code))
+	if errs := models.ValidatePromotion(p); len(errs) > 0 {
+		respondWithError(w, http.StatusBadRequest, strings.Join(errs, "; "))
+		return
+	}
+
+	if err := h.repo.Create(&p); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			respondWithError(w, http.StatusConflict, "промокод уже используется в другой акции")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "db error: insert promotion")
+		return
+	}
+	SetAuditChange(r, "promotion.create", "promotion", strconv.Itoa(p.ID), nil, p)
+	respondWithJSON(w, http.StatusCreated, p)
+}
+
+func (h *PromotionHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var p models.Promotion
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	p.ID = id
+	p.Code = strings.ToUpper(strings.TrimSpace(p.Code))
+	if errs := models.ValidatePromotion(p); len(errs) > 0 {
+		respondWithError(w, http.StatusBadRequest, strings.Join(errs, "; "))
+		return
+	}
+
+	before, err := h.repo.GetByID(id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if before == nil {
+		respondWithError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	found, err := h.repo.Update(&p)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			respondWithError(w, http.StatusConflict, "промокод уже используется в другой акции")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "db error: update promotion")
+		return
+	}
+	if !found {
+		respondWithError(w, http.StatusNotFound, "not found")
+		return
+	}
+	p.UsedCount = before.UsedCount
+	SetAuditChange(r, "promotion.update", "promotion", strconv.Itoa(id), before, p)
+	respondWithJSON(w, http.StatusOK, p)
+}
+
+func (h *PromotionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	before, err := h.repo.GetByID(id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if before == nil {
+		respondWithError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	found, err := h.repo.Delete(id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if !found {
+		respondWithError(w, http.StatusNotFound, "not found")
+		return
+	}
+	SetAuditChange(r, "promotion.delete", "promotion", strconv.Itoa(id), before, nil)
+	respondWithJSON(w, http.StatusOK, StatusResponse{Status: "deleted"})
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"renault-backend/database"
+	"renault-backend/models"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultSimilarLimit = 4
+	maxSimilarLimit     = 12
+)
+
+// RecommendationHandler — «похожие автомобили» и «покупатели также смотрели» для карточки
+type RecommendationHandler struct {
+	repo *database.RecommendationRepository
+}
+
+func NewRecommendationHandler(repo *database.RecommendationRepository) *RecommendationHandler {
+	return &RecommendationHandler{repo: repo}
+}
+
+// Similar — GET /api/cars/{id}/similar?limit=4
+// Похожие считаются по категории, цене, особенностям и характеристикам (models.SimilarityScore),
+// «также смотрели / добавляли в корзину» — по просмотрам карточек и корзинам других покупателей.
+func (h *RecommendationHandler) Similar(w http.ResponseWriter, r *http.Request) {
+	carID := mux.Vars(r)["id"]
+
+	limit := defaultSimilarLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			respondWithError(w, http.StatusBadRequest, "limit должен быть положительным числом")
+			return
+		}
+		limit = min(n, maxSimilarLimit)
+	}
+
+	profiles, err := h.repo.Profiles()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	byID := make(map[string]models.CarProfile, len(profiles))
+	for _, p := range profiles {
+		byID[p.ID] = p
+	}
+	target, ok := byID[carID]
+	if !ok {
+		respondWithError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	viewed, err := h.repo.AlsoViewed(carID, limit)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	carted, err := h.repo.AlsoAddedToCart(carID, limit)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.CarRecommendations{
+		CarID:           carID,
+		Similar:         models.RankSimilar(target, profiles, limit),
+		AlsoViewed:      recommendedFrom(viewed, byID),
+		AlsoAddedToCart: recommendedFrom(carted, byID),
+	})
+}
+
+func recommendedFrom(list []models.CoOccurrence, byID map[string]models.CarProfile) []models.RecommendedCar {
+	result := []models.RecommendedCar{}
+	for _, c := range list {
+		if p, ok := byID[c.CarID]; ok {
+			rc := p.Recommended()
+			rc.Customers = c.Customers
+			result = append(result, rc)
+		}
+	}
+	return result
+}
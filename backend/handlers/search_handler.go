@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"renault-backend/database"
+	"renault-backend/models"
+	"strconv"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+	suggestLimit       = 8
+)
+
+// SearchHandler — поиск по каталогу для строки поиска на сайте
+type SearchHandler struct {
+	repo *database.SearchRepository
+}
+
+func NewSearchHandler(repo *database.SearchRepository) *SearchHandler {
+	return &SearchHandler{repo: repo}
+}
+
+// Search — GET /api/search?q=полный+привод&limit=20
+// Сначала ищутся автомобили, где есть все слова; если таких нет — где есть хотя бы одно.
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query().Get("q")
+	tokens := models.SearchTokens(q)
+	if len(tokens) == 0 {
+		respondWithError(w, http.StatusBadRequest, "q обязателен")
+		return
+	}
+
+	limit := defaultSearchLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			respondWithError(w, http.StatusBadRequest, "limit должен быть положительным числом")
+			return
+		}
+		limit = min(n, maxSearchLimit)
+	}
+
+	resp := models.SearchResponse{Query: q}
+	results, total, err := h.repo.Search(models.SearchMatchQuery(tokens, true), limit)
+	if err == nil && total == 0 && len(tokens) > 1 {
+		resp.Loose = true
+		results, total, err = h.repo.Search(models.SearchMatchQuery(tokens, false), limit)
+	}
+	if err != nil {
+		respondWithSearchError(w, err)
+		return
+	}
+
+	resp.Total, resp.Results = total, results
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// Suggest — подсказки для строки поиска с учётом опечаток: GET /api/search/suggest?q=вариатр
+func (h *SearchHandler) Suggest(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query().Get("q")
+
+	vocab, err := h.repo.Vocabulary()
+	if err != nil {
+		respondWithSearchError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, suggestResponse{Query: q, Suggestions: vocab.Suggest(q, suggestLimit)})
+}
+
+func respondWithSearchError(w http.ResponseWriter, err error) {
+	if errors.Is(err, database.ErrSearchUnavailable) {
+		respondWithError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	respondWithError(w, http.StatusInternalServerError, "db error")
+}
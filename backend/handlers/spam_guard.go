@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"renault-backend/antispam"
+	"renault-backend/database"
+	"renault-backend/logging"
+	"renault-backend/models"
+	"renault-backend/realip"
+	"time"
+)
+
+// maxFormBody — отзыв или заявка с запасом; всё крупнее — не форма с сайта
+const maxFormBody = 16 << 10
+
+// SpamFields — служебные поля публичных форм: скрытая ловушка для ботов и токен,
+// полученный формой при показе (GET /api/form-token)
+type SpamFields struct {
+	Website   string `json:"website"`
+	FormToken string `json:"formToken"`
+}
+
+// SpamGuard проверяет отзывы и заявки с сайта и откладывает подозрительные в очередь модерации
+type SpamGuard struct {
+	policy          antispam.Policy
+	tokens          *antispam.FormTokens
+	repo            *database.ModerationRepository
+	duplicateWindow time.Duration
+	ips             *realip.Resolver
+}
+
+// NewSpamGuard — duplicateWindow: за какой срок одинаковые тексты считаются повтором
+func NewSpamGuard(policy antispam.Policy, tokens *antispam.FormTokens, repo *database.ModerationRepository,
+	duplicateWindow time.Duration, ips *realip.Resolver) *SpamGuard {
+	return &SpamGuard{policy: policy, tokens: tokens, repo: repo, duplicateWindow: duplicateWindow, ips: ips}
+}
+
+// FormToken выдаёт токен для формы: GET /api/form-token
+func (g *SpamGuard) FormToken(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	respondWithJSON(w, http.StatusOK, formTokenResponse{Token: g.tokens.Issue()})
+}
+
+// DecodeForm читает JSON формы с ограничением размера; false — ответ с ошибкой уже отправлен
+func (g *SpamGuard) DecodeForm(w http.ResponseWriter, r *http.Request, v any) bool {
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxFormBody)).Decode(v)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		respondWithError(w, http.StatusRequestEntityTooLarge, "request body too large")
+		return false
+	case err != nil:
+		respondWithError(w, http.StatusBadRequest, "invalid json")
+		return false
+	}
+	return true
+}
+
+// Screen возвращает причины отправить форму на модерацию; пустой список — можно сохранять.
+// text — основной текст (ищется среди недавних повторов), fields — прочие поля, набранные человеком.
+func (g *SpamGuard) Screen(r *http.Request, kind string, spam SpamFields, text string, fields ...string) ([]string, error) {
+	age, valid := g.tokens.Age(spam.FormToken)
+	reasons := g.policy.Check(antispam.Submission{
+		Fields:         append([]string{text}, fields...),
+		Honeypot:       spam.Website,
+		FormAge:        age,
+		FormTokenValid: valid,
+	})
+
+	if hash := antispam.Fingerprint(text); hash != "" {
+		seen, err := g.repo.SeenText(kind, hash, time.Now().Add(-g.duplicateWindow))
+		if err != nil {
+			return nil, err
+		}
+		if seen {
+			reasons = append(reasons, antispam.ReasonDuplicate)
+		}
+	}
+	return reasons, nil
+}
+
+// Hold кладёт отправку в очередь модерации и отвечает 202: клиенту не сообщаем, что именно
+// показалось подозрительным
+func (g *SpamGuard) Hold(w http.ResponseWriter, r *http.Request, kind string, payload any, reasons []string) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "encode error")
+		return
+	}
+	item := &models.ModerationItem{Kind: kind, Payload: data, Reasons: reasons, ClientIP: g.ips.ClientIP(r)}
+	if err := g.repo.Enqueue(item); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error: moderation queue")
+		return
+	}
+	logging.FromContext(r.Context()).Warn("submission held for moderation",
+		"kind", kind, "moderation_id", item.ID, "reasons", reasons)
+	respondWithJSON(w, http.StatusAccepted, HeldResponse{Status: "moderation", ID: item.ID})
+}
@@ -0,0 +1,507 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"renault-backend/database"
+	"renault-backend/logging"
+	"renault-backend/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	maxTradeInPhotos    = 10
+	maxTradeInPhotoSize = 10 << 20 // 10 МБ на фото
+	// все фото и поля формы вместе
+	maxTradeInBodySize = maxTradeInPhotos*maxTradeInPhotoSize + 1<<20
+)
+
+var errPhotoTooLarge = fmt.Errorf("фото больше %d МБ", maxTradeInPhotoSize>>20)
+
+var allowedPhotoTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+type TradeInHandler struct {
+	repo      *database.TradeInRepository
+	uploadDir string
+}
+
+func NewTradeInHandler(repo *database.TradeInRepository, uploadDir string) *TradeInHandler {
+	return &TradeInHandler{repo: repo, uploadDir: uploadDir}
+}
+
+// requireAccount возвращает пользователя из JWT (JWTUserMiddleware). Заявки trade-in привязаны
+// к аккаунту, а не к X-User-Id: заголовок задаёт сам клиент.
+func requireAccount(w http.ResponseWriter, r *http.Request) (string, bool) {
+	account := Username(r)
+	if account == "" {
+		respondWithError(w, http.StatusUnauthorized, "login required")
+		return "", false
+	}
+	return account, true
+}
+
+// Submit принимает заявку на trade-in: POST /api/trade-in.
+// Поддерживается multipart/form-data с файлами в поле photos и обычный JSON без фото.
+func (h *TradeInHandler) Submit(w http.ResponseWriter, r *http.Request) {
+	account, ok := requireAccount(w, r)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxTradeInBodySize)
+	var tooLarge *http.MaxBytesError
+
+	var t models.TradeInRequest
+	isMultipart := strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data")
+
+	if isMultipart {
+		err := r.ParseMultipartForm(maxTradeInPhotoSize)
+		if errors.As(err, &tooLarge) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, "request body too large")
+			return
+		}
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid multipart form")
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
+
+		t.Make = r.FormValue("make")
+		t.Model = r.FormValue("model")
+		t.Year, _ = strconv.Atoi(r.FormValue("year"))
+		t.Mileage, _ = strconv.Atoi(r.FormValue("mileage"))
+		t.Condition = r.FormValue("condition")
+		t.Comment = r.FormValue("comment")
+	} else if err := json.NewDecoder(r.Body).Decode(&t); errors.As(err, &tooLarge) {
+		respondWithError(w, http.StatusRequestEntityTooLarge, "request body too large")
+		return
+	} else if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	now := time.Now()
+	if errs := models.ValidateTradeIn(t, now); len(errs) > 0 {
+		respondWithError(w, http.StatusBadRequest, strings.Join(errs, "; "))
+		return
+	}
+
+	rule, err := h.repo.FindDepreciationRule(t.Make, t.Model)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if rule == nil {
+		respondWithError(w, http.StatusUnprocessableEntity, "нет данных для оценки этой марки, обратитесь к менеджеру")
+		return
+	}
+
+	t.Account = account
+	t.UserID = getUserID(r)
+	t.Status = models.TradeInSubmitted
+	t.EstimateMin, t.EstimateMax = models.EstimateTradeIn(t, *rule, now)
+	t.Photos = []string{}
+
+	// если заявка не сохранится, уже записанные фотографии не должны остаться на диске
+	saved := false
+	defer func() {
+		if !saved {
+			h.removePhotos(r, t.Photos)
+		}
+	}()
+
+	if isMultipart {
+		files := r.MultipartForm.File["photos"]
+		if len(files) > maxTradeInPhotos {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("не больше %d фотографий", maxTradeInPhotos))
+			return
+		}
+		for _, fh := range files {
+			name, err := h.savePhoto(fh)
+			if err != nil {
+				respondWithError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			t.Photos = append(t.Photos, name)
+		}
+	}
+
+	if err := h.repo.Create(&t); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error: insert trade-in")
+		return
+	}
+	saved = true
+	t.CreatedAt = now.UTC()
+
+	respondWithJSON(w, http.StatusCreated, t)
+}
+
+// savePhoto проверяет тип и размер файла и сохраняет его в uploadDir под случайным именем
+func (h *TradeInHandler) savePhoto(fh *multipart.FileHeader) (string, error) {
+	if fh.Size > maxTradeInPhotoSize {
+		return "", errPhotoTooLarge
+	}
+
+	src, err := fh.Open()
+	if err != nil {
+		return "", fmt.Errorf("не удалось прочитать фото")
+	}
+	defer src.Close()
+
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(src, head)
+	ext, ok := allowedPhotoTypes[http.DetectContentType(head[:n])]
+	if !ok {
+		return "", fmt.Errorf("допустимы только JPEG, PNG и WebP")
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(h.uploadDir, 0755); err != nil {
+		return "", err
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	name := hex.EncodeToString(b) + ext
+	path := filepath.Join(h.uploadDir, name)
+	dst, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return "", err
+	}
+
+	// читаем на байт больше лимита: размер из заголовка части задаёт клиент
+	written, err := io.Copy(dst, io.LimitReader(src, maxTradeInPhotoSize+1))
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && written > maxTradeInPhotoSize {
+		err = errPhotoTooLarge
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return name, nil
+}
+
+func (h *TradeInHandler) removePhotos(r *http.Request, names []string) {
+	for _, name := range names {
+		if err := os.Remove(filepath.Join(h.uploadDir, name)); err != nil && !os.IsNotExist(err) {
+			logging.FromContext(r.Context()).Warn("trade-in: remove photo", "name", name, "err", err)
+		}
+	}
+}
+
+// servePhoto отдаёт фотографию заявки; имя должно быть среди фотографий этой заявки
+func (h *TradeInHandler) servePhoto(w http.ResponseWriter, r *http.Request, t *models.TradeInRequest) {
+	name := mux.Vars(r)["name"]
+	for _, p := range t.Photos {
+		if p == name {
+			w.Header().Set("Cache-Control", "private, no-store")
+			http.ServeFile(w, r, filepath.Join(h.uploadDir, name))
+			return
+		}
+	}
+	respondWithError(w, http.StatusNotFound, "not found")
+}
+
+// Photo — фотография своей заявки: GET /api/trade-in/{id}/photos/{name}
+func (h *TradeInHandler) Photo(w http.ResponseWriter, r *http.Request) {
+	if t := h.loadOwned(w, r); t != nil {
+		h.servePhoto(w, r, t)
+	}
+}
+
+// ListMine возвращает заявки текущего пользователя: GET /api/trade-in
+func (h *TradeInHandler) ListMine(w http.ResponseWriter, r *http.Request) {
+	account, ok := requireAccount(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := h.repo.List(account, "")
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, list)
+}
+
+// loadOwned достаёт заявку и проверяет, что она принадлежит пользователю;
+// чужая заявка неотличима от несуществующей
+func (h *TradeInHandler) loadOwned(w http.ResponseWriter, r *http.Request) *models.TradeInRequest {
+	account, ok := requireAccount(w, r)
+	if !ok {
+		return nil
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid id")
+		return nil
+	}
+
+	t, err := h.repo.GetByID(id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return nil
+	}
+	if t == nil || t.Account != account {
+		respondWithError(w, http.StatusNotFound, "not found")
+		return nil
+	}
+	return t
+}
+
+// GetMine возвращает одну заявку пользователя: GET /api/trade-in/{id}
+func (h *TradeInHandler) GetMine(w http.ResponseWriter, r *http.Request) {
+	if t := h.loadOwned(w, r); t != nil {
+		respondWithJSON(w, http.StatusOK, t)
+	}
+}
+
+// Accept — клиент принимает финальное предложение
+func (h *TradeInHandler) Accept(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, models.TradeInOffered, models.TradeInAccepted)
+}
+
+// Reject — клиент отказывается от предложения
+func (h *TradeInHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, models.TradeInOffered, models.TradeInRejected)
+}
+
+// Apply зачитывает принятое предложение в счёт покупки из корзины X-User-Id
+func (h *TradeInHandler) Apply(w http.ResponseWriter, r *http.Request) {
+	t := h.loadOwned(w, r)
+	if t == nil {
+		return
+	}
+	userID := getUserID(r)
+	if userID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing X-User-Id")
+		return
+	}
+
+	ok, err := h.repo.Apply(t.ID, userID)
+	h.respondTransition(w, t, models.TradeInAccepted, ok, err)
+}
+
+func (h *TradeInHandler) transition(w http.ResponseWriter, r *http.Request, from, to string) {
+	t := h.loadOwned(w, r)
+	if t == nil {
+		return
+	}
+
+	ok, err := h.repo.SetStatus(t.ID, from, to)
+	h.respondTransition(w, t, from, ok, err)
+}
+
+// respondTransition отвечает обновлённой заявкой или 409, если она была не в статусе from
+func (h *TradeInHandler) respondTransition(w http.ResponseWriter, t *models.TradeInRequest, from string, ok bool, err error) {
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if !ok {
+		respondWithError(w, http.StatusConflict, fmt.Sprintf("заявка в статусе %s, ожидался %s", t.Status, from))
+		return
+	}
+
+	updated, err := h.repo.GetByID(t.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, updated)
+}
+
+// Credit возвращает сумму trade-in пользователя, зачтённых в корзину X-User-Id
+// и ещё не ушедших в заказ: GET /api/trade-in/credit
+func (h *TradeInHandler) Credit(w http.ResponseWriter, r *http.Request) {
+	account, ok := requireAccount(w, r)
+	if !ok {
+		return
+	}
+	userID := getUserID(r)
+	if userID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing X-User-Id")
+		return
+	}
+
+	credit, _, err := h.repo.AppliedCredit(userID, account)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, tradeInCreditResponse{Credit: credit})
+}
+
+// ---------- админка ----------
+
+// AdminList — очередь заявок для менеджеров: GET /api/admin/trade-in?status=submitted
+func (h *TradeInHandler) AdminList(w http.ResponseWriter, r *http.Request) {
+	list, err := h.repo.List("", r.URL.Query().Get("status"))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, list)
+}
+
+// AdminPhoto — фотография любой заявки: GET /api/admin/trade-in/{id}/photos/{name}
+func (h *TradeInHandler) AdminPhoto(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	t, err := h.repo.GetByID(id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if t == nil {
+		respondWithError(w, http.StatusNotFound, "not found")
+		return
+	}
+	h.servePhoto(w, r, t)
+}
+
+type tradeInOfferRequest struct {
+	FinalOffer int    `json:"finalOffer"`
+	Note       string `json:"note"`
+}
+
+// SetOffer — менеджер выставляет финальную цену: PUT /api/admin/trade-in/{id}/offer
+func (h *TradeInHandler) SetOffer(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var req tradeInOfferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if req.FinalOffer <= 0 {
+		respondWithError(w, http.StatusBadRequest, "finalOffer должен быть больше нуля")
+		return
+	}
+
+	t, err := h.repo.GetByID(id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if t == nil {
+		respondWithError(w, http.StatusNotFound, "not found")
+		return
+	}
+	// пересмотреть можно, пока клиент не принял предложение
+	if t.Status != models.TradeInSubmitted && t.Status != models.TradeInOffered {
+		respondWithError(w, http.StatusConflict, "заявка уже закрыта")
+		return
+	}
+
+	if err := h.repo.SetOffer(id, req.FinalOffer, req.Note, AdminUsername(r)); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+
+	updated, err := h.repo.GetByID(id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	SetAuditChange(r, "trade_in.offer", "trade_in", strconv.Itoa(id), t, updated)
+	respondWithJSON(w, http.StatusOK, updated)
+}
+
+func (h *TradeInHandler) ListDepreciation(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.repo.ListDepreciationRules()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, rules)
+}
+
+// SaveDepreciation добавляет или обновляет правило: PUT /api/admin/trade-in/depreciation
+func (h *TradeInHandler) SaveDepreciation(w http.ResponseWriter, r *http.Request) {
+	var d models.DepreciationRule
+	if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if errs := models.ValidateDepreciationRule(d); len(errs) > 0 {
+		respondWithError(w, http.StatusBadRequest, strings.Join(errs, "; "))
+		return
+	}
+
+	before, err := h.repo.GetDepreciationRule(d.Make, d.Model)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if err := h.repo.SaveDepreciationRule(&d); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	after, err := h.repo.GetDepreciationRule(d.Make, d.Model)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	SetAuditChange(r, "depreciation.save", "depreciation", strconv.Itoa(after.ID), before, after)
+	respondWithJSON(w, http.StatusOK, StatusResponse{Status: "saved"})
+}
+
+func (h *TradeInHandler) DeleteDepreciation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	before, err := h.repo.GetDepreciationRuleByID(id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if before == nil {
+		respondWithError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	found, err := h.repo.DeleteDepreciationRule(id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if !found {
+		respondWithError(w, http.StatusNotFound, "not found")
+		return
+	}
+	SetAuditChange(r, "depreciation.delete", "depreciation", strconv.Itoa(id), before, nil)
+	respondWithJSON(w, http.StatusOK, StatusResponse{Status: "deleted"})
+}
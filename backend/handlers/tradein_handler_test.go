@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"renault-backend/database"
+	"renault-backend/models"
+	"strconv"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestTradeInRefusesOtherAccounts(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1) // у каждого соединения своя база в памяти
+	prev := database.DB
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = prev
+		db.Close()
+	})
+	if err := database.CreateTables(slog.New(slog.DiscardHandler)); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := database.NewTradeInRepository()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "photo.jpg"), []byte("jpeg"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ti := models.TradeInRequest{Account: "alice", UserID: "cart-alice", Make: "renault", Model: "logan",
+		Year: 2018, Mileage: 60000, Condition: "good", EstimateMin: 500000, EstimateMax: 600000,
+		Status: models.TradeInSubmitted, Photos: []string{"photo.jpg"}}
+	if err := repo.Create(&ti); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SetOffer(ti.ID, 550000, "", "manager"); err != nil {
+		t.Fatal(err)
+	}
+	h := NewTradeInHandler(repo, dir)
+	id := strconv.Itoa(ti.ID)
+
+	call := func(handler http.HandlerFunc, account, cart string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": id, "name": "photo.jpg"})
+		if account != "" {
+			req = req.WithContext(WithUsername(req.Context(), account))
+		}
+		if cart != "" {
+			req.Header.Set("X-User-Id", cart)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		account string
+		want    int
+	}{
+		{"anonymous get", h.GetMine, "", http.StatusUnauthorized},
+		{"other account get", h.GetMine, "bob", http.StatusNotFound},
+		{"other account photo", h.Photo, "bob", http.StatusNotFound},
+		{"other account accept", h.Accept, "bob", http.StatusNotFound},
+		{"other account reject", h.Reject, "bob", http.StatusNotFound},
+		{"other account apply", h.Apply, "bob", http.StatusNotFound},
+		{"owner get", h.GetMine, "alice", http.StatusOK},
+		{"owner photo", h.Photo, "alice", http.StatusOK},
+		{"owner accept", h.Accept, "alice", http.StatusOK},
+	}
+	for _, tt := range tests {
+		if rec := call(tt.handler, tt.account, "cart-bob"); rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tt.name, rec.Code, tt.want, rec.Body)
+		}
+	}
+
+	// чужой аккаунт не видит заявку в списке и не получает её зачёт, даже подставив корзину владельца
+	if list, err := repo.List("bob", ""); err != nil || len(list) != 0 {
+		t.Errorf("List(bob) = %v, %v; want empty", list, err)
+	}
+	if rec := call(h.Apply, "alice", "cart-alice"); rec.Code != http.StatusOK {
+		t.Fatalf("owner apply: status = %d (%s)", rec.Code, rec.Body)
+	}
+	if credit, _, err := repo.AppliedCredit("cart-alice", "bob"); err != nil || credit != 0 {
+		t.Errorf("AppliedCredit(cart-alice, bob) = %d, %v; want 0", credit, err)
+	}
+	if credit, _, err := repo.AppliedCredit("cart-alice", "alice"); err != nil || credit != 550000 {
+		t.Errorf("AppliedCredit(cart-alice, alice) = %d, %v; want 550000", credit, err)
+	}
+}
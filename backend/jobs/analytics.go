@@ -0,0 +1,112 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"renault-backend/database"
+	"renault-backend/models"
+	"sync/atomic"
+	"time"
+)
+
+// AnalyticsWriter принимает события через буферизованный канал и пишет их в БД пачками,
+// чтобы запись аналитики не задерживала ответы хендлеров
+type AnalyticsWriter struct {
+	repo       *database.AnalyticsRepository
+	events     chan models.AnalyticsEvent
+	batchSize  int
+	flushEvery time.Duration
+	dropped    atomic.Int64
+	logger     *slog.Logger
+}
+
+// NewAnalyticsWriter — buffer: сколько событий может ждать записи; при переполнении события отбрасываются
+func NewAnalyticsWriter(repo *database.AnalyticsRepository, buffer int, logger *slog.Logger) *AnalyticsWriter {
+	return &AnalyticsWriter{
+		repo:       repo,
+		events:     make(chan models.AnalyticsEvent, buffer),
+		batchSize:  100,
+		flushEvery: time.Second,
+		logger:     logger,
+	}
+}
+
+// Record ставит событие в очередь без ожидания; false — буфер полон, событие потеряно
+func (w *AnalyticsWriter) Record(e models.AnalyticsEvent) bool {
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+	select {
+	case w.events <- e:
+		return true
+	default:
+		if n := w.dropped.Add(1); n == 1 || n%1000 == 0 {
+			w.logger.Warn("analytics buffer full, events dropped", "dropped_total", n)
+		}
+		return false
+	}
+}
+
+// Run пишет события до отмены ctx, после чего дописывает то, что осталось в буфере
+func (w *AnalyticsWriter) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.flushEvery)
+	defer ticker.Stop()
+
+	batch := make([]models.AnalyticsEvent, 0, w.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := w.repo.InsertEvents(batch); err != nil {
+			w.logger.Error("analytics: write events", "events", len(batch), "err", err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			for {
+				select {
+				case e := <-w.events:
+					batch = append(batch, e)
+				default:
+					flush()
+					return
+				}
+			}
+		case e := <-w.events:
+			batch = append(batch, e)
+			if len(batch) >= w.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// AnalyticsRollup периодически пересчитывает дневные свёртки для отчётов
+type AnalyticsRollup struct {
+	repo     *database.AnalyticsRepository
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewAnalyticsRollup(repo *database.AnalyticsRepository, interval time.Duration, logger *slog.Logger) *AnalyticsRollup {
+	return &AnalyticsRollup{repo: repo, interval: interval, logger: logger}
+}
+
+func (a *AnalyticsRollup) Run(ctx context.Context) {
+	Every(ctx, a.interval, a.tick)
+}
+
+func (a *AnalyticsRollup) tick() {
+	n, err := a.repo.RefreshRollups()
+	if err != nil {
+		a.logger.Error("analytics rollup", "err", err)
+	}
+	if n > 0 {
+		a.logger.Info("analytics rollup", "events", n)
+	}
+}
@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"renault-backend/database"
+	"time"
+)
+
+// AuditRetention удаляет записи журнала аудита старше срока хранения
+type AuditRetention struct {
+	repo     *database.AuditRepository
+	keep     time.Duration
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewAuditRetention(repo *database.AuditRepository, keep, interval time.Duration, logger *slog.Logger) *AuditRetention {
+	return &AuditRetention{repo: repo, keep: keep, interval: interval, logger: logger}
+}
+
+func (a *AuditRetention) Run(ctx context.Context) {
+	Every(ctx, a.interval, a.tick)
+}
+
+func (a *AuditRetention) tick() {
+	n, err := a.repo.Purge(time.Now().Add(-a.keep))
+	if err != nil {
+		a.logger.Error("audit retention", "err", err)
+	}
+	if n > 0 {
+		a.logger.Info("audit retention: purged", "records", n)
+	}
+}
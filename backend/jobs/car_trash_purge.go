@@ -0,0 +1,43 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"renault-backend/database"
+	"time"
+)
+
+// CarTrashPurge окончательно удаляет автомобили, пролежавшие в корзине дольше срока хранения,
+// и убирает их из корзин покупателей
+type CarTrashPurge struct {
+	trash     *database.CarTrashRepository
+	carts     *database.CartRepository
+	retention time.Duration
+	interval  time.Duration
+	logger    *slog.Logger
+}
+
+func NewCarTrashPurge(trash *database.CarTrashRepository, carts *database.CartRepository, retention, interval time.Duration, logger *slog.Logger) *CarTrashPurge {
+	return &CarTrashPurge{trash: trash, carts: carts, retention: retention, interval: interval, logger: logger}
+}
+
+func (p *CarTrashPurge) Run(ctx context.Context) {
+	Every(ctx, p.interval, p.tick)
+}
+
+func (p *CarTrashPurge) tick() {
+	ids, err := p.trash.Purge(time.Now().Add(-p.retention))
+	if err != nil {
+		p.logger.Error("car trash: purge", "err", err)
+		return
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	removed, err := p.carts.RemoveCars(ids)
+	if err != nil {
+		p.logger.Error("car trash: clean carts", "err", err)
+	}
+	p.logger.Info("car trash: purged", "cars", len(ids), "cart_lines", removed)
+}
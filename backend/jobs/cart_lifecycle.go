@@ -0,0 +1,115 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"renault-backend/database"
+	"renault-backend/notify"
+	"time"
+)
+
+// CartLifecycle удаляет просроченные корзины и ставит в очередь
+// напоминания о брошенных корзинах
+type CartLifecycle struct {
+	carts         *database.CartRepository
+	promos        *database.PromotionRepository
+	notifications *database.NotificationRepository
+
+	expireAfter    time.Duration
+	abandonedAfter time.Duration
+	interval       time.Duration
+	logger         *slog.Logger
+}
+
+func NewCartLifecycle(carts *database.CartRepository, promos *database.PromotionRepository,
+	notifications *database.NotificationRepository, expireAfter, abandonedAfter, interval time.Duration, logger *slog.Logger) *CartLifecycle {
+	return &CartLifecycle{
+		carts:          carts,
+		promos:         promos,
+		notifications:  notifications,
+		expireAfter:    expireAfter,
+		abandonedAfter: abandonedAfter,
+		interval:       interval,
+		logger:         logger,
+	}
+}
+
+func (c *CartLifecycle) Run(ctx context.Context) {
+	Every(ctx, c.interval, c.tick)
+}
+
+func (c *CartLifecycle) tick() {
+	now := time.Now()
+
+	// сначала удаляем просроченные — по ним напоминать уже незачем
+	if c.expireAfter > 0 {
+		users, err := c.carts.ExpireStale(now.Add(-c.expireAfter))
+		if err != nil {
+			c.logger.Error("cart lifecycle: expire", "err", err)
+		}
+		for _, u := range users {
+			if err := c.promos.ReleaseAll(u); err != nil {
+				c.logger.Error("cart lifecycle: release promo codes", "user", u, "err", err)
+			}
+		}
+		if len(users) > 0 {
+			c.logger.Info("cart lifecycle: expired carts", "carts", len(users))
+		}
+	}
+
+	if c.abandonedAfter <= 0 {
+		return
+	}
+	abandoned, err := c.carts.FindAbandoned(now.Add(-c.abandonedAfter))
+	if err != nil {
+		c.logger.Error("cart lifecycle: find abandoned", "err", err)
+		return
+	}
+	for _, cart := range abandoned {
+		if err := c.remind(cart); err != nil {
+			c.logger.Error("cart lifecycle: remind", "user", cart.UserID, "err", err)
+		}
+	}
+}
+
+func (c *CartLifecycle) remind(cart database.AbandonedCart) error {
+	items, err := c.carts.GetCartDetailed(cart.UserID)
+	if err != nil {
+		return err
+	}
+
+	type reminderItem struct {
+		CarID    string `json:"carId"`
+		Title    string `json:"title"`
+		Quantity int    `json:"quantity"`
+	}
+	payload := struct {
+		LastActivity time.Time      `json:"lastActivity"`
+		Items        []reminderItem `json:"items"`
+	}{LastActivity: cart.LastActivity}
+	for _, it := range items {
+		if it.CarDeleted {
+			continue
+		}
+		payload.Items = append(payload.Items, reminderItem{CarID: it.CarID, Title: it.Title, Quantity: it.Quantity})
+	}
+
+	// в корзине остались только снятые с продажи машины — напоминать не о чем
+	if len(payload.Items) > 0 {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		err = c.notifications.Enqueue(notify.Notification{
+			UserID:  cart.UserID,
+			Kind:    "cart_reminder",
+			Subject: "Вы оставили автомобили в корзине",
+			Payload: data,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return c.carts.MarkReminded(cart.UserID, cart.LastActivity)
+}
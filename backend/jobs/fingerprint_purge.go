@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"renault-backend/database"
+	"time"
+)
+
+// FingerprintPurge удаляет отпечатки текстов отзывов и заявок, вышедшие из окна поиска повторов
+type FingerprintPurge struct {
+	repo     *database.ModerationRepository
+	window   time.Duration
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewFingerprintPurge(repo *database.ModerationRepository, window, interval time.Duration, logger *slog.Logger) *FingerprintPurge {
+	return &FingerprintPurge{repo: repo, window: window, interval: interval, logger: logger}
+}
+
+func (p *FingerprintPurge) Run(ctx context.Context) {
+	Every(ctx, p.interval, p.tick)
+}
+
+func (p *FingerprintPurge) tick() {
+	n, err := p.repo.PurgeFingerprints(time.Now().Add(-p.window))
+	if err != nil {
+		p.logger.Error("fingerprint purge", "err", err)
+	}
+	if n > 0 {
+		p.logger.Info("fingerprint purge: removed", "fingerprints", n)
+	}
+}
@@ -0,0 +1,24 @@
+// Package jobs содержит фоновые задачи сервера (планировщики, очистка и т.п.)
+package jobs
+
+import (
+	"context"
+	"time"
+)
+
+// Every вызывает fn сразу и затем каждые interval, пока ctx не отменён
+func Every(ctx context.Context, interval time.Duration, fn func()) {
+	fn()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fn()
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"renault-backend/database"
+	"renault-backend/notify"
+	"time"
+)
+
+// NotificationDispatcher отправляет уведомления из очереди через notifier
+type NotificationDispatcher struct {
+	repo      *database.NotificationRepository
+	notifier  notify.Notifier
+	interval  time.Duration
+	batchSize int
+	logger    *slog.Logger
+}
+
+func NewNotificationDispatcher(repo *database.NotificationRepository, notifier notify.Notifier, interval time.Duration, logger *slog.Logger) *NotificationDispatcher {
+	return &NotificationDispatcher{repo: repo, notifier: notifier, interval: interval, batchSize: 100, logger: logger}
+}
+
+func (d *NotificationDispatcher) Run(ctx context.Context) {
+	Every(ctx, d.interval, func() { d.tick(ctx) })
+}
+
+func (d *NotificationDispatcher) tick(ctx context.Context) {
+	pending, err := d.repo.Pending(d.batchSize)
+	if err != nil {
+		d.logger.Error("notifications: load pending", "err", err)
+		return
+	}
+	for _, n := range pending {
+		if err := d.notifier.Send(ctx, n); err != nil {
+			d.logger.Error("notifications: send", "id", n.ID, "err", err)
+			if err := d.repo.MarkFailed(n.ID, err); err != nil {
+				d.logger.Error("notifications: mark failed", "id", n.ID, "err", err)
+			}
+			continue
+		}
+		if err := d.repo.MarkSent(n.ID); err != nil {
+			d.logger.Error("notifications: mark sent", "id", n.ID, "err", err)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"renault-backend/database"
+	"time"
+)
+
+// PriceScheduler периодически применяет запланированные изменения цен
+type PriceScheduler struct {
+	repo     *database.PriceRepository
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewPriceScheduler(repo *database.PriceRepository, interval time.Duration, logger *slog.Logger) *PriceScheduler {
+	return &PriceScheduler{repo: repo, interval: interval, logger: logger}
+}
+
+// Run работает до отмены ctx; первый проход — сразу при старте,
+// чтобы применить изменения, наступившие, пока сервер был выключен.
+func (s *PriceScheduler) Run(ctx context.Context) {
+	Every(ctx, s.interval, s.tick)
+}
+
+func (s *PriceScheduler) tick() {
+	n, err := s.repo.ApplyDue(time.Now())
+	if err != nil {
+		s.logger.Error("price scheduler", "err", err)
+	}
+	if n > 0 {
+		s.logger.Info("price scheduler: applied scheduled changes", "changes", n)
+	}
+}
@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"renault-backend/database"
+	"time"
+)
+
+// PublishScheduler публикует и снимает с публикации автомобили по расписанию
+type PublishScheduler struct {
+	repo     *database.CarPublishingRepository
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewPublishScheduler(repo *database.CarPublishingRepository, interval time.Duration, logger *slog.Logger) *PublishScheduler {
+	return &PublishScheduler{repo: repo, interval: interval, logger: logger}
+}
+
+func (s *PublishScheduler) Run(ctx context.Context) {
+	Every(ctx, s.interval, s.tick)
+}
+
+func (s *PublishScheduler) tick() {
+	published, unpublished, err := s.repo.ApplyDue(time.Now())
+	if err != nil {
+		s.logger.Error("publish scheduler", "err", err)
+		return
+	}
+	if published > 0 || unpublished > 0 {
+		s.logger.Info("publish scheduler", "published", published, "unpublished", unpublished)
+	}
+}
@@ -0,0 +1,29 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"renault-backend/ratelimit"
+	"time"
+)
+
+// RateLimitSweep убирает из памяти вёдра клиентов, которые уже полностью восстановились
+type RateLimitSweep struct {
+	store    *ratelimit.MemoryStore
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewRateLimitSweep(store *ratelimit.MemoryStore, interval time.Duration, logger *slog.Logger) *RateLimitSweep {
+	return &RateLimitSweep{store: store, interval: interval, logger: logger}
+}
+
+func (s *RateLimitSweep) Run(ctx context.Context) {
+	Every(ctx, s.interval, s.tick)
+}
+
+func (s *RateLimitSweep) tick() {
+	if n := s.store.Sweep(); n > 0 {
+		s.logger.Debug("rate limit sweep", "removed", n, "remaining", s.store.Len())
+	}
+}
@@ -0,0 +1,141 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+
+	"renault-backend/realip"
+
+	"github.com/gorilla/mux"
+)
+
+// RequestIDHeader — заголовок с идентификатором запроса; приходит от прокси или генерируется
+const RequestIDHeader = "X-Request-ID"
+
+// чужой идентификатор принимаем, только если он короткий и без мусора
+var requestIDRe = regexp.MustCompile(`^[A-Za-z0-9._\-]{1,64}$`)
+
+type requestIDKey struct{}
+
+// requestInfo заполняется по ходу обработки и читается журналом доступа
+type requestInfo struct {
+	user string
+}
+
+type requestInfoKey struct{}
+
+// RequestID — внешний middleware: берёт X-Request-ID из запроса или создаёт новый,
+// возвращает его в ответе и кладёт в контекст логгер с request_id
+func RequestID(base *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !requestIDRe.MatchString(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+		ctx = context.WithValue(ctx, requestInfoKey{}, &requestInfo{})
+		ctx = WithLogger(ctx, base.With("request_id", id))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequestIDFrom — идентификатор текущего запроса или пустая строка
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// SetUser сообщает журналу доступа, кто выполнил запрос (например, администратор из JWT)
+func SetUser(ctx context.Context, user string) {
+	if info, ok := ctx.Value(requestInfoKey{}).(*requestInfo); ok {
+		info.user = user
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "req-" + time.Now().UTC().Format("150405.000000000")
+	}
+	return hex.EncodeToString(b)
+}
+
+// AccessLog — middleware для mux.Router: строка журнала на каждый запрос с шаблоном маршрута,
+// кодом ответа, длительностью и пользователем. 5xx пишутся как error, 4xx — как warn.
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := NewResponseRecorder(w)
+		start := time.Now()
+		next.ServeHTTP(rec, r)
+
+		route := "unmatched"
+		if cur := mux.CurrentRoute(r); cur != nil {
+			if tpl, err := cur.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+		user := r.Header.Get("X-User-Id")
+		if info, ok := r.Context().Value(requestInfoKey{}).(*requestInfo); ok && info.user != "" {
+			user = info.user
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case rec.Status >= 500:
+			level = slog.LevelError
+		case rec.Status >= 400:
+			level = slog.LevelWarn
+		}
+		FromContext(r.Context()).LogAttrs(r.Context(), level, "request",
+			slog.String("method", r.Method),
+			slog.String("route", route),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.Status),
+			slog.Int("bytes", rec.Bytes),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("user", user),
+			slog.String("ip", realip.RemoteIP(r)),
+		)
+	})
+}
+
+// ResponseRecorder запоминает код и размер ответа для middleware журнала, метрик и аудита
+type ResponseRecorder struct {
+	http.ResponseWriter
+	Status int
+	Bytes  int
+}
+
+func NewResponseRecorder(w http.ResponseWriter) *ResponseRecorder {
+	return &ResponseRecorder{ResponseWriter: w, Status: http.StatusOK}
+}
+
+func (s *ResponseRecorder) WriteHeader(code int) {
+	s.Status = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *ResponseRecorder) Write(b []byte) (int, error) {
+	n, err := s.ResponseWriter.Write(b)
+	s.Bytes += n
+	return n, err
+}
+
+// Flush нужен потоковым ответам (выгрузка каталога и т.п.)
+func (s *ResponseRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap даёт http.ResponseController добраться до исходного ResponseWriter
+func (s *ResponseRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
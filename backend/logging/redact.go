@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// ключи, значения которых в логи не попадают вовсе
+var secretKeys = []string{"password", "token", "secret", "authorization", "cookie"}
+
+var (
+	emailRe  = regexp.MustCompile(`([A-Za-z0-9._%+\-])[A-Za-z0-9._%+\-]*@([A-Za-z0-9.\-]+\.[A-Za-z]{2,})`)
+	jwtRe    = regexp.MustCompile(`eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`)
+	bearerRe = regexp.MustCompile(`(?i)(bearer\s+)\S+`)
+)
+
+// Redact маскирует в тексте адреса почты (ivan@mail.ru → i***@mail.ru) и токены
+func Redact(s string) string {
+	if !strings.ContainsAny(s, "@.") && !strings.Contains(strings.ToLower(s), "bearer") {
+		return s
+	}
+	s = bearerRe.ReplaceAllString(s, "${1}"+redacted)
+	s = jwtRe.ReplaceAllString(s, redacted)
+	return emailRe.ReplaceAllString(s, "${1}***@${2}")
+}
+
+// redactAttr — ReplaceAttr для slog: скрывает значения секретных ключей
+// и маскирует почту и токены в строках и ошибках
+func redactAttr(_ []string, a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+	for _, k := range secretKeys {
+		if strings.Contains(key, k) {
+			return slog.String(a.Key, redacted)
+		}
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		if v := a.Value.String(); v != "" {
+			return slog.String(a.Key, Redact(v))
+		}
+	case slog.KindAny:
+		switch v := a.Value.Any().(type) {
+		case error:
+			return slog.String(a.Key, Redact(v.Error()))
+		case fmt.Stringer:
+			return slog.String(a.Key, Redact(v.String()))
+		}
+	}
+	return a
+}
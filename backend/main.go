@@ -2,19 +2,38 @@
 package main
 
 import (
+	"context"
+	"crypto/hkdf"
+	"crypto/sha256"
 	"database/sql"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
+	"renault-backend/antispam"
+	"renault-backend/config"
 	"renault-backend/database"
+	"renault-backend/fixtures"
 	"renault-backend/handlers"
+	"renault-backend/jobs"
+	"renault-backend/logging"
+	"renault-backend/metrics"
+	"renault-backend/models"
+	"renault-backend/realip"
+	"renault-backend/security"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gorilla/mux"
 	_ "github.com/mattn/go-sqlite3"
-	"github.com/rs/cors"
 )
 
 const (
@@ -22,6 +41,21 @@ const (
 	PORT       = "8080"
 )
 
+// Ключи подписей выводятся из JWT_SECRET через HKDF, у каждой цели свой:
+// подпись ссылки предпросмотра нельзя выдать за токен формы и наоборот
+var (
+	previewLinkKey = deriveKey("car-preview-link")
+	formTokenKey   = deriveKey("form-token")
+)
+
+func deriveKey(purpose string) []byte {
+	key, err := hkdf.Key(sha256.New, []byte(JWT_SECRET), nil, "renault-backend/"+purpose, sha256.Size)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
 // ----- Модели каталога -----
 
 type Car struct {
@@ -36,233 +70,278 @@ type Car struct {
 	Features    []string `json:"features"`
 	TechSpecs   []Spec   `json:"techSpecs"`
 	Equipment   []Spec   `json:"equipment"`
-}
 
-type Review struct {
-	ID        int    `json:"id"`
-	Email     string `json:"email"`
-	Model     string `json:"model"`
-	Rating    int    `json:"rating"`
-	Text      string `json:"text"`
-	CreatedAt string `json:"created_at"`
-}
+	// типизированные характеристики для фильтров (только чтение, см. models.SpecSchema)
+	Specs models.SpecValues `json:"specs"`
+
+	// цена с учётом действующих акций; Price остаётся исходной base_price
+	DiscountedPrice int                       `json:"discountedPrice"`
+	Promotions      []models.AppliedPromotion `json:"promotions"`
+
+	// статус публикации (draft, published, archived) и расписание
+	models.CarPublishing
 
-type Spec struct {
-	Name  string `json:"name"`
-	Value string `json:"value"`
+	// увеличивается при каждом изменении; отдаётся как ETag
+	Version int `json:"version"`
 }
 
+// Review — отзыв; тип общий с очередью модерации
+type Review = models.Review
+
+// Spec — характеристика; тот же тип, что и в выгрузке каталога
+type Spec = models.CatalogSpec
+
 // отдельная БД под каталог автомобилей
 var carDB *sql.DB
 
-func CORS(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+// акции каталога, нужны хендлерам для расчёта цены со скидкой
+var promoRepo *database.PromotionRepository
 
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-User-Id")
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
+// recommendationHandler учитывает просмотры карточек для «покупатели также смотрели»
+var recommendationHandler *handlers.RecommendationHandler
 
-		// preflight OPTIONS request — отвечаем сразу
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
+// события аналитики от хендлеров каталога и отзывов (пишутся асинхронно)
+var analyticsEvents handlers.EventRecorder
 
-		next.ServeHTTP(w, r)
-	})
-}
+// отзывы покупателей и антиспам для них (подозрительные уходят на модерацию)
+var reviewRepo *database.ReviewRepository
+var spamGuard *handlers.SpamGuard
 
 func createReviewHandler(w http.ResponseWriter, r *http.Request) {
-	var rev Review
-	if err := json.NewDecoder(r.Body).Decode(&rev); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+	var req struct {
+		Review
+		handlers.SpamFields
+	}
+	if !spamGuard.DecodeForm(w, r, &req) {
 		return
 	}
+	rev := req.Review
+	rev.Email = strings.TrimSpace(rev.Email)
 
-	if rev.Email == "" || rev.Text == "" {
-		http.Error(w, "email and text are required", http.StatusBadRequest)
+	if errs := models.ValidateReview(rev); len(errs) > 0 {
+		http.Error(w, strings.Join(errs, "; "), http.StatusBadRequest)
 		return
 	}
-	if rev.Rating < 0 || rev.Rating > 5 {
-		http.Error(w, "rating must be between 0 and 5", http.StatusBadRequest)
+
+	// подозрительный отзыв не публикуем, а откладываем до решения администратора
+	reasons, err := spamGuard.Screen(r, models.ModerationKindReview, req.SpamFields, rev.Text, rev.Model)
+	if err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+	if len(reasons) > 0 {
+		spamGuard.Hold(w, r, models.ModerationKindReview, rev, reasons)
 		return
 	}
 
-	res, err := carDB.Exec(`
-        INSERT INTO reviews (email, model, rating, text)
-        VALUES (?, ?, ?, ?)
-    `, rev.Email, rev.Model, rev.Rating, rev.Text)
-	if err != nil {
+	if err := reviewRepo.Create(&rev); err != nil {
 		http.Error(w, "db error", http.StatusInternalServerError)
 		return
 	}
 
-	id, _ := res.LastInsertId()
-	rev.ID = int(id)
+	// отзыв пишется по названию модели, к автомобилю каталога он не привязан
+	analyticsEvents.Record(models.AnalyticsEvent{Type: models.EventReviewSubmitted, Rating: rev.Rating})
 
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]any{
-		"status": "ok",
-		"id":     rev.ID,
-	})
+	json.NewEncoder(w).Encode(handlers.CreatedResponse{Status: "ok", ID: rev.ID})
 }
 
 func main() {
-	// ---------- БД пользователей / auth (твоя старая логика) ----------
-	if err := database.InitDB(); err != nil {
-		log.Fatalf("Failed to connect to users DB: %v", err)
+	cfg := config.LoadConfig()
+
+	// ---------- Журнал ----------
+	logger, err := logging.New(os.Stderr, cfg.LogFormat, cfg.LogLevel)
+	if err != nil {
+		slog.Error("invalid logging config", "err", err)
+		os.Exit(1)
 	}
-	defer database.DB.Close()
+	// log.Printf из сторонних пакетов тоже попадёт в структурированный журнал
+	slog.SetDefault(logger)
+	// журналы пакетов передаются в конструкторы, записи помечаются component
+	dbLog := logger.With("component", "database")
+	jobsLog := logger.With("component", "jobs")
 
-	userRepo := database.NewUserRepository()
-	authHandler := handlers.NewAuthHandler(userRepo, JWT_SECRET)
+	// ---------- БД пользователей / auth (твоя старая логика) ----------
+	if err := database.InitDB(dbLog); err != nil {
+		fatal("failed to connect to users DB", err)
+	}
 
 	// ---------- БД каталога автомобилей ----------
-	var err error
 	// путь поправь, если бинарник запускается не из корня проекта
-	carDB, err = sql.Open("sqlite3", "cars.db")
+	carDB, err = sql.Open("sqlite3", database.DSN(database.CatalogPath))
 	if err != nil {
-		log.Fatal("Ошибка открытия БД каталога:", err)
+		fatal("failed to open catalog DB", err)
 	}
-	defer carDB.Close()
 
 	if err := carDB.Ping(); err != nil {
-		log.Fatal("Ошибка подключения к БД каталога:", err)
+		fatal("failed to connect to catalog DB", err)
 	}
 
-	if err := createCarTables(); err != nil {
-		log.Fatal("Ошибка создания таблиц каталога:", err)
+	if err := database.InitCatalog(carDB, dbLog); err != nil {
+		fatal("failed to create catalog tables", err)
 	}
 
-	if err := seedCarData(); err != nil {
-		log.Fatal("Ошибка начального заполнения каталога:", err)
-	}
+	publishingRepo := database.NewCarPublishingRepository(carDB)
+	priceRepo := database.NewPriceRepository(carDB)
+	promoRepo = database.NewPromotionRepository(carDB)
+	catalogIORepo := database.NewCatalogIORepository(carDB)
+	recommendationHandler = handlers.NewRecommendationHandler(database.NewRecommendationRepository())
+	analyticsRepo := database.NewAnalyticsRepository()
+	analyticsWriter := jobs.NewAnalyticsWriter(analyticsRepo, cfg.AnalyticsBufferSize, jobsLog)
+	analyticsEvents = analyticsWriter
+
+	// адрес клиента для лимитов, аудита и антиспама: доверяем только заголовку своего прокси
+	clientIPs := realip.New(cfg.RealIPHeader)
 
-	// ---------- Роутер ----------
-	router := mux.NewRouter()
+	// ---------- Антиспам отзывов и заявок ----------
+	stopWords, err := antispam.LoadStopWords(cfg.SpamStopWordsFile)
+	if err != nil {
+		fatal("failed to load stop words", err)
+	}
+	moderationRepo := database.NewModerationRepository()
+	reviewRepo = database.NewReviewRepository(carDB)
+	duplicateWindow := time.Duration(cfg.SpamDuplicateWindowDays) * 24 * time.Hour
+	spamGuard = handlers.NewSpamGuard(antispam.Policy{
+		MinFillTime: time.Duration(cfg.SpamMinFillSeconds) * time.Second,
+		MaxLinks:    cfg.SpamMaxLinks,
+		StopWords:   stopWords,
+	}, antispam.NewFormTokens(formTokenKey), moderationRepo, duplicateWindow, clientIPs)
+
+	// подкоманды CLI (например, `server catalog export`) работают с открытыми БД и завершаются,
+	// не запуская сервер и фоновые задачи; `server openapi` нужен собранный роутер, он ниже
+	if len(os.Args) > 1 && os.Args[1] != "openapi" {
+		code := runCommand(os.Args[1:], catalogIORepo, promoRepo)
+		closeDatabases()
+		os.Exit(code)
+	}
 
-	// подроутер /api
-	api := router.PathPrefix("/api").Subrouter()
+	// фоновые задачи запускаются после сборки роутера (workers.Start) и останавливаются
+	// после HTTP-сервера, чтобы дописать события последних запросов
+	workers := newWorkerGroup()
 
-	// Публичные маршруты (auth и прочее)
-	api.HandleFunc("/health", handlers.HealthCheck).Methods("GET")
-	api.HandleFunc("/register", authHandler.Register).Methods("POST")
-	api.HandleFunc("/login", authHandler.Login).Methods("POST")
-	api.HandleFunc("/validate-password", authHandler.ValidatePassword).Methods("POST")
-	api.HandleFunc("/password-rules", authHandler.PasswordRules).Methods("GET")
+	// события аналитики пишутся в БД пачками, отчёты читают свёртки, пересчитываемые раз в 5 минут
+	workers.Go(analyticsWriter.Run)
+	workers.Go(jobs.NewAnalyticsRollup(analyticsRepo, 5*time.Minute, jobsLog).Run)
 
-	// ОТЗЫВЫ: только сохранение в БД
-	api.HandleFunc("/reviews", createReviewHandler).Methods("POST", "OPTIONS")
+	// применяем запланированные изменения цен раз в минуту
+	workers.Go(jobs.NewPriceScheduler(priceRepo, time.Minute, jobsLog).Run)
 
-	// Отладочные маршруты (как было)
-	api.HandleFunc("/users", authHandler.GetAllUsers).Methods("GET")
+	// публикуем и снимаем с публикации автомобили по расписанию
+	workers.Go(jobs.NewPublishScheduler(publishingRepo, time.Minute, jobsLog).Run)
 
-	// Каталог автомобилей — новые хендлеры на carDB
-	api.HandleFunc("/cars", getAllCarsHandler).Methods("GET")
-	api.HandleFunc("/cars/{id}", getCarByIDHandler).Methods("GET")
-	// если нужно будет фильтровать по категории:
-	// api.HandleFunc("/cars/category/{category}", getCarsByCategoryHandler).Methods("GET")
+	// отпечатки текстов старше окна повторов больше не нужны
+	workers.Go(jobs.NewFingerprintPurge(moderationRepo, duplicateWindow, time.Hour, jobsLog).Run)
 
-	// Каталог автомобилей — публичные GET
-	api.HandleFunc("/cars", getAllCarsHandler).Methods("GET")
-	api.HandleFunc("/cars/{id}", getCarByIDHandler).Methods("GET")
-	// api.HandleFunc("/cars/category/{category}", getCarsByCategoryHandler).Methods("GET")
+	// ---------- Роутер (router.go) ----------
+	build := buildInfo()
+	metrics.RegisterBuildInfo(build.Version, build.Commit)
+	metrics.RegisterDB(carDB, "catalog")
+	metrics.RegisterDB(database.DB, "main")
 
-	// ----- АДМИНСКИЕ РОУТЫ ДЛЯ КАТАЛОГА -----
-	admin := api.PathPrefix("/admin").Subrouter()
+	router, spec, err := newRouter(cfg, logger, workers, clientIPs, build)
+	if err != nil {
+		fatal("failed to build router", err)
+	}
 
-	// защищаем все маршруты /api/admin/...
-	admin.Use(JWTAdminMiddleware)
+	if len(os.Args) > 1 {
+		code := openapiCommand(os.Args[2:], router, spec)
+		closeDatabases()
+		os.Exit(code)
+	}
 
-	admin.HandleFunc("/cars", createCarHandler).Methods("POST")
-	admin.HandleFunc("/cars/{id}", updateCarHandler).Methods("PUT")
-	admin.HandleFunc("/cars/{id}", deleteCarHandler).Methods("DELETE")
+	// новый маршрут без описания (или описание без маршрута) не мешает запуску, но заметен в журнале;
+	// в CI то же проверяет `server openapi check`
+	problems, err := checkAPISpec(router)
+	if err != nil {
+		fatal("failed to walk routes", err)
+	}
+	for _, p := range problems {
+		logger.Warn("openapi: " + p)
+	}
 
-	cartHandler := handlers.NewCartHandler()
+	// пустой каталог заполняем фикстурами dev (fixtures/data)
+	if seeded, err := fixtures.SeedIfEmpty(carDB); err != nil {
+		fatal("failed to seed catalog", err)
+	} else if seeded {
+		logger.Info("catalog seeded with dev fixtures")
+	}
 
-	api.HandleFunc("/cart", cartHandler.GetCart).Methods(http.MethodGet, http.MethodOptions)
-	api.HandleFunc("/cart", cartHandler.AddToCart).Methods(http.MethodPost, http.MethodOptions)
-	api.HandleFunc("/cart", cartHandler.Clear).Methods(http.MethodDelete, http.MethodOptions)
-	api.HandleFunc("/cart/{id}", cartHandler.UpdateQuantity).Methods(http.MethodPatch, http.MethodOptions)
-	api.HandleFunc("/cart/{id}", cartHandler.DeleteItem).Methods(http.MethodDelete, http.MethodOptions)
+	workers.Start()
 
 	// ---------- CORS ----------
-	corsHandler := cors.New(cors.Options{
-		AllowedOrigins:   []string{"*"}, // пока можно так, потом ограничишь
-		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-Requested-With", "X-User-Id"},
-		ExposedHeaders:   []string{"Content-Length"},
-		AllowCredentials: true,
-		MaxAge:           86400,
+	// список источников свой для каждого окружения (CORS_ALLOWED_ORIGINS)
+	corsHandler, err := security.NewCORS(security.CORSOptions{
+		AllowedOrigins:   cfg.CORSAllowedOrigins,
+		AllowCredentials: cfg.CORSAllowCredentials,
 	})
+	if err != nil {
+		fatal("invalid CORS config", err)
+	}
+	if len(cfg.CORSAllowedOrigins) == 0 {
+		logger.Warn("CORS_ALLOWED_ORIGINS is empty, cross-origin browser requests are rejected")
+	}
 
 	addr := ":" + PORT
-	log.Printf("🚗 Renault Backend Server starting on http://localhost%s", addr)
-	log.Printf("📡 API endpoints:")
-	log.Printf("  🔍 GET  http://localhost%s/api/health", addr)
-	log.Printf("  📝 POST http://localhost%s/api/register", addr)
-	log.Printf("  🔑 POST http://localhost%s/api/login", addr)
-	log.Printf("  📊 POST http://localhost%s/api/validate-password", addr)
-	log.Printf("  📋 GET  http://localhost%s/api/password-rules", addr)
-	log.Printf("  👥 GET  http://localhost%s/api/users", addr)
-	log.Println("")
-	log.Println("🔒 Правила паролей:")
-	log.Println("  - Минимум 8 символов")
-	log.Println("  - Хотя бы одна заглавная и строчная буква")
-	log.Println("  - Хотя бы одна цифра")
-	log.Println("  - Хотя бы один специальный символ")
-	log.Println("  - Запрещены простые пароли и последовательности")
 
-	handler := corsHandler.Handler(router)
+	// X-Request-ID и заголовки безопасности снаружи всего остального, чтобы они были и у ответов CORS
+	handler := logging.RequestID(logger, security.Headers(security.HeadersOptions{
+		ContentSecurityPolicy: cfg.ContentSecurityPolicy,
+		HSTSMaxAgeSeconds:     cfg.HSTSMaxAgeSeconds,
+		Proxy:                 clientIPs,
+	}, corsHandler.Handler(router)))
 
-	if err := http.ListenAndServe(addr, handler); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		fatal("invalid TLS config", errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together"))
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		fatal("failed to listen", err)
 	}
-}
 
-// ---------- Работа с БД каталога ----------
+	// SIGINT/SIGTERM: перестаём принимать запросы, дожидаемся начатых,
+	// останавливаем фоновые задачи и закрываем БД
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTimeout := time.Duration(cfg.ShutdownTimeoutSeconds) * time.Second
+	logger.Info("server starting", "addr", addr, "tls", cfg.TLSCertFile != "",
+		"version", build.Version, "commit", build.Commit)
+	serveErr := runServer(ctx, newHTTPServer(cfg, addr, handler), ln, cfg.TLSCertFile, cfg.TLSKeyFile, shutdownTimeout)
+	if serveErr != nil {
+		logger.Error("http server", "err", serveErr)
+	}
 
-func createCarTables() error {
-	_, err := carDB.Exec(`
-        CREATE TABLE IF NOT EXISTS cars (
-            id TEXT PRIMARY KEY,
-            title TEXT NOT NULL,
-            description TEXT,
-            category TEXT,
-            image TEXT,
-            base_price INTEGER
-        );
-
-        CREATE TABLE IF NOT EXISTS car_features (
-            id INTEGER PRIMARY KEY AUTOINCREMENT,
-            car_id TEXT NOT NULL,
-            name TEXT NOT NULL,
-            FOREIGN KEY (car_id) REFERENCES cars(id) ON DELETE CASCADE
-        );
-
-        CREATE TABLE IF NOT EXISTS car_images (
-            id INTEGER PRIMARY KEY AUTOINCREMENT,
-            car_id TEXT NOT NULL,
-            image_path TEXT NOT NULL,
-            FOREIGN KEY (car_id) REFERENCES cars(id) ON DELETE CASCADE
-        );
-		-- ТАБЛИЦА ОТЗЫВОВ
-        CREATE TABLE IF NOT EXISTS reviews (
-            id INTEGER PRIMARY KEY AUTOINCREMENT,
-            email TEXT NOT NULL,
-            model TEXT,
-            rating INTEGER,
-            text TEXT NOT NULL,
-            created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-        );
-
-    `)
+	logger.Info("stopping background workers")
+	if !workers.Stop(shutdownTimeout) {
+		logger.Warn("background workers did not stop in time")
+	}
+	closeDatabases()
+	logger.Info("server stopped")
 
-	return err
+	if serveErr != nil {
+		os.Exit(1)
+	}
 }
 
+// closeDatabases закрывает обе БД; вызывается после остановки сервера и фоновых задач
+func closeDatabases() {
+	if err := carDB.Close(); err != nil {
+		slog.Error("close catalog DB", "err", err)
+	}
+	if err := database.DB.Close(); err != nil {
+		slog.Error("close main DB", "err", err)
+	}
+}
+
+// fatal пишет ошибку запуска в журнал и завершает процесс
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
+}
+
+// ---------- Работа с БД каталога ----------
+
 func createCarHandler(w http.ResponseWriter, r *http.Request) {
 	var c Car
 	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
@@ -276,6 +355,31 @@ func createCarHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// новые модели по умолчанию — черновики, в каталоге они появятся после публикации
+	if c.Status == "" {
+		c.Status = models.CarDraft
+	}
+	if errs := models.ValidateCarPublishing(c.CarPublishing); len(errs) > 0 {
+		http.Error(w, strings.Join(errs, "; "), http.StatusBadRequest)
+		return
+	}
+	c.CarPublishing = c.CarPublishing.InUTC()
+
+	var trashed bool
+	err := carDB.QueryRow(`SELECT deleted_at IS NOT NULL FROM cars WHERE id = ?`, c.ID).Scan(&trashed)
+	if err == nil {
+		msg := "car with this id already exists"
+		if trashed {
+			msg = "car with this id is in trash, restore it instead"
+		}
+		http.Error(w, msg, http.StatusConflict)
+		return
+	}
+	if err != sql.ErrNoRows {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+
 	tx, err := carDB.Begin()
 	if err != nil {
 		http.Error(w, "db error", http.StatusInternalServerError)
@@ -285,14 +389,19 @@ func createCarHandler(w http.ResponseWriter, r *http.Request) {
 
 	// вставляем запись в cars
 	_, err = tx.Exec(`
-        INSERT INTO cars (id, title, description, category, image, base_price)
-        VALUES (?, ?, ?, ?, ?, ?)
-    `, c.ID, c.Title, c.Description, c.Category, c.Image, c.Price)
+        INSERT INTO cars (id, title, description, category, image, base_price, status, publish_at, unpublish_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+    `, c.ID, c.Title, c.Description, c.Category, c.Image, c.Price, c.Status, c.PublishAt, c.UnpublishAt)
 	if err != nil {
 		http.Error(w, "db error: insert car", http.StatusInternalServerError)
 		return
 	}
 
+	if err := database.RecordPriceChange(tx, c.ID, nil, c.Price, handlers.AdminUsername(r), nil); err != nil {
+		http.Error(w, "db error: price history", http.StatusInternalServerError)
+		return
+	}
+
 	// features
 	if len(c.Features) > 0 {
 		stmt, err := tx.Prepare(`INSERT INTO car_features (car_id, name) VALUES (?, ?)`)
@@ -327,14 +436,32 @@ func createCarHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// характеристики
+	if err := database.ReplaceCarSpecs(tx, c.ID, database.SpecKindTech, c.TechSpecs); err != nil {
+		http.Error(w, "db error: insert specs", http.StatusInternalServerError)
+		return
+	}
+	if err := database.ReplaceCarSpecs(tx, c.ID, database.SpecKindEquipment, c.Equipment); err != nil {
+		http.Error(w, "db error: insert specs", http.StatusInternalServerError)
+		return
+	}
+
+	// типизированные характеристики для фильтров выводятся из текстовых
+	if err := database.RefreshCarSpecValues(tx, c.ID); err != nil {
+		http.Error(w, "db error: spec values", http.StatusInternalServerError)
+		return
+	}
+
 	if err := tx.Commit(); err != nil {
 		http.Error(w, "db error: commit", http.StatusInternalServerError)
 		return
 	}
 
+	handlers.SetAuditChange(r, "car.create", "car", c.ID, nil, auditCar(&c))
+
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]string{"status": "created", "id": c.ID})
+	json.NewEncoder(w).Encode(carCreatedResponse{Status: "created", ID: c.ID})
 }
 
 func updateCarHandler(w http.ResponseWriter, r *http.Request) {
@@ -350,6 +477,32 @@ func updateCarHandler(w http.ResponseWriter, r *http.Request) {
 	// на всякий случай принудительно проставим id
 	c.ID = id
 
+	expectedVersion, checkVersion, err := ifMatchVersion(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
+
+	before, err := loadCar(id, false)
+	if err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+	if before == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	// статус не передан — оставляем текущий вместе с расписанием
+	if c.Status == "" {
+		c.CarPublishing = before.CarPublishing
+	}
+	if errs := models.ValidateCarPublishing(c.CarPublishing); len(errs) > 0 {
+		http.Error(w, strings.Join(errs, "; "), http.StatusBadRequest)
+		return
+	}
+	c.CarPublishing = c.CarPublishing.InUTC()
+
 	tx, err := carDB.Begin()
 	if err != nil {
 		http.Error(w, "db error", http.StatusInternalServerError)
@@ -357,16 +510,46 @@ func updateCarHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	defer tx.Rollback()
 
-	// обновляем основную запись
-	_, err = tx.Exec(`
+	// старая цена нужна для истории цен, версия — для проверки If-Match
+	var oldPrice, version int
+	err = tx.QueryRow(`SELECT base_price, version FROM cars WHERE id = ? AND deleted_at IS NULL`, c.ID).
+		Scan(&oldPrice, &version)
+	if err == sql.ErrNoRows {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+	if checkVersion && version != expectedVersion {
+		versionConflict(w, version)
+		return
+	}
+
+	// обновляем основную запись; условие по версии защищает от параллельной записи
+	res, err := tx.Exec(`
         UPDATE cars
-        SET title = ?, description = ?, category = ?, image = ?, base_price = ?
-        WHERE id = ?
-    `, c.Title, c.Description, c.Category, c.Image, c.Price, c.ID)
+        SET title = ?, description = ?, category = ?, image = ?, base_price = ?,
+            status = ?, publish_at = ?, unpublish_at = ?, version = version + 1
+        WHERE id = ? AND version = ?
+    `, c.Title, c.Description, c.Category, c.Image, c.Price, c.Status, c.PublishAt, c.UnpublishAt, c.ID, version)
 	if err != nil {
 		http.Error(w, "db error: update car", http.StatusInternalServerError)
 		return
 	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		versionConflict(w, version)
+		return
+	}
+	c.Version = version + 1
+
+	if oldPrice != c.Price {
+		if err := database.RecordPriceChange(tx, c.ID, &oldPrice, c.Price, handlers.AdminUsername(r), nil); err != nil {
+			http.Error(w, "db error: price history", http.StatusInternalServerError)
+			return
+		}
+	}
 
 	// проще всего – удалить старые features/images и записать новые
 	if _, err := tx.Exec(`DELETE FROM car_features WHERE car_id = ?`, c.ID); err != nil {
@@ -408,195 +591,252 @@ func updateCarHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// характеристики меняем, только если они переданы: admin.html их не отправляет
+	if c.TechSpecs != nil {
+		if err := database.ReplaceCarSpecs(tx, c.ID, database.SpecKindTech, c.TechSpecs); err != nil {
+			http.Error(w, "db error: update specs", http.StatusInternalServerError)
+			return
+		}
+	} else {
+		c.TechSpecs = before.TechSpecs
+	}
+	if c.Equipment != nil {
+		if err := database.ReplaceCarSpecs(tx, c.ID, database.SpecKindEquipment, c.Equipment); err != nil {
+			http.Error(w, "db error: update specs", http.StatusInternalServerError)
+			return
+		}
+	} else {
+		c.Equipment = before.Equipment
+	}
+
+	// типизированные характеристики для фильтров выводятся из текстовых
+	if err := database.RefreshCarSpecValues(tx, c.ID); err != nil {
+		http.Error(w, "db error: spec values", http.StatusInternalServerError)
+		return
+	}
+
 	if err := tx.Commit(); err != nil {
 		http.Error(w, "db error: commit", http.StatusInternalServerError)
 		return
 	}
 
+	handlers.SetAuditChange(r, "car.update", "car", c.ID, auditCar(before), auditCar(&c))
+
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	json.NewEncoder(w).Encode(map[string]string{"status": "updated"})
+	w.Header().Set("ETag", carETag(c.Version))
+	json.NewEncoder(w).Encode(carVersionResponse{Status: "updated", Version: c.Version})
 }
 
-func deleteCarHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id := vars["id"]
+// carPatch — частичное изменение автомобиля: nil-поля не трогаем,
+// features и images меняются поштучно
+type carPatch struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+	Category    *string `json:"category"`
+	Image       *string `json:"image"`
+	Price       *int    `json:"price"`
+
+	AddFeatures    []string `json:"addFeatures"`
+	RemoveFeatures []string `json:"removeFeatures"`
+	AddImages      []string `json:"addImages"`
+	RemoveImages   []string `json:"removeImages"`
+}
 
-	res, err := carDB.Exec(`DELETE FROM cars WHERE id = ?`, id)
-	if err != nil {
-		http.Error(w, "db error", http.StatusInternalServerError)
+// patchCarHandler — PATCH /api/admin/cars/{id}, поддерживает If-Match
+func patchCarHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var p carPatch
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		http.Error(w, "invalid json: "+err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	affected, _ := res.RowsAffected()
-	if affected == 0 {
-		http.Error(w, "not found", http.StatusNotFound)
+	if p.Title != nil && strings.TrimSpace(*p.Title) == "" {
+		http.Error(w, "title cannot be empty", http.StatusBadRequest)
+		return
+	}
+	if p.Price != nil && *p.Price < 0 {
+		http.Error(w, "price cannot be negative", http.StatusBadRequest)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
-}
+	expectedVersion, checkVersion, err := ifMatchVersion(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
 
-func seedCarData() error {
-	var count int
-	if err := carDB.QueryRow("SELECT COUNT(*) FROM cars").Scan(&count); err != nil {
-		return err
+	before, err := loadCar(id, false)
+	if err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
 	}
-	if count > 0 {
-		// уже есть данные — ничего не делаем
-		return nil
+	if before == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
 	}
 
 	tx, err := carDB.Begin()
 	if err != nil {
-		return err
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
 	}
 	defer tx.Rollback()
 
-	// ----- cars -----
-	carsInsert := `
-		INSERT INTO cars (id, title, description, category, image, base_price)
-		VALUES (?, ?, ?, ?, ?, ?);
-	`
-	cars := []struct {
-		ID, Title, Desc, Category, Image string
-		Price                            int
+	var oldPrice, version int
+	err = tx.QueryRow(`SELECT base_price, version FROM cars WHERE id = ? AND deleted_at IS NULL`, id).
+		Scan(&oldPrice, &version)
+	if err == sql.ErrNoRows {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+	if checkVersion && version != expectedVersion {
+		versionConflict(w, version)
+		return
+	}
+
+	set := []string{"version = version + 1"}
+	var args []any
+	for _, f := range []struct {
+		column string
+		value  *string
 	}{
-		{"logan", "Renault Logan", "Надежный седан для города и трассы. Идеальное сочетание цены и качества.",
-			"Легковые", "images/renault_logan.jpeg", 950000},
-		{"sandero", "Renault Sandero", "Компактный хэтчбек с просторным салоном и экономичным двигателем.",
-			"Легковые", "images/renault_sander.jpg", 890000},
-		{"stepway", "Renault Sandero Stepway", "Хэтчбек в кросс-кузове с увеличенным клиренсом и стильным дизайном.",
-			"Легковые", "images/renault_sander_stepway.jpeg", 1100000},
-
-		{"duster", "Renault Duster", "Легендарный внедорожник с полным приводом. Покоритель любых дорог.",
-			"Кроссоверы", "images/duster.jpeg", 1450000},
-		{"kaptur", "Renault Kaptur", "Стильный компактный кроссовер с передовыми технологиями безопасности.",
-			"Кроссоверы", "images/kapture.jpeg", 1350000},
-		{"arkana", "Renault Arkana", "Элегантное кросс-купе с динамичным характером и просторным салоном.",
-			"Кроссоверы", "images/arkana.jpeg", 1650000},
-
-		{"loganvan", "Renault Logan Van", "Коммерческая версия Logan с увеличенным багажным отделением.",
-			"Коммерческие", "images/van.jpeg", 1000000},
-		{"kangoo", "Renault Kangoo", "Компактный коммерческий автомобиль с отличной маневренностью.",
-			"Коммерческие", "images/kangoo.jpeg", 1300000},
-		{"trafic", "Renault Trafic", "Универсальный коммерческий автомобиль для перевозки грузов.",
-			"Коммерческие", "images/trafic.jpg", 1800000},
-
-		{"zoe", "Renault ZOE", "Компактный электромобиль для города с впечатляющим запасом хода.",
-			"Электромобили", "images/zoe.jpeg", 2200000},
-		{"megane", "Renault Megane E-Tech", "Современный электрокроссовер с технологиями нового поколения.",
-			"Электромобили", "images/megane e.jpg", 3500000},
-		{"captur", "Renault Captur E-Tech", "Гибридный кроссовер с экономичным расходом и отличной динамикой.",
-			"Гибриды", "images/captur e.jpg", 1900000},
-	}
-
-	for _, c := range cars {
-		if _, err := tx.Exec(carsInsert, c.ID, c.Title, c.Desc, c.Category, c.Image, c.Price); err != nil {
-			return err
+		{"title", p.Title},
+		{"description", p.Description},
+		{"category", p.Category},
+		{"image", p.Image},
+	} {
+		if f.value != nil {
+			set = append(set, f.column+" = ?")
+			args = append(args, *f.value)
 		}
 	}
+	if p.Price != nil {
+		set = append(set, "base_price = ?")
+		args = append(args, *p.Price)
+	}
+	args = append(args, id, version)
 
-	// ----- car_features -----
-	featuresInsert := `INSERT INTO car_features (car_id, name) VALUES (?, ?);`
-	features := map[string][]string{
-		"logan": {
-			"Расход: 6.1 л/100км",
-			"Мощность: 82 л.с.",
-			"Объем багажника: 510 л",
-		},
-		"sandero": {
-			"Расход: 5.8 л/100км",
-			"Мощность: 75 л.с.",
-			"5-ступенчатая МКПП",
-		},
-		"stepway": {
-			"Клиренс: 195 мм",
-			"Мощность: 90 л.с.",
-			"Защита бампера",
-		},
-		"duster": {
-			"Полный привод 4x4",
-			"Мощность: 114 л.с.",
-			"Клиренс: 210 мм",
-		},
-		"kaptur": {
-			"Система ESP",
-			"Мощность: 113 л.с.",
-			"Мультимедиа R-Link",
-		},
-		"arkana": {
-			"Купе-форма",
-			"Мощность: 150 л.с.",
-			"Вариатор X-Tronic",
-		},
-		"loganvan": {
-			"Объем багажника: 800 л",
-			"Грузоподъемность: 500 кг",
-			"Низкий расход топлива",
-		},
-		"kangoo": {
-			"Объем: 3-4.6 м³",
-			"Грузоподъемность: 650 кг",
-			"Сдвижные двери",
-		},
-		"trafic": {
-			"Объем: 5.2-8.6 м³",
-			"Грузоподъемность: 1-1.5 т",
-			"Дизельный двигатель",
-		},
-		"zoe": {
-			"Запас хода: 395 км",
-			"Мощность: 135 л.с.",
-			"Быстрая зарядка за 30 мин",
-		},
-		"megane": {
-			"Запас хода: 470 км",
-			"Мощность: 220 л.с.",
-			"Цифровая панель 12,3\"",
-		},
-		"captur": {
-			"Гибридная система",
-			"Расход: 4.5 л/100км",
-			"Электро-привод на малых скоростях",
-		},
-	}
-
-	imagesInsert := `INSERT INTO car_images (car_id, image_path) VALUES (?, ?);`
-
-	carImages := map[string][]string{
-		"logan": {
-			"images/renault_logan.jpeg",
-			"images/renault_logan_2.jpg",
-			"images/renaul_logan_3.jpg",
-		},
-		"sandero": {
-			"images/renault_sander.jpg",
-			"images/renault_sandero2.jpg",
-		},
-		"stepway": {
-			"images/renault_sander_stepway.jpeg",
-			"images/renault_sandero_stepway2.jpg",
-		},
-	}
-
-	for carID, imgs := range carImages {
-		for _, path := range imgs {
-			if _, err := tx.Exec(imagesInsert, carID, path); err != nil {
-				return err
-			}
+	res, err := tx.Exec(`UPDATE cars SET `+strings.Join(set, ", ")+` WHERE id = ? AND version = ?`, args...)
+	if err != nil {
+		http.Error(w, "db error: update car", http.StatusInternalServerError)
+		return
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		versionConflict(w, version)
+		return
+	}
+
+	if p.Price != nil && *p.Price != oldPrice {
+		if err := database.RecordPriceChange(tx, id, &oldPrice, *p.Price, handlers.AdminUsername(r), nil); err != nil {
+			http.Error(w, "db error: price history", http.StatusInternalServerError)
+			return
 		}
 	}
 
-	for carID, list := range features {
-		for _, f := range list {
-			if _, err := tx.Exec(featuresInsert, carID, f); err != nil {
-				return err
+	for _, q := range []struct {
+		query  string
+		values []string
+	}{
+		{`DELETE FROM car_features WHERE car_id = ? AND name = ?`, p.RemoveFeatures},
+		{`DELETE FROM car_images WHERE car_id = ? AND image_path = ?`, p.RemoveImages},
+		{`INSERT INTO car_features (car_id, name)
+		  SELECT ?1, ?2 WHERE NOT EXISTS (SELECT 1 FROM car_features WHERE car_id = ?1 AND name = ?2)`, p.AddFeatures},
+		{`INSERT INTO car_images (car_id, image_path)
+		  SELECT ?1, ?2 WHERE NOT EXISTS (SELECT 1 FROM car_images WHERE car_id = ?1 AND image_path = ?2)`, p.AddImages},
+	} {
+		for _, v := range q.values {
+			if _, err := tx.Exec(q.query, id, v); err != nil {
+				http.Error(w, "db error: features/images", http.StatusInternalServerError)
+				return
 			}
 		}
 	}
 
-	return tx.Commit()
+	// типизированные характеристики для фильтров выводятся из текстовых
+	if err := database.RefreshCarSpecValues(tx, id); err != nil {
+		http.Error(w, "db error: spec values", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		http.Error(w, "db error: commit", http.StatusInternalServerError)
+		return
+	}
+
+	after, err := loadCar(id, false)
+	if err != nil || after == nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+	handlers.SetAuditChange(r, "car.patch", "car", id, auditCar(before), auditCar(after))
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("ETag", carETag(after.Version))
+	json.NewEncoder(w).Encode(after)
+}
+
+func carETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatchVersion разбирает заголовок If-Match; check=false — заголовка нет или он равен "*"
+func ifMatchVersion(r *http.Request) (version int, check bool, err error) {
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	if v == "" || v == "*" {
+		return 0, false, nil
+	}
+	v = strings.Trim(strings.TrimPrefix(v, "W/"), `"`)
+	version, err = strconv.Atoi(v)
+	if err != nil {
+		return 0, false, fmt.Errorf("If-Match does not match any version of this car")
+	}
+	return version, true, nil
+}
+
+// versionConflict — автомобиль успел изменить кто-то другой
+func versionConflict(w http.ResponseWriter, current int) {
+	w.Header().Set("ETag", carETag(current))
+	http.Error(w, "car was modified by someone else, reload and try again", http.StatusPreconditionFailed)
+}
+
+func deleteCarHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	before, err := loadCar(id, false)
+	if err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+
+	// мягкое удаление: автомобиль пропадает из каталога, но его можно восстановить
+	// из корзины, пока он не удалён окончательно (см. jobs.CarTrashPurge)
+	res, err := carDB.Exec(`
+        UPDATE cars SET deleted_at = ?, deleted_by = ?, version = version + 1
+        WHERE id = ? AND deleted_at IS NULL
+    `, time.Now().UTC(), handlers.AdminUsername(r), id)
+	if err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+
+	affected, _ := res.RowsAffected()
+	if affected == 0 {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	handlers.SetAuditChange(r, "car.delete", "car", id, auditCar(before), nil)
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(handlers.StatusResponse{Status: "deleted"})
 }
 
 func getCarImages(carID string) ([]string, error) {
@@ -619,111 +859,284 @@ func getCarImages(carID string) ([]string, error) {
 
 // ---------- HTTP-хендлеры каталога ----------
 
+// публичный каталог показывает только опубликованные и не удалённые автомобили
+const publicCarsFilter = `deleted_at IS NULL AND status = 'published'`
+
+// getAllCarsHandler — публичный каталог; фильтры по категории, цене и типизированным характеристикам:
+// ?category=Кроссоверы&price_min=1000000&power_hp_min=110&drive_type=awd (см. models.ParseCatalogFilter)
 func getAllCarsHandler(w http.ResponseWriter, r *http.Request) {
-	rows, err := carDB.Query(`SELECT id, title, description, category, image, base_price FROM cars`)
+	filter, errs := models.ParseCatalogFilter(r.URL.Query())
+	if len(errs) > 0 {
+		http.Error(w, strings.Join(errs, "; "), http.StatusBadRequest)
+		return
+	}
+
+	where := publicCarsFilter
+	filterWhere, args := database.CatalogFilterSQL(filter)
+	if filterWhere != "" {
+		where += ` AND ` + filterWhere
+	}
+
+	cars, err := queryCars(where, args...)
+	if err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := applyCatalogPromotions(cars); err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(cars)
+}
+
+// specSchemaHandler — GET /api/cars/spec-schema: ключи, единицы и значения фильтров
+func specSchemaHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(models.SpecSchema)
+}
+
+// adminListCarsHandler — все автомобили, кроме удалённых, в любом статусе
+func adminListCarsHandler(w http.ResponseWriter, r *http.Request) {
+	where := `deleted_at IS NULL`
+	var args []any
+	if status := r.URL.Query().Get("status"); status != "" {
+		where += ` AND status = ?`
+		args = append(args, status)
+	}
+
+	cars, err := queryCars(where, args...)
 	if err != nil {
 		http.Error(w, "db error", http.StatusInternalServerError)
 		return
 	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(cars)
+}
+
+const carColumns = `id, title, description, category, image, base_price, status, publish_at, unpublish_at, version`
+
+func scanCar(row interface{ Scan(...any) error }) (*Car, error) {
+	var c Car
+	var publishAt, unpublishAt sql.NullTime
+	err := row.Scan(&c.ID, &c.Title, &c.Description, &c.Category, &c.Image, &c.Price,
+		&c.Status, &publishAt, &unpublishAt, &c.Version)
+	if err != nil {
+		return nil, err
+	}
+	if publishAt.Valid {
+		c.PublishAt = &publishAt.Time
+	}
+	if unpublishAt.Valid {
+		c.UnpublishAt = &unpublishAt.Time
+	}
+	c.Model = c.Title
+	return &c, nil
+}
+
+// queryCars выбирает автомобили по условию where вместе с features и images
+func queryCars(where string, args ...any) ([]Car, error) {
+	rows, err := carDB.Query(`SELECT `+carColumns+` FROM cars WHERE `+where, args...)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
 	var cars []Car
-
 	for rows.Next() {
-		var c Car
-		if err := rows.Scan(&c.ID, &c.Title, &c.Description, &c.Category, &c.Image, &c.Price); err != nil {
-			http.Error(w, "scan error", http.StatusInternalServerError)
-			return
+		c, err := scanCar(rows)
+		if err != nil {
+			return nil, err
 		}
-		c.Model = c.Title
+		cars = append(cars, *c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
 
-		// подгружаем features
-		featRows, err := carDB.Query(`SELECT name FROM car_features WHERE car_id = ?`, c.ID)
-		if err != nil {
-			http.Error(w, "db error", http.StatusInternalServerError)
-			return
+	for i := range cars {
+		c := &cars[i]
+		if c.Features, err = getCarFeatures(c.ID); err != nil {
+			return nil, err
 		}
-		for featRows.Next() {
-			var name string
-			if err := featRows.Scan(&name); err != nil {
-				http.Error(w, "scan error", http.StatusInternalServerError)
-				featRows.Close()
-				return
-			}
-			c.Features = append(c.Features, name)
+		if c.Images, err = getCarImages(c.ID); err != nil {
+			return nil, err
 		}
-		featRows.Close()
-
-		// подгружаем изображения
-		imgs, err := getCarImages(c.ID)
-		if err != nil {
-			http.Error(w, "db error", http.StatusInternalServerError)
-			return
+		if err := loadCarSpecs(c); err != nil {
+			return nil, err
 		}
 		// если в таблице нет записей, хотя бы главное изображение
-		if len(imgs) == 0 && c.Image != "" {
-			imgs = []string{c.Image}
+		if len(c.Images) == 0 && c.Image != "" {
+			c.Images = []string{c.Image}
 		}
-		c.Images = imgs
+	}
+	return cars, nil
+}
 
-		cars = append(cars, c)
+func getCarFeatures(carID string) ([]string, error) {
+	rows, err := carDB.Query(`SELECT name FROM car_features WHERE car_id = ?`, carID)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	json.NewEncoder(w).Encode(cars)
+	var features []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		features = append(features, name)
+	}
+	return features, rows.Err()
 }
 
-func getCarByIDHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id := vars["id"]
+// applyCatalogPromotions проставляет цену со скидкой по акциям без промокода
+func applyCatalogPromotions(cars []Car) error {
+	promos, err := promoRepo.Automatic(time.Now())
+	if err != nil {
+		return err
+	}
+	for i := range cars {
+		cars[i].DiscountedPrice, cars[i].Promotions = models.PriceForCar(
+			cars[i].ID, cars[i].Category, cars[i].Price, promos)
+	}
+	return nil
+}
 
-	var c Car
-	err := carDB.QueryRow(
-		`SELECT id, title, description, category, image, base_price FROM cars WHERE id = ?`,
-		id,
-	).Scan(&c.ID, &c.Title, &c.Description, &c.Category, &c.Image, &c.Price)
+// loadCar читает автомобиль с features и images как они лежат в БД;
+// nil — если его нет, он в корзине или (при onlyPublished) не опубликован
+func loadCar(id string, onlyPublished bool) (*Car, error) {
+	where := `id = ? AND deleted_at IS NULL`
+	if onlyPublished {
+		where = `id = ? AND ` + publicCarsFilter
+	}
+
+	c, err := scanCar(carDB.QueryRow(`SELECT `+carColumns+` FROM cars WHERE `+where, id))
 	if err == sql.ErrNoRows {
-		http.Error(w, "not found", http.StatusNotFound)
-		return
+		return nil, nil
 	}
 	if err != nil {
-		http.Error(w, "db error", http.StatusInternalServerError)
-		return
+		return nil, err
 	}
 
-	c.Model = c.Title
+	if c.Features, err = getCarFeatures(c.ID); err != nil {
+		return nil, err
+	}
+	if c.Images, err = getCarImages(c.ID); err != nil {
+		return nil, err
+	}
+	if err := loadCarSpecs(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
 
-	featRows, err := carDB.Query(`SELECT name FROM car_features WHERE car_id = ?`, c.ID)
+func loadCarSpecs(c *Car) error {
+	var err error
+	if c.TechSpecs, err = database.LoadCarSpecs(carDB, c.ID, database.SpecKindTech); err != nil {
+		return err
+	}
+	if c.Equipment, err = database.LoadCarSpecs(carDB, c.ID, database.SpecKindEquipment); err != nil {
+		return err
+	}
+	c.Specs, err = database.LoadCarSpecValues(carDB, c.ID)
+	return err
+}
+
+// carAuditSnapshot — поля автомобиля, которые хранятся в каталоге (для журнала аудита)
+type carAuditSnapshot struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Category    string   `json:"category"`
+	Image       string   `json:"image"`
+	Price       int      `json:"price"`
+	Features    []string `json:"features"`
+	Images      []string `json:"images"`
+	TechSpecs   []Spec   `json:"techSpecs"`
+	Equipment   []Spec   `json:"equipment"`
+	models.CarPublishing
+}
+
+func auditCar(c *Car) any {
+	if c == nil {
+		return nil
+	}
+	return carAuditSnapshot{
+		ID: c.ID, Title: c.Title, Description: c.Description, Category: c.Category,
+		Image: c.Image, Price: c.Price, Features: c.Features, Images: c.Images,
+		TechSpecs: c.TechSpecs, Equipment: c.Equipment, CarPublishing: c.CarPublishing,
+	}
+}
+
+func getCarByIDHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	c, err := loadCar(id, true)
 	if err != nil {
 		http.Error(w, "db error", http.StatusInternalServerError)
 		return
 	}
-	for featRows.Next() {
-		var name string
-		if err := featRows.Scan(&name); err != nil {
-			http.Error(w, "scan error", http.StatusInternalServerError)
-			featRows.Close()
-			return
-		}
-		c.Features = append(c.Features, name)
+	if c == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	analyticsEvents.Record(models.AnalyticsEvent{Type: models.EventCarViewed, CarID: c.ID, UserID: r.Header.Get("X-User-Id")})
+	writeCarDetails(w, c)
+}
+
+// previewCarHandler — карточка автомобиля в любом статусе по подписанной ссылке
+// из POST /api/admin/cars/{id}/preview-link
+func previewCarHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	expires, _ := strconv.ParseInt(r.URL.Query().Get("expires"), 10, 64)
+	if !handlers.VerifyCarPreview(previewLinkKey, id, expires, r.URL.Query().Get("sig"), time.Now()) {
+		http.Error(w, "invalid or expired preview link", http.StatusForbidden)
+		return
 	}
-	featRows.Close()
 
-	imgs, err := getCarImages(c.ID)
+	c, err := loadCar(id, false)
 	if err != nil {
 		http.Error(w, "db error", http.StatusInternalServerError)
 		return
 	}
-	if len(imgs) == 0 && c.Image != "" {
-		imgs = []string{c.Image}
+	if c == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
 	}
-	c.Images = imgs
+	w.Header().Set("Cache-Control", "no-store")
+	writeCarDetails(w, c)
+}
 
-	c.TechSpecs = []Spec{}
-	c.Equipment = []Spec{}
+// writeCarDetails отдаёт карточку автомобиля с ценой по акциям
+func writeCarDetails(w http.ResponseWriter, c *Car) {
+	if len(c.Images) == 0 && c.Image != "" {
+		c.Images = []string{c.Image}
+	}
+
+	if c.TechSpecs == nil {
+		c.TechSpecs = []Spec{}
+	}
+	if c.Equipment == nil {
+		c.Equipment = []Spec{}
+	}
+
+	priced := []Car{*c}
+	if err := applyCatalogPromotions(priced); err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
 
+	w.Header().Set("ETag", carETag(c.Version))
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	json.NewEncoder(w).Encode(c)
+	json.NewEncoder(w).Encode(priced[0])
 }
 
 // опционально — фильтр по категории, если понадобится
@@ -731,73 +1144,53 @@ func getCarsByCategoryHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	category := vars["category"]
 
-	rows, err := carDB.Query(
-		`SELECT id, title, description, category, image, base_price FROM cars WHERE category = ?`,
-		category,
-	)
+	cars, err := queryCars(`category = ? AND `+publicCarsFilter, category)
 	if err != nil {
 		http.Error(w, "db error", http.StatusInternalServerError)
 		return
 	}
-	defer rows.Close()
 
-	var cars []Car
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(cars)
+}
 
-	for rows.Next() {
-		var c Car
-		if err := rows.Scan(&c.ID, &c.Title, &c.Description, &c.Category, &c.Image, &c.Price); err != nil {
-			http.Error(w, "scan error", http.StatusInternalServerError)
-			return
-		}
-		c.Model = c.Title
+var (
+	errMissingBearer = errors.New("missing or invalid Authorization header")
+	errInvalidToken  = errors.New("invalid token")
+	errInvalidClaims = errors.New("invalid token claims")
+)
 
-		featRows, err := carDB.Query(`SELECT name FROM car_features WHERE car_id = ?`, c.ID)
-		if err != nil {
-			http.Error(w, "db error", http.StatusInternalServerError)
-			return
-		}
-		for featRows.Next() {
-			var name string
-			if err := featRows.Scan(&name); err != nil {
-				http.Error(w, "scan error", http.StatusInternalServerError)
-				featRows.Close()
-				return
-			}
-			c.Features = append(c.Features, name)
-		}
-		featRows.Close()
+// bearerClaims разбирает и проверяет JWT из заголовка Authorization
+func bearerClaims(r *http.Request) (jwt.MapClaims, error) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
+		return nil, errMissingBearer
+	}
 
-		cars = append(cars, c)
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, http.ErrAbortHandler
+		}
+		return []byte(JWT_SECRET), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, errInvalidToken
 	}
 
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	json.NewEncoder(w).Encode(cars)
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errInvalidClaims
+	}
+	return claims, nil
 }
 
 func JWTAdminMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
-			http.Error(w, "missing or invalid Authorization header", http.StatusUnauthorized)
-			return
-		}
-
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, http.ErrAbortHandler
-			}
-			return []byte(JWT_SECRET), nil
-		})
-		if err != nil || !token.Valid {
-			http.Error(w, "invalid token", http.StatusUnauthorized)
-			return
-		}
-
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			http.Error(w, "invalid token claims", http.StatusUnauthorized)
+		claims, err := bearerClaims(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
 			return
 		}
 
@@ -807,6 +1200,22 @@ func JWTAdminMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
+		username, _ := claims["username"].(string)
+		logging.SetUser(r.Context(), username)
+		next.ServeHTTP(w, r.WithContext(handlers.WithAdminUsername(r.Context(), username)))
+	})
+}
+
+// JWTUserMiddleware — необязательный вход: с действительным JWT имя пользователя попадает
+// в контекст (handlers.Username), без него или с негодным токеном запрос идёт как анонимный
+func JWTUserMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if claims, err := bearerClaims(r); err == nil {
+			if username, _ := claims["username"].(string); username != "" {
+				logging.SetUser(r.Context(), username)
+				r = r.WithContext(handlers.WithUsername(r.Context(), username))
+			}
+		}
 		next.ServeHTTP(w, r)
 	})
 }
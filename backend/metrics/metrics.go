@@ -0,0 +1,105 @@
+// Package metrics собирает метрики сервера в формате Prometheus: HTTP-запросы по шаблонам
+// маршрутов gorilla/mux, пулы соединений БД и сведения о сборке
+package metrics
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"renault-backend/logging"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// маршрут для запросов, не совпавших ни с одним шаблоном: путь в метку не пишем,
+// иначе случайные URL раздуют число временных рядов
+const unmatchedRoute = "unmatched"
+
+var (
+	registry = prometheus.NewRegistry()
+
+	requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP-запросы по шаблону маршрута, методу и коду ответа.",
+	}, []string{"route", "method", "code"})
+
+	duration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Время обработки HTTP-запросов по шаблону маршрута и методу.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	inFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "Запросы, обрабатываемые прямо сейчас.",
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		requests, duration, inFlight,
+	)
+}
+
+// RegisterDB добавляет статистику пула соединений db (метки db_name=name)
+func RegisterDB(db *sql.DB, name string) {
+	registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterBuildInfo публикует версию и коммит сборки как build_info{version, commit} 1
+func RegisterBuildInfo(version, commit string) {
+	info := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:        "build_info",
+		Help:        "Версия и коммит сборки сервера.",
+		ConstLabels: prometheus.Labels{"version": version, "commit": commit},
+	})
+	info.Set(1)
+	registry.MustRegister(info)
+}
+
+// Handler отдаёт метрики для GET /metrics только с заголовком Authorization: Bearer <token>
+// (в Prometheus — authorization.credentials); пустой token закрывает метрики совсем
+func Handler(token string) http.Handler {
+	h := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// Middleware — middleware для mux.Router: считает запросы и время ответа
+// по шаблону маршрута (/api/cars/{id}), а не по фактическому пути
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := unmatchedRoute
+		if cur := mux.CurrentRoute(r); cur != nil {
+			if tpl, err := cur.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		rec := logging.NewResponseRecorder(w)
+		start := time.Now()
+		next.ServeHTTP(rec, r)
+
+		duration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+		requests.WithLabelValues(route, r.Method, strconv.Itoa(rec.Status)).Inc()
+	})
+}
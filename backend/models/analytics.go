@@ -0,0 +1,102 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// типы событий аналитики
+const (
+	EventCarViewed       = "car_viewed"
+	EventAddedToCart     = "added_to_cart"
+	EventReviewSubmitted = "review_submitted"
+	EventLeadCreated     = "lead_created"
+)
+
+// AnalyticsEvent — событие поведения покупателя
+type AnalyticsEvent struct {
+	Type       string    `json:"type"`
+	CarID      string    `json:"carId,omitempty"`
+	UserID     string    `json:"userId,omitempty"` // X-User-Id, если покупатель известен
+	Rating     int       `json:"rating,omitempty"` // оценка отзыва 1–5, 0 — без оценки
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+// Validate проверяет событие; отзыв может быть не привязан к автомобилю каталога
+func (e AnalyticsEvent) Validate() error {
+	switch e.Type {
+	case EventCarViewed, EventAddedToCart, EventLeadCreated:
+		if e.CarID == "" {
+			return fmt.Errorf("%s: carId обязателен", e.Type)
+		}
+	case EventReviewSubmitted:
+	default:
+		return fmt.Errorf("неизвестный тип события %q", e.Type)
+	}
+	if e.Rating < 0 || e.Rating > 5 {
+		return fmt.Errorf("rating должен быть от 0 до 5")
+	}
+	return nil
+}
+
+// AnalyticsRange — период отчёта по дням включительно, даты в формате 2006-01-02 (UTC)
+type AnalyticsRange struct {
+	From string
+	To   string
+}
+
+// CarViewsDay — просмотры модели за день
+type CarViewsDay struct {
+	Day   string `json:"day"`
+	CarID string `json:"carId"`
+	Title string `json:"title"`
+	Views int    `json:"views"`
+}
+
+// FunnelStep — счётчики воронки «просмотр → корзина → заявка»
+type FunnelStep struct {
+	CarID      string  `json:"carId,omitempty"`
+	Title      string  `json:"title,omitempty"`
+	Views      int     `json:"views"`
+	CartAdds   int     `json:"cartAdds"`
+	Leads      int     `json:"leads"`
+	ViewToCart float64 `json:"viewToCart"` // доля добавлений в корзину от просмотров
+	CartToLead float64 `json:"cartToLead"` // доля заявок от добавлений в корзину
+}
+
+// WithRates считает конверсии по счётчикам
+func (s FunnelStep) WithRates() FunnelStep {
+	s.ViewToCart, s.CartToLead = 0, 0
+	if s.Views > 0 {
+		s.ViewToCart = float64(s.CartAdds) / float64(s.Views)
+	}
+	if s.CartAdds > 0 {
+		s.CartToLead = float64(s.Leads) / float64(s.CartAdds)
+	}
+	return s
+}
+
+// ConversionFunnel — воронка за период: итог и по моделям
+type ConversionFunnel struct {
+	From  string       `json:"from"`
+	To    string       `json:"to"`
+	Total FunnelStep   `json:"total"`
+	ByCar []FunnelStep `json:"byCar"`
+}
+
+// CategoryStats — интерес к категории за период
+type CategoryStats struct {
+	Category string `json:"category"`
+	Views    int    `json:"views"`
+	CartAdds int    `json:"cartAdds"`
+	Leads    int    `json:"leads"`
+}
+
+// RatingsDay — отзывы за день: средняя оценка и распределение по звёздам
+type RatingsDay struct {
+	Day          string      `json:"day"`
+	Reviews      int         `json:"reviews"` // все отзывы, включая без оценки
+	Rated        int         `json:"rated"`
+	Average      float64     `json:"average"`
+	Distribution map[int]int `json:"distribution"` // оценка → число отзывов
+}
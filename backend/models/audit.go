@@ -0,0 +1,82 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// AuditEntry — запись журнала действий администраторов (только добавление)
+type AuditEntry struct {
+	ID       int             `json:"id"`
+	Actor    string          `json:"actor"`
+	Action   string          `json:"action"` // например, car.update или promotions.create
+	Entity   string          `json:"entity"`
+	EntityID string          `json:"entityId,omitempty"`
+	Before   json.RawMessage `json:"before,omitempty"`
+	After    json.RawMessage `json:"after,omitempty"`
+	Diff     json.RawMessage `json:"diff,omitempty"` // {"поле": {"before": …, "after": …}}
+	IP       string          `json:"ip"`
+	Path     string          `json:"path"`
+	Status   int             `json:"status"`
+	At       time.Time       `json:"at"`
+}
+
+// AuditFilter — фильтры для GET /api/admin/audit
+type AuditFilter struct {
+	Actor    string
+	Action   string
+	Entity   string
+	EntityID string
+	From     *time.Time
+	To       *time.Time
+	Limit    int
+	Offset   int
+}
+
+// FieldChange — изменение одного поля
+type FieldChange struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// AuditDiff сравнивает два JSON-объекта по полям верхнего уровня.
+// Для создания/удаления (одного из снимков нет) и не-объектов возвращает nil.
+func AuditDiff(before, after json.RawMessage) json.RawMessage {
+	var b, a map[string]json.RawMessage
+	if json.Unmarshal(before, &b) != nil || json.Unmarshal(after, &a) != nil || b == nil || a == nil {
+		return nil
+	}
+
+	null := json.RawMessage("null")
+	diff := map[string]FieldChange{}
+	for k, bv := range b {
+		av, ok := a[k]
+		if !ok {
+			av = null
+		}
+		if !jsonEqual(bv, av) {
+			diff[k] = FieldChange{Before: bv, After: av}
+		}
+	}
+	for k, av := range a {
+		if _, ok := b[k]; !ok && !jsonEqual(null, av) {
+			diff[k] = FieldChange{Before: null, After: av}
+		}
+	}
+
+	out, err := json.Marshal(diff)
+	if err != nil {
+		return nil
+	}
+	return out
+}
+
+// jsonEqual сравнивает значения без учёта форматирования
+func jsonEqual(x, y json.RawMessage) bool {
+	var cx, cy bytes.Buffer
+	if json.Compact(&cx, x) != nil || json.Compact(&cy, y) != nil {
+		return bytes.Equal(x, y)
+	}
+	return bytes.Equal(cx.Bytes(), cy.Bytes())
+}
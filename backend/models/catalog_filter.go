@@ -0,0 +1,137 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// CatalogFilter — состояние фильтров публичного каталога (общие для /api/cars и /api/cars/facets)
+type CatalogFilter struct {
+	Categories []string     // ?category=Кроссоверы,Гибриды
+	PriceMin   *int         // ?price_min=
+	PriceMax   *int         // ?price_max=
+	Specs      []SpecFilter // см. ParseSpecFilters
+}
+
+// ParseCatalogFilter читает фильтры каталога из query-параметров
+func ParseCatalogFilter(q url.Values) (CatalogFilter, []string) {
+	var f CatalogFilter
+	var errs []string
+
+	for _, c := range strings.Split(q.Get("category"), ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			f.Categories = append(f.Categories, c)
+		}
+	}
+	for _, bound := range []struct {
+		param string
+		dst   **int
+	}{{"price_min", &f.PriceMin}, {"price_max", &f.PriceMax}} {
+		raw := q.Get(bound.param)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s должен быть целым числом", bound.param))
+			continue
+		}
+		*bound.dst = &v
+	}
+
+	specs, specErrs := ParseSpecFilters(q)
+	f.Specs = specs
+	return f, append(errs, specErrs...)
+}
+
+// Without возвращает копию фильтра без условия facet (category, price или ключ характеристики):
+// счётчики фасета считаются по остальным фильтрам, чтобы были видны альтернативы
+func (f CatalogFilter) Without(facet string) CatalogFilter {
+	switch facet {
+	case FacetCategory:
+		f.Categories = nil
+	case FacetPrice:
+		f.PriceMin, f.PriceMax = nil, nil
+	default:
+		specs := make([]SpecFilter, 0, len(f.Specs))
+		for _, s := range f.Specs {
+			if s.Key != facet {
+				specs = append(specs, s)
+			}
+		}
+		f.Specs = specs
+	}
+	return f
+}
+
+// Selected — выбрано ли значение фасета в текущем фильтре
+func (f CatalogFilter) Selected(facet, value string) bool {
+	var values []string
+	if facet == FacetCategory {
+		values = f.Categories
+	}
+	for _, s := range f.Specs {
+		if s.Key == facet {
+			values = s.Values
+		}
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	FacetCategory = "category"
+	FacetPrice    = "price"
+)
+
+// FacetSpecKeys — перечислимые характеристики, по которым строятся фасеты
+var FacetSpecKeys = []string{"drive_type", "fuel_type", "transmission"}
+
+// PriceBucket — ценовой диапазон [Min, Max); Max == nil — без верхней границы
+type PriceBucket struct {
+	Label    string `json:"label"`
+	Min      int    `json:"min"`
+	Max      *int   `json:"max,omitempty"`
+	Count    int    `json:"count"`
+	Selected bool   `json:"selected"`
+}
+
+func priceBound(v int) *int { return &v }
+
+// PriceBuckets — диапазоны цен для фасета, по base_price
+var PriceBuckets = []PriceBucket{
+	{Label: "до 1 млн ₽", Min: 0, Max: priceBound(1_000_000)},
+	{Label: "1–1,5 млн ₽", Min: 1_000_000, Max: priceBound(1_500_000)},
+	{Label: "1,5–2 млн ₽", Min: 1_500_000, Max: priceBound(2_000_000)},
+	{Label: "2–3 млн ₽", Min: 2_000_000, Max: priceBound(3_000_000)},
+	{Label: "от 3 млн ₽", Min: 3_000_000},
+}
+
+// FacetValue — значение фасета и число автомобилей с ним
+type FacetValue struct {
+	Value    string `json:"value"`
+	Label    string `json:"label"`
+	Count    int    `json:"count"`
+	Selected bool   `json:"selected"`
+}
+
+// PriceFacet — границы цен и диапазоны
+type PriceFacet struct {
+	Min     int           `json:"min"`
+	Max     int           `json:"max"`
+	Buckets []PriceBucket `json:"buckets"`
+}
+
+// CatalogFacets — ответ GET /api/cars/facets
+type CatalogFacets struct {
+	Total      int                     `json:"total"` // автомобилей со всеми фильтрами
+	Categories []FacetValue            `json:"category"`
+	Price      PriceFacet              `json:"price"`
+	Specs      map[string][]FacetValue `json:"specs"` // drive_type, fuel_type, transmission
+}
@@ -0,0 +1,275 @@
+package models
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CatalogSpec — характеристика (техническая или комплектация)
+type CatalogSpec struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// CatalogCar — автомобиль в формате выгрузки/загрузки каталога
+type CatalogCar struct {
+	ID          string        `json:"id"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Category    string        `json:"category"`
+	Image       string        `json:"image"`
+	Price       int           `json:"price"`
+	Status      string        `json:"status,omitempty"` // пусто — оставить текущий (для новых — draft)
+	Features    []string      `json:"features"`
+	Images      []string      `json:"images"`
+	TechSpecs   []CatalogSpec `json:"techSpecs"`
+	Equipment   []CatalogSpec `json:"equipment"`
+}
+
+// CatalogExport — полная выгрузка каталога
+type CatalogExport struct {
+	ExportedAt time.Time    `json:"exportedAt"`
+	Categories []string     `json:"categories"`
+	Cars       []CatalogCar `json:"cars"`
+}
+
+const (
+	ImportCreate    = "create"
+	ImportUpdate    = "update"
+	ImportUnchanged = "unchanged"
+	ImportError     = "error"
+)
+
+// ImportOptions — режим загрузки
+type ImportOptions struct {
+	DryRun bool   // только посчитать изменения, ничего не записывать
+	Atomic bool   // всё или ничего: при любой ошибке не применяется ни одна строка
+	Author string // кто загружает — попадает в историю цен
+}
+
+// ImportRowResult — результат по одной строке файла
+type ImportRowResult struct {
+	Row    int             `json:"row"` // номер строки (CSV) или элемента массива (JSON), с 1
+	ID     string          `json:"id"`
+	Action string          `json:"action"`
+	Diff   json.RawMessage `json:"diff,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// ImportReport — итог загрузки
+type ImportReport struct {
+	DryRun    bool              `json:"dryRun"`
+	Atomic    bool              `json:"atomic"`
+	Applied   bool              `json:"applied"` // изменения записаны в БД
+	Created   int               `json:"created"`
+	Updated   int               `json:"updated"`
+	Unchanged int               `json:"unchanged"`
+	Failed    int               `json:"failed"`
+	Rows      []ImportRowResult `json:"rows"`
+}
+
+var carIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidateCatalogCar проверяет строку загрузки
+func ValidateCatalogCar(c CatalogCar) []string {
+	var errs []string
+
+	if !carIDPattern.MatchString(c.ID) {
+		errs = append(errs, "id обязателен: латиница в нижнем регистре, цифры, - и _")
+	}
+	if strings.TrimSpace(c.Title) == "" {
+		errs = append(errs, "title обязателен")
+	}
+	if c.Price < 0 {
+		errs = append(errs, "price не может быть отрицательной")
+	}
+	if c.Status != "" && !IsValidCarStatus(c.Status) {
+		errs = append(errs, "status должен быть draft, published или archived")
+	}
+	for _, s := range append(append([]CatalogSpec{}, c.TechSpecs...), c.Equipment...) {
+		if strings.TrimSpace(s.Name) == "" {
+			errs = append(errs, "у характеристики должно быть название")
+			break
+		}
+	}
+
+	return errs
+}
+
+// ---------- CSV ----------
+// Одна строка — один автомобиль. Списки (features, images) — по одному значению на строку
+// внутри ячейки, характеристики — «Название: значение» по одной на строку.
+
+var catalogCSVHeader = []string{
+	"id", "title", "description", "category", "image", "price", "status",
+	"features", "images", "tech_specs", "equipment",
+}
+
+// WriteCatalogCSV пишет каталог в CSV (с BOM, чтобы Excel понял UTF-8)
+func WriteCatalogCSV(w io.Writer, cars []CatalogCar) error {
+	if _, err := w.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(catalogCSVHeader); err != nil {
+		return err
+	}
+	for _, c := range cars {
+		rec := []string{
+			c.ID, c.Title, c.Description, c.Category, c.Image, strconv.Itoa(c.Price), c.Status,
+			strings.Join(c.Features, "\n"), strings.Join(c.Images, "\n"),
+			joinSpecs(c.TechSpecs), joinSpecs(c.Equipment),
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadCatalogCSV разбирает CSV. Ошибки разбора отдельных строк возвращаются
+// в rowErrs (номер строки → ошибка), чтобы остальные строки можно было загрузить.
+func ReadCatalogCSV(r io.Reader) (cars []CatalogCar, rows []int, rowErrs map[int]string, err error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("не удалось прочитать заголовок CSV: %v", err)
+	}
+	col := map[string]int{}
+	for i, h := range header {
+		col[strings.TrimPrefix(strings.TrimSpace(strings.ToLower(h)), "\ufeff")] = i
+	}
+	for _, required := range []string{"id", "title"} {
+		if _, ok := col[required]; !ok {
+			return nil, nil, nil, fmt.Errorf("в CSV нет колонки %q", required)
+		}
+	}
+
+	rowErrs = map[int]string{}
+	line := 1
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			rowErrs[line] = err.Error()
+			continue
+		}
+
+		get := func(name string) string {
+			if i, ok := col[name]; ok && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+
+		c := CatalogCar{
+			ID:          get("id"),
+			Title:       get("title"),
+			Description: get("description"),
+			Category:    get("category"),
+			Image:       get("image"),
+			Status:      get("status"),
+			Features:    splitLines(get("features")),
+			Images:      splitLines(get("images")),
+		}
+		if p := get("price"); p != "" {
+			price, err := strconv.Atoi(strings.ReplaceAll(p, " ", ""))
+			if err != nil {
+				rowErrs[line] = fmt.Sprintf("price %q не число", p)
+				continue
+			}
+			c.Price = price
+		}
+		if c.TechSpecs, err = parseSpecs(get("tech_specs")); err != nil {
+			rowErrs[line] = "tech_specs: " + err.Error()
+			continue
+		}
+		if c.Equipment, err = parseSpecs(get("equipment")); err != nil {
+			rowErrs[line] = "equipment: " + err.Error()
+			continue
+		}
+
+		cars = append(cars, c)
+		rows = append(rows, line)
+	}
+	return cars, rows, rowErrs, nil
+}
+
+func splitLines(s string) []string {
+	var out []string
+	for _, l := range strings.Split(s, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+func joinSpecs(specs []CatalogSpec) string {
+	lines := make([]string, len(specs))
+	for i, s := range specs {
+		lines[i] = s.Name + ": " + s.Value
+	}
+	return strings.Join(lines, "\n")
+}
+
+func parseSpecs(s string) ([]CatalogSpec, error) {
+	var specs []CatalogSpec
+	for _, l := range splitLines(s) {
+		name, value, ok := strings.Cut(l, ":")
+		if !ok {
+			return nil, fmt.Errorf("ожидается «Название: значение», получено %q", l)
+		}
+		specs = append(specs, CatalogSpec{Name: strings.TrimSpace(name), Value: strings.TrimSpace(value)})
+	}
+	return specs, nil
+}
+
+// ---------- JSON ----------
+
+// ReadCatalogJSON принимает как полную выгрузку ({"cars": [...]}), так и просто массив автомобилей
+func ReadCatalogJSON(r io.Reader) (cars []CatalogCar, rows []int, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "[") {
+		err = json.Unmarshal(data, &cars)
+	} else {
+		var export CatalogExport
+		err = json.Unmarshal(data, &export)
+		cars = export.Cars
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("некорректный JSON: %v", err)
+	}
+	for i := range cars {
+		rows = append(rows, i+1)
+	}
+	return cars, rows, nil
+}
+
+// ReadCatalog разбирает файл каталога в формате "json" или "csv"
+func ReadCatalog(r io.Reader, format string) (cars []CatalogCar, rows []int, rowErrs map[int]string, err error) {
+	switch format {
+	case "json":
+		cars, rows, err = ReadCatalogJSON(r)
+		return cars, rows, nil, err
+	case "csv":
+		return ReadCatalogCSV(r)
+	}
+	return nil, nil, nil, fmt.Errorf("неизвестный формат %q: ожидается json или csv", format)
+}
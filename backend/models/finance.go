@@ -0,0 +1,174 @@
+package models
+
+import (
+	"errors"
+	"math"
+)
+
+const (
+	FinanceKindCredit  = "credit"
+	FinanceKindLeasing = "leasing"
+)
+
+// FinanceProduct описывает кредитную или лизинговую программу
+type FinanceProduct struct {
+	ID                int     `json:"id"`
+	Code              string  `json:"code"`
+	Name              string  `json:"name"`
+	Kind              string  `json:"kind"`
+	AnnualRate        float64 `json:"annualRate"`        // годовая ставка, %
+	MinDownPaymentPct float64 `json:"minDownPaymentPct"` // минимальный первый взнос, % от цены
+	ResidualPct       float64 `json:"residualPct"`       // выкупной платёж (только лизинг), % от цены
+	MinTermMonths     int     `json:"minTermMonths"`
+	MaxTermMonths     int     `json:"maxTermMonths"`
+	Active            bool    `json:"active"`
+}
+
+type FinanceQuoteRequest struct {
+	CarID       string `json:"carId"`
+	Price       int    `json:"price"` // цена конфигурации, если считаем не по base_price
+	DownPayment int    `json:"downPayment"`
+	TermMonths  int    `json:"termMonths"`
+	Product     string `json:"product"` // code программы
+}
+
+type PaymentScheduleRow struct {
+	Month     int `json:"month"`
+	Payment   int `json:"payment"`
+	Principal int `json:"principal"`
+	Interest  int `json:"interest"`
+	Balance   int `json:"balance"`
+}
+
+type FinanceQuote struct {
+	Product         string               `json:"product"`
+	Kind            string               `json:"kind"`
+	Price           int                  `json:"price"`
+	DownPayment     int                  `json:"downPayment"`
+	Principal       int                  `json:"principal"`
+	TermMonths      int                  `json:"termMonths"`
+	AnnualRate      float64              `json:"annualRate"`
+	MonthlyPayment  int                  `json:"monthlyPayment"`
+	ResidualPayment int                  `json:"residualPayment"`
+	TotalCost       int                  `json:"totalCost"`
+	Overpayment     int                  `json:"overpayment"`
+	Schedule        []PaymentScheduleRow `json:"schedule"`
+}
+
+var (
+	ErrInvalidPrice       = errors.New("цена должна быть больше нуля")
+	ErrInvalidDownPayment = errors.New("первый взнос должен быть не меньше минимального и меньше цены")
+	ErrInvalidTerm        = errors.New("срок не входит в допустимый диапазон программы")
+)
+
+// ValidateFinanceProduct проверяет параметры программы перед сохранением
+func ValidateFinanceProduct(p FinanceProduct) []string {
+	var errs []string
+
+	if p.Code == "" {
+		errs = append(errs, "code обязателен")
+	}
+	if p.Name == "" {
+		errs = append(errs, "name обязателен")
+	}
+	if p.Kind != FinanceKindCredit && p.Kind != FinanceKindLeasing {
+		errs = append(errs, "kind должен быть credit или leasing")
+	}
+	if p.AnnualRate < 0 {
+		errs = append(errs, "ставка не может быть отрицательной")
+	}
+	if p.MinDownPaymentPct < 0 || p.MinDownPaymentPct >= 100 {
+		errs = append(errs, "минимальный взнос должен быть в диапазоне 0–100%")
+	}
+	if p.ResidualPct < 0 || p.ResidualPct >= 100 {
+		errs = append(errs, "выкупной платёж должен быть в диапазоне 0–100%")
+	}
+	if p.Kind == FinanceKindCredit && p.ResidualPct != 0 {
+		errs = append(errs, "выкупной платёж допустим только для лизинга")
+	}
+	if p.MinTermMonths <= 0 || p.MaxTermMonths < p.MinTermMonths {
+		errs = append(errs, "некорректный диапазон сроков")
+	}
+
+	return errs
+}
+
+// CalculateQuote считает аннуитетный график платежей.
+// Все суммы — целые рубли; ежемесячный платёж округляется до рубля,
+// а последний платёж корректируется так, чтобы остаток сошёлся в ноль
+// (для лизинга — в сумму выкупного платежа).
+func CalculateQuote(price, downPayment, termMonths int, p FinanceProduct) (*FinanceQuote, error) {
+	if price <= 0 {
+		return nil, ErrInvalidPrice
+	}
+	minDown := int(math.Ceil(float64(price) * p.MinDownPaymentPct / 100))
+	if downPayment < minDown || downPayment >= price {
+		return nil, ErrInvalidDownPayment
+	}
+	if termMonths < p.MinTermMonths || termMonths > p.MaxTermMonths {
+		return nil, ErrInvalidTerm
+	}
+
+	principal := price - downPayment
+	residual := 0
+	if p.Kind == FinanceKindLeasing {
+		residual = int(math.Round(float64(price) * p.ResidualPct / 100))
+		if residual > principal {
+			residual = principal
+		}
+	}
+
+	monthlyRate := p.AnnualRate / 12 / 100
+	payment := annuityPayment(float64(principal), float64(residual), monthlyRate, termMonths)
+
+	quote := &FinanceQuote{
+		Product:         p.Code,
+		Kind:            p.Kind,
+		Price:           price,
+		DownPayment:     downPayment,
+		Principal:       principal,
+		TermMonths:      termMonths,
+		AnnualRate:      p.AnnualRate,
+		MonthlyPayment:  payment,
+		ResidualPayment: residual,
+		Schedule:        make([]PaymentScheduleRow, 0, termMonths),
+	}
+
+	balance := principal
+	paid := 0
+	for month := 1; month <= termMonths; month++ {
+		interest := int(math.Round(float64(balance) * monthlyRate))
+		principalPart := payment - interest
+		if month == termMonths {
+			// последний платёж закрывает остаток до выкупной суммы
+			principalPart = balance - residual
+		}
+
+		balance -= principalPart
+		rowPayment := principalPart + interest
+		paid += rowPayment
+
+		quote.Schedule = append(quote.Schedule, PaymentScheduleRow{
+			Month:     month,
+			Payment:   rowPayment,
+			Principal: principalPart,
+			Interest:  interest,
+			Balance:   balance,
+		})
+	}
+
+	quote.TotalCost = downPayment + paid + residual
+	quote.Overpayment = quote.TotalCost - price
+
+	return quote, nil
+}
+
+// annuityPayment — платёж по аннуитету с остаточной (balloon) стоимостью
+func annuityPayment(principal, residual, monthlyRate float64, n int) int {
+	if monthlyRate == 0 {
+		return int(math.Round((principal - residual) / float64(n)))
+	}
+	factor := math.Pow(1+monthlyRate, float64(n))
+	payment := (principal - residual/factor) * monthlyRate / (1 - 1/factor)
+	return int(math.Round(payment))
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCalculateQuote(t *testing.T) {
+	credit := FinanceProduct{Code: "credit", Kind: FinanceKindCredit, AnnualRate: 12,
+		MinDownPaymentPct: 10, MinTermMonths: 6, MaxTermMonths: 84}
+	zeroRate := credit
+	zeroRate.AnnualRate = 0
+	leasing := FinanceProduct{Code: "leasing", Kind: FinanceKindLeasing, AnnualRate: 9,
+		MinDownPaymentPct: 20, ResidualPct: 25, MinTermMonths: 12, MaxTermMonths: 60}
+
+	tests := []struct {
+		name        string
+		price, down int
+		term        int
+		product     FinanceProduct
+		wantErr     error
+		wantPayment int
+		wantResid   int
+		wantOver    int
+	}{
+		{name: "zero rate", price: 1_200_000, down: 200_000, term: 10, product: zeroRate,
+			wantPayment: 100_000, wantOver: 0},
+		{name: "annuity credit", price: 1_000_000, down: 100_000, term: 12, product: credit,
+			wantPayment: 79_964, wantOver: 59_568},
+		{name: "last payment reconciles odd amounts", price: 1_999_999, down: 300_001, term: 7, product: credit,
+			wantPayment: 252_668, wantOver: 68_677},
+		{name: "leasing with balloon", price: 2_000_000, down: 400_000, term: 36, product: leasing,
+			wantPayment: 38_730, wantResid: 500_000, wantOver: 294_271},
+		{name: "leasing down payment below minimum", price: 2_000_000, down: 399_999, term: 36, product: leasing,
+			wantErr: ErrInvalidDownPayment},
+		{name: "down payment equal to price", price: 1_000_000, down: 1_000_000, term: 12, product: credit,
+			wantErr: ErrInvalidDownPayment},
+		{name: "term out of range", price: 1_000_000, down: 100_000, term: 120, product: credit,
+			wantErr: ErrInvalidTerm},
+		{name: "zero price", price: 0, down: 0, term: 12, product: credit,
+			wantErr: ErrInvalidPrice},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := CalculateQuote(tt.price, tt.down, tt.term, tt.product)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if q.MonthlyPayment != tt.wantPayment {
+				t.Errorf("MonthlyPayment = %d, want %d", q.MonthlyPayment, tt.wantPayment)
+			}
+			if q.ResidualPayment != tt.wantResid {
+				t.Errorf("ResidualPayment = %d, want %d", q.ResidualPayment, tt.wantResid)
+			}
+			if q.Overpayment != tt.wantOver {
+				t.Errorf("Overpayment = %d, want %d", q.Overpayment, tt.wantOver)
+			}
+			if len(q.Schedule) != tt.term {
+				t.Fatalf("len(Schedule) = %d, want %d", len(q.Schedule), tt.term)
+			}
+
+			// остаток сходится в ноль (для лизинга — в выкупной платёж), а график гасит весь долг
+			last := q.Schedule[len(q.Schedule)-1]
+			if last.Balance != q.ResidualPayment {
+				t.Errorf("last balance = %d, want %d", last.Balance, q.ResidualPayment)
+			}
+			principal, paid := 0, 0
+			for _, row := range q.Schedule {
+				if row.Payment != row.Principal+row.Interest {
+					t.Errorf("month %d: payment %d != principal %d + interest %d",
+						row.Month, row.Payment, row.Principal, row.Interest)
+				}
+				principal += row.Principal
+				paid += row.Payment
+			}
+			if principal != q.Principal-q.ResidualPayment {
+				t.Errorf("sum of principal = %d, want %d", principal, q.Principal-q.ResidualPayment)
+			}
+			if q.TotalCost != tt.down+paid+q.ResidualPayment {
+				t.Errorf("TotalCost = %d, want %d", q.TotalCost, tt.down+paid+q.ResidualPayment)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	LeadTypeCallback = "callback" // «перезвоните мне»
+	LeadTypeQuote    = "quote"    // «получить предложение»
+	LeadTypeQuestion = "question" // «вопрос по модели»
+)
+
+const (
+	LeadNew       = "new"
+	LeadContacted = "contacted"
+	LeadQualified = "qualified"
+	LeadWon       = "won"
+	LeadLost      = "lost"
+)
+
+// Типы записей в истории лида
+const (
+	LeadActivityCreated  = "created"
+	LeadActivityStatus   = "status"
+	LeadActivityAssigned = "assigned"
+	LeadActivityNote     = "note"
+)
+
+var leadTypes = map[string]bool{
+	LeadTypeCallback: true,
+	LeadTypeQuote:    true,
+	LeadTypeQuestion: true,
+}
+
+// leadTransitions — допустимые переходы по воронке; won/lost закрывают лид
+var leadTransitions = map[string][]string{
+	LeadNew:       {LeadContacted, LeadQualified, LeadLost},
+	LeadContacted: {LeadQualified, LeadWon, LeadLost},
+	LeadQualified: {LeadContacted, LeadWon, LeadLost},
+}
+
+type Lead struct {
+	ID            int       `json:"id"`
+	Type          string    `json:"type"`
+	CarID         string    `json:"carId"`
+	Name          string    `json:"name"`
+	Phone         string    `json:"phone"`
+	Email         string    `json:"email,omitempty"`
+	Message       string    `json:"message,omitempty"`
+	Status        string    `json:"status"`
+	AssignedTo    string    `json:"assignedTo,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+	LastTouchedAt time.Time `json:"lastTouchedAt"`
+	SLABreached   bool      `json:"slaBreached"`
+}
+
+type LeadActivity struct {
+	ID        int       `json:"id"`
+	LeadID    int       `json:"leadId"`
+	Actor     string    `json:"actor"`
+	Kind      string    `json:"kind"`
+	Details   string    `json:"details"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type LeadFilter struct {
+	Status     string
+	AssignedTo string
+	CarID      string
+	Overdue    bool
+}
+
+var phoneRegex = regexp.MustCompile(`^\+?[0-9\s()\-]{10,20}$`)
+
+// ValidateLead проверяет заявку с сайта
+func ValidateLead(l Lead) []string {
+	var errs []string
+
+	if !leadTypes[l.Type] {
+		errs = append(errs, "type должен быть callback, quote или question")
+	}
+	if strings.TrimSpace(l.CarID) == "" {
+		errs = append(errs, "carId обязателен")
+	}
+	if strings.TrimSpace(l.Name) == "" {
+		errs = append(errs, "Имя обязательно")
+	}
+	if !phoneRegex.MatchString(strings.TrimSpace(l.Phone)) {
+		errs = append(errs, "Некорректный номер телефона")
+	}
+	if l.Email != "" && !ValidateEmail(l.Email) {
+		errs = append(errs, "Некорректный email адрес")
+	}
+	if len(l.Message) > 2000 {
+		errs = append(errs, "Сообщение не должно превышать 2000 символов")
+	}
+
+	return errs
+}
+
+// CanTransitionLead проверяет, можно ли перевести лид из from в to
+func CanTransitionLead(from, to string) bool {
+	for _, s := range leadTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsLeadOpen — лид ещё в работе (не выигран и не проигран)
+func IsLeadOpen(status string) bool {
+	return status != LeadWon && status != LeadLost
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Что лежит в очереди модерации
+const (
+	ModerationKindReview = "review"
+	ModerationKindLead   = "lead"
+)
+
+const (
+	ModerationPending  = "pending"
+	ModerationApproved = "approved"
+	ModerationRejected = "rejected"
+)
+
+// ModerationItem — подозрительная отправка формы, ждущая решения администратора.
+// Payload — отзыв или лид в том виде, в каком его сохранят после одобрения.
+type ModerationItem struct {
+	ID        int             `json:"id"`
+	Kind      string          `json:"kind"`
+	Payload   json.RawMessage `json:"payload"`
+	Reasons   []string        `json:"reasons"`
+	ClientIP  string          `json:"clientIp"`
+	Status    string          `json:"status"`
+	CreatedAt time.Time       `json:"createdAt"`
+	DecidedBy string          `json:"decidedBy,omitempty"`
+	DecidedAt *time.Time      `json:"decidedAt,omitempty"`
+	// ResultID — id отзыва или лида, созданного при одобрении
+	ResultID int `json:"resultId,omitempty"`
+}
+
+// IsModerationKind — kind из запроса администратора допустим
+func IsModerationKind(kind string) bool {
+	return kind == ModerationKindReview || kind == ModerationKindLead
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Order — оформленная корзина. Суммы фиксируются на момент оформления.
+type Order struct {
+	ID            int         `json:"id"`
+	UserID        string      `json:"userId"`
+	Items         []OrderItem `json:"items"`
+	Subtotal      int         `json:"subtotal"`
+	Discount      int         `json:"discount"`
+	TradeInCredit int         `json:"tradeInCredit"`
+	Total         int         `json:"total"`
+	PromoCodes    []string    `json:"promoCodes"` // промокоды, давшие скидку; по ним считается лимит
+	TradeInIDs    []int       `json:"tradeInIds"` // trade-in, зачтённые в этот заказ
+	CreatedAt     time.Time   `json:"createdAt"`
+}
+
+type OrderItem struct {
+	CarID     string `json:"carId"`
+	Title     string `json:"title"`
+	Quantity  int    `json:"quantity"`
+	UnitPrice int    `json:"unitPrice"` // после акций по позиции
+	LineTotal int    `json:"lineTotal"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// PriceChange — запись в истории цен автомобиля
+type PriceChange struct {
+	ID         int       `json:"id"`
+	CarID      string    `json:"carId"`
+	OldPrice   *int      `json:"oldPrice"` // nil для первой цены при создании
+	NewPrice   int       `json:"newPrice"`
+	ChangedBy  string    `json:"changedBy"`
+	ChangedAt  time.Time `json:"changedAt"`
+	ScheduleID *int      `json:"scheduleId,omitempty"` // если изменение пришло из расписания
+}
+
+// ScheduledPriceChange — запланированное изменение цены
+type ScheduledPriceChange struct {
+	ID          int        `json:"id"`
+	CarID       string     `json:"carId"`
+	NewPrice    int        `json:"newPrice"`
+	EffectiveAt time.Time  `json:"effectiveAt"`
+	CreatedBy   string     `json:"createdBy"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	AppliedAt   *time.Time `json:"appliedAt,omitempty"`
+	CancelledAt *time.Time `json:"cancelledAt,omitempty"`
+}
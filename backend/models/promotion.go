@@ -0,0 +1,180 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	PromoKindPercent = "percent" // Value — процент скидки
+	PromoKindFixed   = "fixed"   // Value — скидка в рублях
+
+	PromoScopeCar      = "car"      // Target — id автомобиля
+	PromoScopeCategory = "category" // Target — категория каталога
+	PromoScopeCart     = "cart"     // скидка на всю корзину от MinCartTotal
+)
+
+// Promotion — акция; если задан Code, действует только после ввода промокода
+type Promotion struct {
+	ID           int        `json:"id"`
+	Name         string     `json:"name"`
+	Description  string     `json:"description,omitempty"` // например, «зимние шины в подарок»
+	Code         string     `json:"code,omitempty"`
+	Kind         string     `json:"kind"`
+	Value        int        `json:"value"`
+	Scope        string     `json:"scope"`
+	Target       string     `json:"target,omitempty"`
+	MinCartTotal int        `json:"minCartTotal,omitempty"`
+	StartsAt     *time.Time `json:"startsAt,omitempty"`
+	EndsAt       *time.Time `json:"endsAt,omitempty"`
+	UsageLimit   int        `json:"usageLimit"` // 0 — без ограничений
+	UsedCount    int        `json:"usedCount"`  // использований в оформленных заказах
+	Stackable    bool       `json:"stackable"`
+	Active       bool       `json:"active"`
+}
+
+// AppliedPromotion — акция, сработавшая для позиции или корзины
+type AppliedPromotion struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Code        string `json:"code,omitempty"`
+	Discount    int    `json:"discount"`
+}
+
+// ValidatePromotion проверяет акцию перед сохранением
+func ValidatePromotion(p Promotion) []string {
+	var errs []string
+
+	if strings.TrimSpace(p.Name) == "" {
+		errs = append(errs, "name обязателен")
+	}
+	switch p.Kind {
+	case PromoKindPercent:
+		if p.Value <= 0 || p.Value > 100 {
+			errs = append(errs, "процент скидки должен быть от 1 до 100")
+		}
+	case PromoKindFixed:
+		if p.Value < 0 {
+			errs = append(errs, "скидка не может быть отрицательной")
+		}
+	default:
+		errs = append(errs, "kind должен быть percent или fixed")
+	}
+	switch p.Scope {
+	case PromoScopeCar, PromoScopeCategory:
+		if strings.TrimSpace(p.Target) == "" {
+			errs = append(errs, "target обязателен для акций на автомобиль или категорию")
+		}
+	case PromoScopeCart:
+	default:
+		errs = append(errs, "scope должен быть car, category или cart")
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		errs = append(errs, "endsAt должен быть позже startsAt")
+	}
+	if p.UsageLimit < 0 {
+		errs = append(errs, "usageLimit не может быть отрицательным")
+	}
+
+	return errs
+}
+
+// IsValidAt — акция включена, попадает в окно действия и не исчерпала лимит
+func (p Promotion) IsValidAt(now time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if p.StartsAt != nil && now.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && !now.Before(*p.EndsAt) {
+		return false
+	}
+	return p.UsageLimit == 0 || p.UsedCount < p.UsageLimit
+}
+
+// AppliesToCar — акция относится к этому автомобилю
+func (p Promotion) AppliesToCar(carID, category string) bool {
+	switch p.Scope {
+	case PromoScopeCar:
+		return p.Target == carID
+	case PromoScopeCategory:
+		return p.Target == category
+	}
+	return false
+}
+
+// DiscountFor — размер скидки на сумму amount, не больше самой суммы
+func (p Promotion) DiscountFor(amount int) int {
+	var d int
+	switch p.Kind {
+	case PromoKindPercent:
+		d = amount * p.Value / 100
+	case PromoKindFixed:
+		d = p.Value
+	}
+	if d > amount {
+		d = amount
+	}
+	return d
+}
+
+// BestDiscount применяет правила суммирования:
+// все stackable-акции складываются между собой, а из нестакающихся
+// берётся одна самая выгодная; клиент получает больший из двух вариантов.
+func BestDiscount(amount int, promos []Promotion) (int, []AppliedPromotion) {
+	var stacked []AppliedPromotion
+	stackedTotal := 0
+
+	var best *AppliedPromotion
+	for _, p := range promos {
+		d := p.DiscountFor(amount)
+		applied := AppliedPromotion{ID: p.ID, Name: p.Name, Description: p.Description, Code: p.Code, Discount: d}
+
+		if p.Stackable {
+			stacked = append(stacked, applied)
+			stackedTotal += d
+			continue
+		}
+		if best == nil || d > best.Discount || (d == best.Discount && p.ID < best.ID) {
+			a := applied
+			best = &a
+		}
+	}
+
+	if stackedTotal > amount {
+		stackedTotal = amount
+	}
+
+	if best != nil && best.Discount >= stackedTotal {
+		return best.Discount, []AppliedPromotion{*best}
+	}
+	if len(stacked) == 0 {
+		return 0, []AppliedPromotion{}
+	}
+	return stackedTotal, stacked
+}
+
+// PriceForCar считает цену автомобиля с учётом подходящих акций
+func PriceForCar(carID, category string, price int, promos []Promotion) (int, []AppliedPromotion) {
+	var matching []Promotion
+	for _, p := range promos {
+		if p.AppliesToCar(carID, category) {
+			matching = append(matching, p)
+		}
+	}
+	discount, applied := BestDiscount(price, matching)
+	return price - discount, applied
+}
+
+// CartDiscount считает скидку на корзину от суммы subtotal
+func CartDiscount(subtotal int, promos []Promotion) (int, []AppliedPromotion) {
+	var matching []Promotion
+	for _, p := range promos {
+		if p.Scope == PromoScopeCart && subtotal >= p.MinCartTotal {
+			matching = append(matching, p)
+		}
+	}
+	return BestDiscount(subtotal, matching)
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+const (
+	CarDraft     = "draft"     // готовится, виден только в админке и по ссылке предпросмотра
+	CarPublished = "published" // виден в публичном каталоге
+	CarArchived  = "archived"  // снят с публикации
+)
+
+// CarPublishing — статус автомобиля и расписание публикации
+type CarPublishing struct {
+	Status      string     `json:"status"`
+	PublishAt   *time.Time `json:"publishAt,omitempty"`   // когда опубликовать черновик/архив
+	UnpublishAt *time.Time `json:"unpublishAt,omitempty"` // когда снять с публикации
+}
+
+func IsValidCarStatus(s string) bool {
+	return s == CarDraft || s == CarPublished || s == CarArchived
+}
+
+// ValidateCarPublishing проверяет статус и расписание
+func ValidateCarPublishing(p CarPublishing) []string {
+	var errs []string
+
+	if !IsValidCarStatus(p.Status) {
+		errs = append(errs, "status должен быть draft, published или archived")
+	}
+	if p.PublishAt != nil && p.Status == CarPublished {
+		errs = append(errs, "publishAt задаётся только для неопубликованного автомобиля")
+	}
+	if p.UnpublishAt != nil && p.Status != CarPublished && p.PublishAt == nil {
+		errs = append(errs, "unpublishAt без publishAt имеет смысл только для опубликованного автомобиля")
+	}
+	if p.PublishAt != nil && p.UnpublishAt != nil && !p.UnpublishAt.After(*p.PublishAt) {
+		errs = append(errs, "unpublishAt должен быть позже publishAt")
+	}
+
+	return errs
+}
+
+// InUTC приводит расписание к UTC — так время хранится в БД и сравнивается планировщиком
+func (p CarPublishing) InUTC() CarPublishing {
+	if p.PublishAt != nil {
+		t := p.PublishAt.UTC()
+		p.PublishAt = &t
+	}
+	if p.UnpublishAt != nil {
+		t := p.UnpublishAt.UTC()
+		p.UnpublishAt = &t
+	}
+	return p
+}
@@ -0,0 +1,216 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// CarProfile — данные автомобиля, по которым считается похожесть
+type CarProfile struct {
+	ID       string
+	Title    string
+	Category string
+	Image    string
+	Price    int
+	Features []string
+	Specs    SpecValues
+}
+
+// RecommendedCar — автомобиль в рекомендациях. Для похожих заполнены Score и Reasons,
+// для «также смотрели / добавляли в корзину» — Customers.
+type RecommendedCar struct {
+	ID        string   `json:"id"`
+	Title     string   `json:"title"`
+	Category  string   `json:"category"`
+	Image     string   `json:"image"`
+	Price     int      `json:"price"`
+	Score     float64  `json:"score,omitempty"`
+	Reasons   []string `json:"reasons,omitempty"`
+	Customers int      `json:"customers,omitempty"` // покупателей, смотревших или добавивших оба автомобиля
+}
+
+// CarRecommendations — ответ GET /api/cars/{id}/similar
+type CarRecommendations struct {
+	CarID           string           `json:"carId"`
+	Similar         []RecommendedCar `json:"similar"`
+	AlsoViewed      []RecommendedCar `json:"alsoViewed"`
+	AlsoAddedToCart []RecommendedCar `json:"alsoAddedToCart"`
+}
+
+// CoOccurrence — сколько покупателей взаимодействовали и с исходным автомобилем, и с CarID
+type CoOccurrence struct {
+	CarID     string
+	Customers int
+}
+
+// веса составляющих похожести; максимум суммы — 10
+const (
+	similarCategoryWeight = 3.0
+	similarPriceWeight    = 3.0
+	similarFeaturesWeight = 2.0
+	similarSpecsWeight    = 2.0
+
+	// при такой относительной разнице цен (и числовых характеристик) их вклад падает до нуля
+	similarPriceSpread = 0.5
+	similarSpecSpread  = 0.5
+)
+
+// причины, по которым автомобиль считается похожим (поле reasons)
+const (
+	ReasonSameCategory   = "same_category"
+	ReasonSimilarPrice   = "similar_price"
+	ReasonSharedFeatures = "shared_features"
+	ReasonSimilarSpecs   = "similar_specs"
+)
+
+// similarSpecKeys — характеристики, совпадение которых делает автомобили похожими
+var similarSpecKeys = []string{"drive_type", "fuel_type", "transmission", "power_hp"}
+
+// SimilarityScore оценивает похожесть b на a от 0 до 10: категория, близость цены,
+// доля общих особенностей (коэффициент Жаккара) и совпадение ключевых характеристик.
+// Функция чистая и детерминированная; reasons — составляющие, давшие заметный вклад.
+func SimilarityScore(a, b CarProfile) (float64, []string) {
+	var score float64
+	reasons := []string{}
+
+	if a.Category != "" && a.Category == b.Category {
+		score += similarCategoryWeight
+		reasons = append(reasons, ReasonSameCategory)
+	}
+
+	if a.Price > 0 && b.Price > 0 {
+		diff := math.Abs(float64(a.Price-b.Price)) / float64(max(a.Price, b.Price))
+		if closeness := 1 - diff/similarPriceSpread; closeness > 0 {
+			score += similarPriceWeight * closeness
+			if closeness >= 0.5 {
+				reasons = append(reasons, ReasonSimilarPrice)
+			}
+		}
+	}
+
+	if j := jaccard(a.Features, b.Features); j > 0 {
+		score += similarFeaturesWeight * j
+		reasons = append(reasons, ReasonSharedFeatures)
+	}
+
+	if s := specsCloseness(a.Specs, b.Specs); s > 0 {
+		score += similarSpecsWeight * s
+		if s >= 0.5 {
+			reasons = append(reasons, ReasonSimilarSpecs)
+		}
+	}
+
+	// округление убирает шум float и делает порядок при равных оценках стабильным
+	return math.Round(score*100) / 100, reasons
+}
+
+// RankSimilar возвращает до limit автомобилей, наиболее похожих на target, без него самого.
+// При равной оценке выше тот, чья цена ближе, затем — по ID.
+func RankSimilar(target CarProfile, candidates []CarProfile, limit int) []RecommendedCar {
+	type scored struct {
+		car     CarProfile
+		score   float64
+		reasons []string
+	}
+	var list []scored
+	for _, c := range candidates {
+		if c.ID == target.ID {
+			continue
+		}
+		score, reasons := SimilarityScore(target, c)
+		if score <= 0 {
+			continue
+		}
+		list = append(list, scored{c, score, reasons})
+	}
+
+	priceGap := func(c CarProfile) int {
+		if c.Price > target.Price {
+			return c.Price - target.Price
+		}
+		return target.Price - c.Price
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		if ga, gb := priceGap(a.car), priceGap(b.car); ga != gb {
+			return ga < gb
+		}
+		return a.car.ID < b.car.ID
+	})
+
+	result := []RecommendedCar{}
+	for _, s := range list {
+		if len(result) == limit {
+			break
+		}
+		r := s.car.Recommended()
+		r.Score, r.Reasons = s.score, s.reasons
+		result = append(result, r)
+	}
+	return result
+}
+
+// Recommended — карточка автомобиля для списка рекомендаций
+func (p CarProfile) Recommended() RecommendedCar {
+	return RecommendedCar{ID: p.ID, Title: p.Title, Category: p.Category, Image: p.Image, Price: p.Price}
+}
+
+// jaccard — доля общих особенностей среди всех особенностей двух автомобилей (без учёта регистра)
+func jaccard(a, b []string) float64 {
+	set := map[string]bool{}
+	for _, f := range a {
+		set[normalizeSpecText(f)] = true
+	}
+	union := len(set)
+	common := 0
+	seen := map[string]bool{}
+	for _, f := range b {
+		f = normalizeSpecText(f)
+		if seen[f] {
+			continue
+		}
+		seen[f] = true
+		if set[f] {
+			common++
+		} else {
+			union++
+		}
+	}
+	if union == 0 {
+		return 0
+	}
+	return float64(common) / float64(union)
+}
+
+// specsCloseness — средняя близость ключевых характеристик, известных у обоих автомобилей:
+// перечисления совпадают или нет, числа сравниваются по относительной разнице
+func specsCloseness(a, b SpecValues) float64 {
+	var sum float64
+	n := 0
+	for _, key := range similarSpecKeys {
+		va, okA := a[key]
+		vb, okB := b[key]
+		if !okA || !okB {
+			continue
+		}
+		n++
+		switch va := va.(type) {
+		case string:
+			if vb, ok := vb.(string); ok && strings.EqualFold(va, vb) {
+				sum++
+			}
+		case float64:
+			if vb, ok := vb.(float64); ok && va > 0 && vb > 0 {
+				sum += math.Max(0, 1-math.Abs(va-vb)/math.Max(va, vb)/similarSpecSpread)
+			}
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSimilarityScore(t *testing.T) {
+	tests := []struct {
+		name        string
+		a, b        CarProfile
+		wantScore   float64
+		wantReasons []string
+	}{
+		{name: "same category",
+			a: CarProfile{Category: "suv"}, b: CarProfile{Category: "suv"},
+			wantScore: 3, wantReasons: []string{ReasonSameCategory}},
+		{name: "different category, no price",
+			a: CarProfile{Category: "suv"}, b: CarProfile{Category: "van"},
+			wantScore: 0, wantReasons: []string{}},
+		{name: "close price",
+			a: CarProfile{Price: 1_000_000}, b: CarProfile{Price: 1_100_000},
+			wantScore: 2.45, wantReasons: []string{ReasonSimilarPrice}},
+		{name: "price gap counts but is not a reason",
+			a: CarProfile{Price: 1_000_000}, b: CarProfile{Price: 1_400_000},
+			wantScore: 1.29, wantReasons: []string{}},
+		{name: "price twice as high gives nothing",
+			a: CarProfile{Price: 1_000_000}, b: CarProfile{Price: 2_000_000},
+			wantScore: 0, wantReasons: []string{}},
+		{name: "shared features ignore case",
+			a:         CarProfile{Features: []string{"ABS", "Круиз-контроль"}},
+			b:         CarProfile{Features: []string{"abs", "Подогрев сидений", "ABS"}},
+			wantScore: 0.67, wantReasons: []string{ReasonSharedFeatures}},
+		{name: "specs: enum match and numeric closeness",
+			a:         CarProfile{Specs: SpecValues{"drive_type": "awd", "power_hp": 100.0, "fuel_type": "petrol"}},
+			b:         CarProfile{Specs: SpecValues{"drive_type": "AWD", "power_hp": 150.0}},
+			wantScore: 1.33, wantReasons: []string{ReasonSimilarSpecs}},
+		{name: "identical cars score the maximum",
+			a: CarProfile{Category: "suv", Price: 1_500_000, Features: []string{"ABS"},
+				Specs: SpecValues{"transmission": "cvt"}},
+			b: CarProfile{Category: "suv", Price: 1_500_000, Features: []string{"ABS"},
+				Specs: SpecValues{"transmission": "cvt"}},
+			wantScore:   10,
+			wantReasons: []string{ReasonSameCategory, ReasonSimilarPrice, ReasonSharedFeatures, ReasonSimilarSpecs}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, reasons := SimilarityScore(tt.a, tt.b)
+			if score != tt.wantScore {
+				t.Errorf("score = %v, want %v", score, tt.wantScore)
+			}
+			if !reflect.DeepEqual(reasons, tt.wantReasons) {
+				t.Errorf("reasons = %v, want %v", reasons, tt.wantReasons)
+			}
+		})
+	}
+}
+
+func TestRankSimilar(t *testing.T) {
+	target := CarProfile{ID: "target", Category: "suv", Price: 1_000_000}
+	candidates := []CarProfile{
+		{ID: "far", Category: "suv", Price: 2_500_000}, // 3 балла, разница цен 1 500 000
+		{ID: "c", Category: "suv", Price: 2_000_000},   // 3 балла, разница 1 000 000
+		{ID: "van", Category: "van"},                   // 0 баллов — не попадает в список
+		{ID: "best", Category: "suv", Price: 1_000_000},
+		{ID: "a", Category: "suv"}, // 3 балла, разница 1 000 000 — выше "c" по ID
+		target,
+	}
+
+	ids := func(cars []RecommendedCar) []string {
+		out := []string{}
+		for _, c := range cars {
+			out = append(out, c.ID)
+		}
+		return out
+	}
+
+	got := RankSimilar(target, candidates, 10)
+	if want := []string{"best", "a", "c", "far"}; !reflect.DeepEqual(ids(got), want) {
+		t.Fatalf("order = %v, want %v", ids(got), want)
+	}
+	if got[0].Score != 6 || !reflect.DeepEqual(got[0].Reasons, []string{ReasonSameCategory, ReasonSimilarPrice}) {
+		t.Errorf("best = %+v, want score 6 with category and price reasons", got[0])
+	}
+
+	if got := RankSimilar(target, candidates, 2); !reflect.DeepEqual(ids(got), []string{"best", "a"}) {
+		t.Errorf("limit 2 = %v, want [best a]", ids(got))
+	}
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// ограничения полей отзыва; тело запроса дополнительно ограничено в хендлере
+const (
+	ReviewMaxTextRunes  = 5000
+	ReviewMaxModelRunes = 100
+	ReviewMaxEmailLen   = 254
+)
+
+type Review struct {
+	ID        int    `json:"id"`
+	Email     string `json:"email"`
+	Model     string `json:"model"`
+	Rating    int    `json:"rating"`
+	Text      string `json:"text"`
+	CreatedAt string `json:"created_at"`
+}
+
+// ValidateReview проверяет отзыв с сайта; rating 0 — без оценки
+func ValidateReview(rev Review) []string {
+	var errs []string
+	if strings.TrimSpace(rev.Email) == "" || strings.TrimSpace(rev.Text) == "" {
+		errs = append(errs, "email and text are required")
+	}
+	if rev.Email != "" && (len(rev.Email) > ReviewMaxEmailLen || !ValidateEmail(rev.Email)) {
+		errs = append(errs, "invalid email")
+	}
+	if utf8.RuneCountInString(rev.Text) > ReviewMaxTextRunes {
+		errs = append(errs, "text is too long")
+	}
+	if utf8.RuneCountInString(rev.Model) > ReviewMaxModelRunes {
+		errs = append(errs, "model is too long")
+	}
+	if rev.Rating < 0 || rev.Rating > 5 {
+		errs = append(errs, "rating must be between 0 and 5")
+	}
+	return errs
+}
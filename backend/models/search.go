@@ -0,0 +1,181 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SearchResult — найденный автомобиль; Snippet — HTML-фрагмент с <mark> вокруг совпадений
+type SearchResult struct {
+	ID       string  `json:"id"`
+	Title    string  `json:"title"`
+	Category string  `json:"category"`
+	Image    string  `json:"image"`
+	Price    int     `json:"price"`
+	Snippet  string  `json:"snippet"`
+	Score    float64 `json:"score"` // чем больше, тем релевантнее
+}
+
+// SearchResponse — ответ GET /api/search
+type SearchResponse struct {
+	Query   string         `json:"query"`
+	Total   int            `json:"total"`
+	Loose   bool           `json:"loose"` // не нашлось всех слов сразу — показаны совпадения по любому
+	Results []SearchResult `json:"results"`
+}
+
+// SearchTokens разбивает текст на слова в нижнем регистре, ё заменяется на е
+func SearchTokens(s string) []string {
+	s = strings.NewReplacer("ё", "е", "Ё", "е").Replace(strings.ToLower(s))
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// окончания, которые отбрасываются при поиске; длинные проверяются первыми
+var ruEndings = []string{
+	"иями", "ями", "ами", "ого", "его", "ому", "ему", "ыми", "ими",
+	"ая", "яя", "ое", "ее", "ые", "ие", "ый", "ий", "ой", "ей", "ом", "ем",
+	"ам", "ям", "ах", "ях", "ую", "юю", "ов", "ев", "ть",
+	"а", "я", "о", "е", "ы", "и", "у", "ю", "ь", "й",
+}
+
+// StemRu — упрощённый стеммер: отрезает падежное окончание, оставляя
+// основу не короче трёх букв. Основа ищется префиксом, поэтому «полный»
+// находит «полного» и «полным».
+func StemRu(word string) string {
+	for _, end := range ruEndings {
+		if strings.HasSuffix(word, end) && utf8.RuneCountInString(word)-utf8.RuneCountInString(end) >= 3 {
+			return strings.TrimSuffix(word, end)
+		}
+	}
+	return word
+}
+
+// SearchMatchQuery строит запрос FTS5 MATCH из пользовательской строки: каждое слово
+// превращается в префикс своей основы. all — все слова обязательны (AND), иначе любое (OR).
+func SearchMatchQuery(tokens []string, all bool) string {
+	terms := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		// в токенах только буквы и цифры, кавычки нужны, чтобы FTS5 не принял слово за оператор
+		terms = append(terms, `"`+StemRu(t)+`"*`)
+	}
+	if all {
+		return strings.Join(terms, " AND ")
+	}
+	return strings.Join(terms, " OR ")
+}
+
+// Vocabulary — слова опубликованного каталога с числом автомобилей, где они встречаются
+type Vocabulary map[string]int
+
+// Add учитывает слова одного автомобиля
+func (v Vocabulary) Add(texts ...string) {
+	seen := map[string]bool{}
+	for _, text := range texts {
+		for _, t := range SearchTokens(text) {
+			if utf8.RuneCountInString(t) < 3 || seen[t] {
+				continue
+			}
+			seen[t] = true
+			v[t]++
+		}
+	}
+}
+
+// Suggest подбирает продолжения последнего слова запроса с учётом опечаток
+// и возвращает до limit вариантов всего запроса целиком
+func (v Vocabulary) Suggest(query string, limit int) []string {
+	tokens := SearchTokens(query)
+	if len(tokens) == 0 {
+		return []string{}
+	}
+	last := tokens[len(tokens)-1]
+	prefix := strings.Join(tokens[:len(tokens)-1], " ")
+	lastLen := utf8.RuneCountInString(last)
+
+	// допустимое число опечаток растёт с длиной слова
+	maxTypos := 0
+	switch {
+	case lastLen >= 7:
+		maxTypos = 2
+	case lastLen >= 4:
+		maxTypos = 1
+	}
+
+	type candidate struct {
+		word string
+		dist int
+		docs int
+	}
+	var candidates []candidate
+	for word, docs := range v {
+		// сравниваем с началом слова: пользователь мог ещё не дописать его,
+		// а из-за пропущенной или лишней буквы начало может быть на символ длиннее или короче
+		r := []rune(word)
+		dist := maxTypos + 1
+		for n := lastLen - 1; n <= lastLen+1; n++ {
+			if n > 0 && n <= len(r) {
+				dist = min(dist, levenshtein(last, string(r[:n])))
+			}
+		}
+		if dist > maxTypos {
+			continue
+		}
+		candidates = append(candidates, candidate{word, dist, docs})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.dist != b.dist {
+			return a.dist < b.dist
+		}
+		if a.docs != b.docs {
+			return a.docs > b.docs
+		}
+		return a.word < b.word
+	})
+
+	// из форм одного слова («привод», «приводом») оставляем самую подходящую
+	suggestions := []string{}
+	stems := map[string]bool{}
+	for _, c := range candidates {
+		if len(suggestions) == limit {
+			break
+		}
+		stem := StemRu(c.word)
+		if stems[stem] {
+			continue
+		}
+		stems[stem] = true
+		s := c.word
+		if prefix != "" {
+			s = prefix + " " + c.word
+		}
+		suggestions = append(suggestions, s)
+	}
+	return suggestions
+}
+
+// levenshtein — расстояние редактирования между словами (по символам, не байтам)
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
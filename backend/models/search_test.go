@@ -0,0 +1,92 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStemRu(t *testing.T) {
+	tests := []struct {
+		name  string
+		forms []string
+		want  string
+	}{
+		{"adjective cases", []string{"полный", "полного", "полному", "полной", "полную", "полные"}, "полн"},
+		{"noun cases", []string{"привод", "привода", "приводом", "приводе", "приводами"}, "привод"},
+		{"plural", []string{"седан", "седаны", "седанов"}, "седан"},
+		{"stem keeps three letters", []string{"ока"}, "ока"},
+		{"latin is untouched", []string{"duster"}, "duster"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, f := range tt.forms {
+				if got := StemRu(f); got != tt.want {
+					t.Errorf("StemRu(%q) = %q, want %q", f, got, tt.want)
+				}
+			}
+		})
+	}
+
+	// окончания -ым нет в списке: такие формы находятся префиксным поиском по основе
+	if stem := StemRu("полный"); !strings.HasPrefix("полным", stem) {
+		t.Errorf("stem %q is not a prefix of полным", stem)
+	}
+}
+
+func TestSearchMatchQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		all   bool
+		want  string
+	}{
+		{"all words", "Полный привод", true, `"полн"* AND "привод"*`},
+		{"any word", "полный привод", false, `"полн"* OR "привод"*`},
+		{"ё is folded", "ёмкий", true, `"емк"*`},
+		{"quotes and operators are plain words", `"duster" OR NEAR(logan, 2) -x* ^arkana:`, true,
+			`"duster"* AND "or"* AND "near"* AND "logan"* AND "2"* AND "x"* AND "arkana"*`},
+		{"only punctuation", `"" * ( ) :`, true, ``},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SearchMatchQuery(SearchTokens(tt.query), tt.all); got != tt.want {
+				t.Errorf("SearchMatchQuery(%q) = %s, want %s", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVocabularySuggest(t *testing.T) {
+	v := Vocabulary{}
+	v.Add("Renault Duster", "полный привод")
+	v.Add("Renault Logan", "передний привод")
+	v.Add("Renault Arkana", "полный привод", "Полный, полный") // повтор в одном автомобиле считается один раз
+	v.Add("Renault Kaptur", "управление приводом")
+
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		want  []string
+	}{
+		{"prefix of a word", "дуст", 5, []string{}},
+		{"unfinished word", "dust", 5, []string{"duster"}},
+		{"typo", "dustr", 5, []string{"duster"}},
+		{"short word needs an exact prefix", "пал", 5, []string{}},
+		{"earlier words are kept", "Renault пол", 5, []string{"renault полный"}},
+		{"more cars first, one form per stem", "п", 10, []string{"привод", "полный", "передний"}},
+		{"limit", "п", 2, []string{"привод", "полный"}},
+		{"empty query", " ,", 5, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := v.Suggest(tt.query, tt.limit); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Suggest(%q, %d) = %v, want %v", tt.query, tt.limit, got, tt.want)
+			}
+		})
+	}
+	if v["полный"] != 2 {
+		t.Errorf("полный counted in %d cars, want 2", v["полный"])
+	}
+}
@@ -0,0 +1,285 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// типы значений характеристик
+const (
+	SpecNumber = "number"
+	SpecEnum   = "enum"
+)
+
+// SpecOption — допустимое значение перечислимой характеристики
+type SpecOption struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+}
+
+// SpecDef — типизированная характеристика. Значение выводится из текстовых
+// характеристик и особенностей, названия которых начинаются с одного из Sources.
+type SpecDef struct {
+	Key     string       `json:"key"`
+	Label   string       `json:"label"`
+	Type    string       `json:"type"`
+	Unit    string       `json:"unit,omitempty"`
+	Options []SpecOption `json:"options,omitempty"`
+	Sources []string     `json:"sources"`
+
+	parse func(text string) (any, bool) // значение по тексту характеристики из Sources
+	loose func(text string) (any, bool) // значение по любому тексту (особенности без названия)
+}
+
+// SpecValues — значения типизированных характеристик автомобиля: float64 для чисел, string для перечислений
+type SpecValues map[string]any
+
+const num = `(\d+(?:[.,]\d+)?)`
+
+// numberSpec разбирает первое число перед единицей измерения unitRe.
+// Для диапазонов вида «1-1.5 т» берётся верхняя граница.
+func numberSpec(unitRe string, scale map[string]float64) func(string) (any, bool) {
+	re := regexp.MustCompile(num + `(?:\s*[-–]\s*` + num + `)?\s*(` + unitRe + `)`)
+	return func(text string) (any, bool) {
+		m := re.FindStringSubmatch(text)
+		if m == nil {
+			return nil, false
+		}
+		raw := m[1]
+		if m[2] != "" {
+			raw = m[2]
+		}
+		v, err := strconv.ParseFloat(strings.ReplaceAll(raw, ",", "."), 64)
+		if err != nil {
+			return nil, false
+		}
+		if k, ok := scale[m[3]]; ok {
+			v *= k
+		}
+		return v, true
+	}
+}
+
+// enumSpec ищет в тексте ключевые слова; порядок правил важен — первое совпадение выигрывает
+func enumSpec(rules ...[2]string) func(string) (any, bool) {
+	return func(text string) (any, bool) {
+		for _, r := range rules {
+			if strings.Contains(text, r[0]) {
+				return r[1], true
+			}
+		}
+		return nil, false
+	}
+}
+
+// SpecSchema — все типизированные характеристики каталога
+var SpecSchema = []SpecDef{
+	{Key: "power_hp", Label: "Мощность", Type: SpecNumber, Unit: "л.с.",
+		Sources: []string{"мощность", "двигатель"}, parse: numberSpec(`л\.?\s*с`, nil)},
+	{Key: "engine_volume_l", Label: "Объём двигателя", Type: SpecNumber, Unit: "л",
+		Sources: []string{"объем двигателя", "двигатель"},
+		// «1.6 л, 82 л.с.»: после «л» не должно быть точки, иначе это «л.с.»
+		parse: numberSpec(`л(?:[^а-я/.]|$)`, nil)},
+	{Key: "fuel_consumption", Label: "Расход топлива", Type: SpecNumber, Unit: "л/100км",
+		Sources: []string{"расход"}, parse: numberSpec(`л\s*/\s*100`, nil)},
+	{Key: "acceleration_s", Label: "Разгон 0–100 км/ч", Type: SpecNumber, Unit: "с",
+		Sources: []string{"разгон"}, parse: numberSpec(`сек`, nil)},
+	{Key: "boot_volume_l", Label: "Объём багажника", Type: SpecNumber, Unit: "л",
+		Sources: []string{"объем багажника"}, parse: numberSpec(`л`, nil)},
+	{Key: "cargo_volume_m3", Label: "Объём грузового отсека", Type: SpecNumber, Unit: "м³",
+		Sources: []string{"объем"}, parse: numberSpec(`м³|м3`, nil)},
+	{Key: "payload_kg", Label: "Грузоподъёмность", Type: SpecNumber, Unit: "кг",
+		Sources: []string{"грузоподъемность"}, parse: numberSpec(`кг|т`, map[string]float64{"т": 1000})},
+	{Key: "clearance_mm", Label: "Клиренс", Type: SpecNumber, Unit: "мм",
+		Sources: []string{"клиренс"}, parse: numberSpec(`мм`, nil)},
+	{Key: "range_km", Label: "Запас хода", Type: SpecNumber, Unit: "км",
+		Sources: []string{"запас хода"}, parse: numberSpec(`км`, nil)},
+	{Key: "battery_kwh", Label: "Ёмкость батареи", Type: SpecNumber, Unit: "кВт·ч",
+		Sources: []string{"батарея", "емкость батареи"}, parse: numberSpec(`квт\s*[·*.]?\s*ч`, nil)},
+	{Key: "seats", Label: "Количество мест", Type: SpecNumber,
+		Sources: []string{"количество мест"}, parse: numberSpec(`$`, nil)},
+	{Key: "drive_type", Label: "Привод", Type: SpecEnum,
+		Options: []SpecOption{{"fwd", "Передний"}, {"rwd", "Задний"}, {"awd", "Полный"}},
+		Sources: []string{"привод"},
+		parse: enumSpec([2]string{"полн", "awd"}, [2]string{"4x4", "awd"}, [2]string{"4х4", "awd"},
+			[2]string{"передн", "fwd"}, [2]string{"задн", "rwd"}),
+		loose: enumSpec([2]string{"полный привод", "awd"}, [2]string{"4x4", "awd"}, [2]string{"4х4", "awd"},
+			[2]string{"передний привод", "fwd"}, [2]string{"задний привод", "rwd"})},
+	{Key: "transmission", Label: "Коробка передач", Type: SpecEnum,
+		Options: []SpecOption{{"manual", "Механическая"}, {"automatic", "Автоматическая"},
+			{"cvt", "Вариатор"}, {"robot", "Робот"}},
+		Sources: []string{"коробка передач", "кпп", "трансмиссия"},
+		parse: enumSpec([2]string{"вариатор", "cvt"}, [2]string{"cvt", "cvt"}, [2]string{"робот", "robot"},
+			[2]string{"мкпп", "manual"}, [2]string{"механ", "manual"},
+			[2]string{"акпп", "automatic"}, [2]string{"автомат", "automatic"}),
+		loose: enumSpec([2]string{"вариатор", "cvt"}, [2]string{"мкпп", "manual"},
+			[2]string{"акпп", "automatic"}, [2]string{"робот", "robot"})},
+	{Key: "fuel_type", Label: "Тип двигателя", Type: SpecEnum,
+		Options: []SpecOption{{"petrol", "Бензин"}, {"diesel", "Дизель"},
+			{"hybrid", "Гибрид"}, {"electric", "Электро"}},
+		Sources: []string{"двигатель", "топливо", "тип двигателя"},
+		// объём в литрах без других пометок — бензиновый двигатель
+		parse: func(text string) (any, bool) {
+			if v, ok := enumSpec([2]string{"дизел", "diesel"}, [2]string{"гибрид", "hybrid"},
+				[2]string{"электро", "electric"}, [2]string{"бензин", "petrol"})(text); ok {
+				return v, true
+			}
+			if _, ok := numberSpec(`л(?:[^а-я/.]|$)`, nil)(text); ok {
+				return "petrol", true
+			}
+			return nil, false
+		},
+		loose: enumSpec([2]string{"дизельный двигатель", "diesel"}, [2]string{"гибридная система", "hybrid"})},
+}
+
+// SpecDefByKey возвращает описание характеристики или nil
+func SpecDefByKey(key string) *SpecDef {
+	for i := range SpecSchema {
+		if SpecSchema[i].Key == key {
+			return &SpecSchema[i]
+		}
+	}
+	return nil
+}
+
+// digitGroups — число с разрядами через пробел, неразрывный или узкий пробел: «1 200», «1 600 000»
+var digitGroups = regexp.MustCompile(`(^|[^\d.,])(\d{1,3}(?:[ \x{00A0}\x{202F}]\d{3})+)\b`)
+
+var digitGroupSeparators = strings.NewReplacer(" ", "", "\u00a0", "", "\u202f", "")
+
+// normalizeSpecText приводит текст к нижнему регистру, ё к е и склеивает разряды чисел,
+// иначе из «до 1 200 л» разбиралось бы 200
+func normalizeSpecText(s string) string {
+	s = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), "ё", "е")
+	return digitGroups.ReplaceAllStringFunc(s, func(m string) string {
+		sub := digitGroups.FindStringSubmatch(m)
+		return sub[1] + digitGroupSeparators.Replace(sub[2])
+	})
+}
+
+// ParseSpecValues выводит типизированные значения из текстовых характеристик.
+// Технические характеристики приоритетнее особенностей («Мощность: 114 л.с.»);
+// особенности без названия («Полный привод 4x4») разбираются только правилами loose.
+func ParseSpecValues(category string, features []string, techSpecs []CatalogSpec) SpecValues {
+	var pairs [][2]string
+	for _, s := range techSpecs {
+		pairs = append(pairs, [2]string{normalizeSpecText(s.Name), normalizeSpecText(s.Value)})
+	}
+	var free []string
+	for _, f := range features {
+		if name, value, ok := strings.Cut(f, ":"); ok {
+			pairs = append(pairs, [2]string{normalizeSpecText(name), normalizeSpecText(value)})
+		} else {
+			free = append(free, normalizeSpecText(f))
+		}
+	}
+
+	values := SpecValues{}
+	for _, def := range SpecSchema {
+		if v, ok := def.fromPairs(pairs); ok {
+			values[def.Key] = v
+			continue
+		}
+		if def.loose == nil {
+			continue
+		}
+		for _, text := range free {
+			if v, ok := def.loose(text); ok {
+				values[def.Key] = v
+				break
+			}
+		}
+	}
+
+	// у электромобилей и гибридов тип двигателя понятен из категории
+	if _, ok := values["fuel_type"]; !ok {
+		switch c := normalizeSpecText(category); {
+		case strings.HasPrefix(c, "электро"):
+			values["fuel_type"] = "electric"
+		case strings.HasPrefix(c, "гибрид"):
+			values["fuel_type"] = "hybrid"
+		}
+	}
+	return values
+}
+
+func (d SpecDef) fromPairs(pairs [][2]string) (any, bool) {
+	for _, p := range pairs {
+		for _, src := range d.Sources {
+			if strings.HasPrefix(p[0], src) {
+				if v, ok := d.parse(p[1]); ok {
+					return v, true
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+// SpecFilter — условие на типизированную характеристику
+type SpecFilter struct {
+	Key    string
+	Min    *float64 // для чисел: <key>_min
+	Max    *float64 // для чисел: <key>_max
+	Values []string // для перечислений: <key>=a,b — любое из значений
+}
+
+// ParseSpecFilters читает фильтры из query-параметров по схеме SpecSchema
+func ParseSpecFilters(q url.Values) ([]SpecFilter, []string) {
+	var filters []SpecFilter
+	var errs []string
+	for _, def := range SpecSchema {
+		switch def.Type {
+		case SpecNumber:
+			f := SpecFilter{Key: def.Key}
+			for _, bound := range []struct {
+				param string
+				dst   **float64
+			}{{def.Key + "_min", &f.Min}, {def.Key + "_max", &f.Max}} {
+				raw := q.Get(bound.param)
+				if raw == "" {
+					continue
+				}
+				v, err := strconv.ParseFloat(strings.ReplaceAll(raw, ",", "."), 64)
+				if err != nil {
+					errs = append(errs, fmt.Sprintf("%s должен быть числом", bound.param))
+					continue
+				}
+				*bound.dst = &v
+			}
+			if f.Min != nil || f.Max != nil {
+				filters = append(filters, f)
+			}
+		case SpecEnum:
+			raw := q.Get(def.Key)
+			if raw == "" {
+				continue
+			}
+			f := SpecFilter{Key: def.Key}
+			for _, v := range strings.Split(raw, ",") {
+				v = strings.TrimSpace(v)
+				if !def.hasOption(v) {
+					errs = append(errs, fmt.Sprintf("%s: неизвестное значение %q", def.Key, v))
+					continue
+				}
+				f.Values = append(f.Values, v)
+			}
+			if len(f.Values) > 0 {
+				filters = append(filters, f)
+			}
+		}
+	}
+	return filters, errs
+}
+
+func (d SpecDef) hasOption(v string) bool {
+	for _, o := range d.Options {
+		if o.Value == v {
+			return true
+		}
+	}
+	return false
+}
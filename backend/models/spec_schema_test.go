@@ -0,0 +1,92 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSpecValues(t *testing.T) {
+	tests := []struct {
+		name      string
+		category  string
+		features  []string
+		techSpecs []CatalogSpec
+		want      SpecValues
+	}{
+		{name: "power from a feature",
+			features: []string{"Мощность: 114 л.с."},
+			want:     SpecValues{"power_hp": 114.0}},
+		{name: "fuel consumption",
+			features: []string{"Расход: 6.1 л/100км"},
+			want:     SpecValues{"fuel_consumption": 6.1}},
+		{name: "engine volume and power in one value",
+			techSpecs: []CatalogSpec{{Name: "Двигатель", Value: "1.6 л, 82 л.с."}},
+			want:      SpecValues{"engine_volume_l": 1.6, "power_hp": 82.0, "fuel_type": "petrol"}},
+		{name: "tech specs win over features",
+			features:  []string{"Мощность: 90 л.с."},
+			techSpecs: []CatalogSpec{{Name: "Мощность", Value: "114 л.с."}},
+			want:      SpecValues{"power_hp": 114.0}},
+		{name: "free features use loose rules",
+			features: []string{"Полный привод 4x4", "Вариатор X-Tronic"},
+			want:     SpecValues{"drive_type": "awd", "transmission": "cvt"}},
+		{name: "transmission and clearance",
+			techSpecs: []CatalogSpec{{Name: "Коробка передач", Value: "МКПП-5"}, {Name: "Клиренс", Value: "205 мм"}},
+			want:      SpecValues{"transmission": "manual", "clearance_mm": 205.0}},
+		{name: "ё in the name",
+			features: []string{"Объём багажника: 475 л"},
+			want:     SpecValues{"boot_volume_l": 475.0}},
+		{name: "range takes the upper bound, tonnes to kg",
+			features: []string{"Грузоподъемность: 1-1.5 т"},
+			want:     SpecValues{"payload_kg": 1500.0}},
+		{name: "electric car by category",
+			category: "Электромобили",
+			features: []string{"Запас хода: 400 км", "Батарея: 52 кВт·ч"},
+			want:     SpecValues{"range_km": 400.0, "battery_kwh": 52.0, "fuel_type": "electric"}},
+
+		// разряды через пробел
+		{name: "thousands with a space",
+			features: []string{"Объем багажника: до 1 200 л"},
+			want:     SpecValues{"boot_volume_l": 1200.0}},
+		{name: "thousands with a non-breaking space",
+			features: []string{"Объем багажника: 1\u00a0200 л"},
+			want:     SpecValues{"boot_volume_l": 1200.0}},
+		{name: "thousands with a narrow space",
+			features: []string{"Запас хода: 1\u202f050 км"},
+			want:     SpecValues{"range_km": 1050.0}},
+		{name: "grouped range",
+			features: []string{"Грузоподъемность: 1 000 - 1 250 кг"},
+			want:     SpecValues{"payload_kg": 1250.0}},
+		{name: "several groups",
+			techSpecs: []CatalogSpec{{Name: "Объем багажника", Value: "1 000 000 л"}},
+			want:      SpecValues{"boot_volume_l": 1000000.0}},
+		{name: "separate numbers are not joined",
+			features: []string{"Количество мест: 2 5"},
+			want:     SpecValues{"seats": 5.0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseSpecValues(tt.category, tt.features, tt.techSpecs)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSpecValues = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeSpecText(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"До 1 200 Л", "до 1200 л"},
+		{"1 600 000 ₽", "1600000 ₽"},
+		{"1.6 л, 82 л.с.", "1.6 л, 82 л.с."},
+		{"12 345", "12345"},
+		{"1234 567", "1234 567"},     // группа длиннее трёх цифр — это два числа
+		{"1 2345", "1 2345"},         // и после пробела должно быть ровно три цифры
+		{"2.5 100 км", "2.5 100 км"}, // дробная часть не склеивается со следующим числом
+	}
+	for _, tt := range tests {
+		if got := normalizeSpecText(tt.in); got != tt.want {
+			t.Errorf("normalizeSpecText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	TradeInSubmitted = "submitted" // ждёт оценки менеджером
+	TradeInOffered   = "offered"   // менеджер выставил финальное предложение
+	TradeInAccepted  = "accepted"
+	TradeInRejected  = "rejected"
+	TradeInApplied   = "applied" // зачтено в счёт покупки
+)
+
+// Коэффициенты состояния автомобиля
+var TradeInConditions = map[string]float64{
+	"excellent": 1.05,
+	"good":      1.0,
+	"fair":      0.9,
+	"poor":      0.75,
+}
+
+const (
+	tradeInYearlyMileageNorm = 15000 // км в год, сверх нормы — штраф
+	tradeInRangeSpreadPct    = 7     // разброс предварительной оценки, ±%
+)
+
+type TradeInRequest struct {
+	ID          int        `json:"id"`
+	UserID      string     `json:"userId"`            // корзина, в которую зачитывается предложение
+	Account     string     `json:"account,omitempty"` // владелец заявки, пользователь из JWT
+	Make        string     `json:"make"`
+	Model       string     `json:"model"`
+	Year        int        `json:"year"`
+	Mileage     int        `json:"mileage"`
+	Condition   string     `json:"condition"`
+	Comment     string     `json:"comment,omitempty"`
+	EstimateMin int        `json:"estimateMin"`
+	EstimateMax int        `json:"estimateMax"`
+	FinalOffer  *int       `json:"finalOffer,omitempty"`
+	ManagerNote string     `json:"managerNote,omitempty"`
+	ReviewedBy  string     `json:"reviewedBy,omitempty"`
+	Status      string     `json:"status"`
+	Photos      []string   `json:"photos"` // имена файлов, см. GET /api/trade-in/{id}/photos/{name}
+	CreatedAt   time.Time  `json:"createdAt"`
+	ReviewedAt  *time.Time `json:"reviewedAt,omitempty"`
+	AppliedAt   *time.Time `json:"appliedAt,omitempty"`
+	OrderID     *int       `json:"orderId,omitempty"` // заказ, в который зачтён trade-in
+}
+
+// DepreciationRule — строка таблицы амортизации, которую ведут админы.
+// Пустая модель означает правило для всей марки.
+type DepreciationRule struct {
+	ID                    int     `json:"id"`
+	Make                  string  `json:"make"`
+	Model                 string  `json:"model"`
+	BasePrice             int     `json:"basePrice"`             // цена нового автомобиля, ₽
+	AnnualDepreciationPct float64 `json:"annualDepreciationPct"` // потеря стоимости в год, %
+	MileagePenaltyPer10k  int     `json:"mileagePenaltyPer10k"`  // ₽ за каждые 10 000 км сверх нормы
+}
+
+// ValidateTradeIn проверяет заявку клиента
+func ValidateTradeIn(t TradeInRequest, now time.Time) []string {
+	var errs []string
+
+	if strings.TrimSpace(t.Make) == "" {
+		errs = append(errs, "make обязателен")
+	}
+	if strings.TrimSpace(t.Model) == "" {
+		errs = append(errs, "model обязателен")
+	}
+	if t.Year < 1980 || t.Year > now.Year() {
+		errs = append(errs, "некорректный год выпуска")
+	}
+	if t.Mileage < 0 {
+		errs = append(errs, "пробег не может быть отрицательным")
+	}
+	if _, ok := TradeInConditions[t.Condition]; !ok {
+		errs = append(errs, "condition должен быть excellent, good, fair или poor")
+	}
+
+	return errs
+}
+
+// ValidateDepreciationRule проверяет строку таблицы амортизации
+func ValidateDepreciationRule(d DepreciationRule) []string {
+	var errs []string
+
+	if strings.TrimSpace(d.Make) == "" {
+		errs = append(errs, "make обязателен")
+	}
+	if d.BasePrice <= 0 {
+		errs = append(errs, "basePrice должен быть больше нуля")
+	}
+	if d.AnnualDepreciationPct < 0 || d.AnnualDepreciationPct >= 100 {
+		errs = append(errs, "annualDepreciationPct должен быть в диапазоне 0–100%")
+	}
+	if d.MileagePenaltyPer10k < 0 {
+		errs = append(errs, "mileagePenaltyPer10k не может быть отрицательным")
+	}
+
+	return errs
+}
+
+// EstimateTradeIn даёт предварительный диапазон цены по правилу амортизации:
+// базовая цена * (1 - ставка)^возраст * коэффициент состояния - штраф за перепробег.
+func EstimateTradeIn(t TradeInRequest, rule DepreciationRule, now time.Time) (min, max int) {
+	age := now.Year() - t.Year
+	if age < 0 {
+		age = 0
+	}
+
+	value := float64(rule.BasePrice) * math.Pow(1-rule.AnnualDepreciationPct/100, float64(age))
+	value *= TradeInConditions[t.Condition]
+
+	norm := tradeInYearlyMileageNorm * (age + 1)
+	if over := t.Mileage - norm; over > 0 {
+		value -= float64(over/10000) * float64(rule.MileagePenaltyPer10k)
+	}
+	if value < 0 {
+		value = 0
+	}
+
+	spread := value * tradeInRangeSpreadPct / 100
+	// округляем до тысячи, как принято в оценках
+	min = int(math.Round((value-spread)/1000)) * 1000
+	max = int(math.Round((value+spread)/1000)) * 1000
+	return min, max
+}
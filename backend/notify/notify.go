@@ -0,0 +1,82 @@
+// Package notify доставляет уведомления пользователям через подключаемые каналы
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Notification — уведомление, готовое к отправке
+type Notification struct {
+	ID        int             `json:"id"`
+	UserID    string          `json:"userId"`
+	Email     string          `json:"email,omitempty"`
+	Kind      string          `json:"kind"`
+	Subject   string          `json:"subject"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// Notifier — канал доставки (почта, SMS, push…). Реализации должны быть потокобезопасны.
+type Notifier interface {
+	Send(ctx context.Context, n Notification) error
+}
+
+// New возвращает канал по имени из конфигурации: "log" (в logger) или "file"
+func New(sink, filePath string, logger *slog.Logger) (Notifier, error) {
+	switch sink {
+	case "", "log":
+		return NewLogNotifier(logger), nil
+	case "file":
+		return NewFileNotifier(filePath), nil
+	}
+	return nil, fmt.Errorf("unknown notification sink %q", sink)
+}
+
+// LogNotifier просто пишет уведомление в лог — для разработки
+type LogNotifier struct {
+	logger *slog.Logger
+}
+
+func NewLogNotifier(logger *slog.Logger) LogNotifier {
+	return LogNotifier{logger: logger}
+}
+
+func (l LogNotifier) Send(_ context.Context, n Notification) error {
+	l.logger.Info("notification", "kind", n.Kind, "user", n.UserID, "subject", n.Subject, "payload", string(n.Payload))
+	return nil
+}
+
+// FileNotifier дописывает уведомления в файл, по одному JSON на строку
+type FileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{path: path}
+}
+
+func (f *FileNotifier) Send(_ context.Context, n Notification) error {
+	line, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"net/http"
+
+	"renault-backend/apidoc"
+	"renault-backend/handlers"
+	"renault-backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+// specPath — где лежит сохранённая спецификация; её сверяет `server openapi check`
+const specPath = "docs/openapi.json"
+
+// ответы хендлеров автомобилей; общие тела — в handlers (StatusResponse и др.)
+
+type carCreatedResponse struct {
+	Status string `json:"status"` // всегда "created"
+	ID     string `json:"id"`
+}
+
+type carVersionResponse struct {
+	Status  string `json:"status"` // всегда "updated"
+	Version int    `json:"version"`
+}
+
+// mainOperations — маршруты, которые обслуживают хендлеры из main
+func mainOperations() []apidoc.Operation {
+	ops := []apidoc.Operation{
+		{Method: http.MethodGet, Path: "/metrics", Tag: "Служебные", Summary: "Метрики Prometheus",
+			Description: "Нужен заголовок Authorization: Bearer <METRICS_TOKEN>; без токена в конфигурации — всегда 401.",
+			Responses:   []apidoc.Response{{Status: http.StatusOK, Body: "", ContentType: "text/plain"}}, Error: apidoc.TextError{}},
+		{Method: http.MethodGet, Path: "/api/openapi.json", Tag: "Служебные", Summary: "Эта спецификация",
+			Responses: apidoc.OK(map[string]any{})},
+		{Method: http.MethodGet, Path: "/api/docs", Tag: "Служебные", Summary: "Переход на /api/docs/",
+			Responses: []apidoc.Response{{Status: http.StatusMovedPermanently}}, Error: apidoc.TextError{}},
+		{Method: http.MethodGet, Path: "/api/docs/", Tag: "Служебные", Summary: "Страница документации",
+			Responses: []apidoc.Response{{Status: http.StatusOK, Body: "", ContentType: "text/html"}}, Error: apidoc.TextError{}},
+
+		{Method: http.MethodPost, Path: "/api/reviews", Tag: "Отзывы", Summary: "Оставить отзыв",
+			Description: "Ограничено по IP (RATE_LIMIT_REVIEWS). Подозрительные отзывы уходят в очередь модерации (202). " +
+				"formToken берётся из GET /api/form-token, website — ловушка для ботов и должно быть пустым.",
+			Request: struct {
+				Review
+				handlers.SpamFields
+			}{},
+			Responses: []apidoc.Response{
+				{Status: http.StatusCreated, Body: handlers.CreatedResponse{}},
+				{Status: http.StatusAccepted, Description: "Отложен до решения модератора", Body: handlers.HeldResponse{}},
+			},
+			Error: apidoc.TextError{}},
+
+		{Method: http.MethodGet, Path: "/api/cars", Tag: "Каталог", Summary: "Опубликованные автомобили",
+			Description: handlers.CatalogFilterDescription,
+			Query:       handlers.CatalogFilterParams, Responses: apidoc.OK([]Car{}), Error: apidoc.TextError{}},
+		{Method: http.MethodGet, Path: "/api/cars/{id}", Tag: "Каталог", Summary: "Карточка автомобиля",
+			Description: "Версия автомобиля отдаётся в ETag.",
+			Responses:   apidoc.OK(Car{}), Error: apidoc.TextError{}},
+		{Method: http.MethodGet, Path: "/api/cars/spec-schema", Tag: "Каталог", Summary: "Схема характеристик для фильтров",
+			Responses: apidoc.OK(models.SpecSchema), Error: apidoc.TextError{}},
+		{Method: http.MethodGet, Path: "/api/cars/{id}/preview", Tag: "Каталог", Summary: "Предпросмотр по подписанной ссылке",
+			Description: "Автомобиль в любом статусе; ссылку выдаёт POST /api/admin/cars/{id}/preview-link.",
+			Query: []apidoc.Param{
+				{Name: "expires", Type: "integer", Description: "срок действия, unix", Required: true},
+				{Name: "sig", Type: "string", Description: "подпись", Required: true},
+			},
+			Responses: apidoc.OK(Car{}), Error: apidoc.TextError{}},
+
+		{Method: http.MethodGet, Path: "/api/admin/cars", Tag: "Администрирование", Admin: true, Summary: "Все автомобили, кроме удалённых",
+			Query:     []apidoc.Param{apidoc.Q("status", "string", "draft, published или archived")},
+			Responses: apidoc.OK([]Car{}), Error: apidoc.TextError{}},
+		{Method: http.MethodPost, Path: "/api/admin/cars", Tag: "Администрирование", Admin: true, Summary: "Создать автомобиль",
+			Description: "Новые модели по умолчанию — черновики.",
+			Request:     Car{},
+			Responses:   []apidoc.Response{{Status: http.StatusCreated, Body: carCreatedResponse{}}},
+			Error:       apidoc.TextError{}},
+		{Method: http.MethodPut, Path: "/api/admin/cars/{id}", Tag: "Администрирование", Admin: true, Summary: "Заменить автомобиль",
+			Description: "Поддерживает If-Match; 412, если версия изменилась.",
+			Request:     Car{}, Responses: apidoc.OK(carVersionResponse{}), Error: apidoc.TextError{}},
+		{Method: http.MethodPatch, Path: "/api/admin/cars/{id}", Tag: "Администрирование", Admin: true, Summary: "Изменить часть полей",
+			Description: "Поддерживает If-Match; 412, если версия изменилась. Неизвестные поля — 400.",
+			Request:     carPatch{}, Responses: apidoc.OK(Car{}), Error: apidoc.TextError{}},
+		{Method: http.MethodDelete, Path: "/api/admin/cars/{id}", Tag: "Администрирование", Admin: true, Summary: "Удалить в корзину",
+			Responses: apidoc.OK(handlers.StatusResponse{}), Error: apidoc.TextError{}},
+	}
+	return append(ops, handlers.APIOperations()...)
+}
+
+// buildAPISpec собирает спецификацию из описаний маршрутов
+func buildAPISpec() ([]byte, error) {
+	return apidoc.Marshal(apidoc.Build(apidoc.Info{
+		Title:       "Renault API",
+		Version:     "1.0",
+		Description: "Каталог, корзина, заявки и администрирование. Администраторские маршруты (/api/admin) требуют JWT из POST /api/login.",
+	}, mainOperations()))
+}
+
+// checkAPISpec — расхождения между роутером и описанием маршрутов
+func checkAPISpec(router *mux.Router) ([]string, error) {
+	routes, err := apidoc.Routes(router)
+	if err != nil {
+		return nil, err
+	}
+	return apidoc.Check(routes, mainOperations()), nil
+}
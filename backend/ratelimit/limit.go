@@ -0,0 +1,59 @@
+// Package ratelimit — ограничение частоты запросов к публичным маршрутам по алгоритму
+// token bucket: у каждого клиента маршрута своё ведро, которое равномерно пополняется
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit — не больше Requests запросов за Period; всплеск до Requests запросов подряд,
+// дальше по одному запросу каждые Period/Requests
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Enabled — false для нулевого лимита (ограничение выключено)
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
+
+// interval — через сколько в ведре появляется один запрос
+func (l Limit) interval() time.Duration {
+	return l.Period / time.Duration(l.Requests)
+}
+
+// String — в формате ParseLimit
+func (l Limit) String() string {
+	if !l.Enabled() {
+		return "off"
+	}
+	return strconv.Itoa(l.Requests) + "/" + l.Period.String()
+}
+
+// ParseLimit разбирает лимит из конфигурации: "10/1m", "5/1h"; "off" или "" — без ограничения
+func ParseLimit(s string) (Limit, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "off" {
+		return Limit{}, nil
+	}
+	n, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("rate limit %q: expected <requests>/<period>, e.g. 10/1m", s)
+	}
+	requests, err := strconv.Atoi(n)
+	if err != nil || requests <= 0 {
+		return Limit{}, fmt.Errorf("rate limit %q: requests must be a positive number", s)
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("rate limit %q: invalid period", s)
+	}
+	if d/time.Duration(requests) <= 0 {
+		return Limit{}, fmt.Errorf("rate limit %q: too many requests for the period", s)
+	}
+	return Limit{Requests: requests, Period: d}, nil
+}
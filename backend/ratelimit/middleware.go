@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"time"
+
+	"renault-backend/logging"
+	"renault-backend/realip"
+)
+
+// KeyFunc — по какому признаку различать клиентов; ip — адрес клиента (realip.Resolver).
+// Ключ должен опираться на то, что клиент не подменит сам: X-User-Id публичных маршрутов
+// ничем не подтверждён, поэтому для них — только адрес.
+type KeyFunc func(r *http.Request, ip string) string
+
+// ByIP — отдельное ведро на каждый адрес. IPv6-клиенту обычно выдаётся целая сеть /64,
+// и менять адрес внутри неё он может без ограничений — поэтому ведро одно на всю сеть.
+func ByIP(_ *http.Request, ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return "ip:" + ip
+	}
+	addr = addr.Unmap() // ::ffff:203.0.113.7 — тот же IPv4-клиент
+	if addr.Is4() {
+		return "ip:" + addr.String()
+	}
+	prefix, _ := addr.WithZone("").Prefix(64)
+	return "ip:" + prefix.String()
+}
+
+// Limiter применяет лимиты маршрутов к запросам
+type Limiter struct {
+	store Store
+	ips   *realip.Resolver
+}
+
+func NewLimiter(store Store, ips *realip.Resolver) *Limiter {
+	return &Limiter{store: store, ips: ips}
+}
+
+// Wrap ограничивает маршрут name лимитом limit. Ответ получает заголовки RateLimit-*,
+// а при исчерпании лимита — 429 с Retry-After. Ошибка хранилища запрос не блокирует.
+func (l *Limiter) Wrap(name string, limit Limit, key KeyFunc, next http.Handler) http.Handler {
+	if !limit.Enabled() {
+		return next
+	}
+	policy := strconv.Itoa(limit.Requests) + ";w=" + strconv.Itoa(int(limit.Period/time.Second))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// preflight CORS не считаем: его шлёт браузер перед каждым настоящим запросом
+		if r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+		d, err := l.store.Take(r.Context(), name+"|"+key(r, l.ips.ClientIP(r)), limit)
+		if err != nil {
+			logging.FromContext(r.Context()).Warn("rate limit store unavailable, request allowed",
+				"limit", name, "err", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h := w.Header()
+		h.Set("RateLimit-Policy", policy)
+		h.Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+		h.Set("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+		h.Set("RateLimit-Reset", seconds(d.ResetAfter))
+		if !d.Allowed {
+			h.Set("Retry-After", seconds(d.RetryAfter))
+			h.Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error": "too many requests, try again later"}`))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// seconds — целые секунды с округлением вверх: «0» клиент понял бы как «можно сразу»
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,23 @@
+package ratelimit
+
+import "testing"
+
+func TestByIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{"203.0.113.7", "ip:203.0.113.7"},
+		{"::ffff:203.0.113.7", "ip:203.0.113.7"},
+		{"2001:db8:1:2:aaaa::1", "ip:2001:db8:1:2::/64"},
+		{"2001:db8:1:2:ffff:ffff:ffff:ffff", "ip:2001:db8:1:2::/64"}, // та же сеть /64 — то же ведро
+		{"2001:db8:1:3::1", "ip:2001:db8:1:3::/64"},
+		{"fe80::1%eth0", "ip:fe80::/64"},
+		{"not-an-ip", "ip:not-an-ip"},
+	}
+	for _, tt := range tests {
+		if got := ByIP(nil, tt.ip); got != tt.want {
+			t.Errorf("ByIP(%q) = %q, want %q", tt.ip, got, tt.want)
+		}
+	}
+}
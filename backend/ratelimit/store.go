@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Decision — ответ хранилища на очередной запрос клиента
+type Decision struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter — когда появится следующий запрос (для отказа)
+	RetryAfter time.Duration
+	// ResetAfter — когда ведро наполнится полностью
+	ResetAfter time.Duration
+}
+
+// Store хранит вёдра клиентов. MemoryStore подходит для одного экземпляра сервера;
+// при нескольких экземплярах за балансировщиком нужна общая реализация (например, в Redis),
+// иначе каждый экземпляр считает лимит отдельно.
+type Store interface {
+	// Take списывает один запрос из ведра key, если он там есть
+	Take(ctx context.Context, key string, limit Limit) (Decision, error)
+}
+
+// bucket хранит момент, когда ведро снова станет полным (GCRA): так состояние — одно число,
+// а не пара «токены + время последнего пополнения»
+type bucket struct {
+	key    string
+	fullAt time.Time
+}
+
+// MemoryStore — вёдра в памяти процесса. Полные вёдра ничем не отличаются от отсутствующих,
+// поэтому их периодически удаляет Sweep (jobs.RateLimitSweep). maxKeys ограничивает память
+// при переборе адресов: когда места нет, вытесняется ведро, к которому дольше всех не обращались.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*list.Element // значения — *bucket
+	lru     *list.List               // спереди — последние обращения
+	maxKeys int
+	now     func() time.Time
+}
+
+func NewMemoryStore(maxKeys int) *MemoryStore {
+	return &MemoryStore{buckets: map[string]*list.Element{}, lru: list.New(), maxKeys: maxKeys, now: time.Now}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Decision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	e, ok := s.buckets[key]
+	if ok {
+		s.lru.MoveToFront(e)
+	} else {
+		if s.maxKeys > 0 && s.lru.Len() >= s.maxKeys {
+			s.removeLocked(s.lru.Back())
+		}
+		e = s.lru.PushFront(&bucket{key: key, fullAt: now})
+		s.buckets[key] = e
+	}
+	return take(e.Value.(*bucket), limit, now), nil
+}
+
+// take — GCRA: каждый запрос отодвигает fullAt на interval; запрос проходит,
+// пока fullAt не ушёл дальше чем на Period вперёд
+func take(b *bucket, limit Limit, now time.Time) Decision {
+	interval := limit.interval()
+	fullAt := b.fullAt
+	if fullAt.Before(now) {
+		fullAt = now
+	}
+	next := fullAt.Add(interval)
+	if next.Sub(now) > limit.Period {
+		return Decision{
+			Allowed:    false,
+			Remaining:  0,
+			RetryAfter: next.Sub(now) - limit.Period,
+			ResetAfter: fullAt.Sub(now),
+		}
+	}
+	b.fullAt = next
+	return Decision{
+		Allowed:    true,
+		Remaining:  int((limit.Period - next.Sub(now)) / interval),
+		ResetAfter: next.Sub(now),
+	}
+}
+
+// Sweep удаляет полные вёдра и возвращает, сколько удалено
+func (s *MemoryStore) Sweep() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sweepLocked(s.now())
+}
+
+func (s *MemoryStore) sweepLocked(now time.Time) int {
+	removed := 0
+	for e := s.lru.Front(); e != nil; {
+		next := e.Next()
+		if !e.Value.(*bucket).fullAt.After(now) {
+			s.removeLocked(e)
+			removed++
+		}
+		e = next
+	}
+	return removed
+}
+
+func (s *MemoryStore) removeLocked(e *list.Element) {
+	delete(s.buckets, e.Value.(*bucket).key)
+	s.lru.Remove(e)
+}
+
+// Len — число вёдер в памяти
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lru.Len()
+}
@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTake(t *testing.T) {
+	limit := Limit{Requests: 3, Period: 3 * time.Second} // один запрос в секунду, запас — три
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := &bucket{fullAt: t0}
+
+	// шаги идут по одному ведру: каждый видит состояние после предыдущих
+	steps := []struct {
+		name string
+		at   time.Duration
+		want Decision
+	}{
+		{"first request", 0, Decision{Allowed: true, Remaining: 2, ResetAfter: time.Second}},
+		{"second request", 0, Decision{Allowed: true, Remaining: 1, ResetAfter: 2 * time.Second}},
+		{"burst exhausted", 0, Decision{Allowed: true, Remaining: 0, ResetAfter: 3 * time.Second}},
+		{"over the limit", 0, Decision{Allowed: false, RetryAfter: time.Second, ResetAfter: 3 * time.Second}},
+		{"denied request does not consume", 500 * time.Millisecond,
+			Decision{Allowed: false, RetryAfter: 500 * time.Millisecond, ResetAfter: 2500 * time.Millisecond}},
+		{"one request recovered", 1500 * time.Millisecond,
+			Decision{Allowed: true, Remaining: 0, ResetAfter: 2500 * time.Millisecond}},
+		{"full bucket after idle", 10 * time.Second, Decision{Allowed: true, Remaining: 2, ResetAfter: time.Second}},
+	}
+	for _, st := range steps {
+		if got := take(b, limit, t0.Add(st.at)); got != st.want {
+			t.Errorf("%s: take = %+v, want %+v", st.name, got, st.want)
+		}
+	}
+}
+
+func TestMemoryStoreEviction(t *testing.T) {
+	limit := Limit{Requests: 2, Period: 2 * time.Second}
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := t0
+	s := NewMemoryStore(2)
+	s.now = func() time.Time { return clock }
+
+	steps := []struct {
+		name    string
+		at      time.Duration
+		key     string
+		allowed bool
+		keys    []string // вёдра в памяти после запроса
+	}{
+		{"a", 0, "a", true, []string{"a"}},
+		{"a again", 0, "a", true, []string{"a"}},
+		{"b", 0, "b", true, []string{"a", "b"}},
+		// a исчерпало лимит; обращение делает его самым свежим
+		{"a limited", 500 * time.Millisecond, "a", false, []string{"a", "b"}},
+		// места нет — вытесняется b: к нему дольше всех не обращались
+		{"c evicts the least recently used", 500 * time.Millisecond, "c", true, []string{"a", "c"}},
+		// a сохранило состояние и по-прежнему в лимите
+		{"a still limited", 500 * time.Millisecond, "a", false, []string{"a", "c"}},
+		{"d evicts c", 600 * time.Millisecond, "d", true, []string{"a", "d"}},
+	}
+	for _, st := range steps {
+		clock = t0.Add(st.at)
+		d, err := s.Take(context.Background(), st.key, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Allowed != st.allowed {
+			t.Errorf("%s: allowed = %v, want %v", st.name, d.Allowed, st.allowed)
+		}
+		if s.Len() != len(st.keys) || len(s.buckets) != len(st.keys) {
+			t.Errorf("%s: %d buckets, want %v", st.name, s.Len(), st.keys)
+		}
+		for _, k := range st.keys {
+			if _, ok := s.buckets[k]; !ok {
+				t.Errorf("%s: bucket %q evicted, want %v", st.name, k, st.keys)
+			}
+		}
+	}
+
+	// к 2 с ведро d (один запрос в 0.6 с) уже полное, a — ещё нет
+	clock = t0.Add(1700 * time.Millisecond)
+	if n := s.Sweep(); n != 1 || s.Len() != 1 {
+		t.Errorf("Sweep = %d, Len = %d; want 1, 1", n, s.Len())
+	}
+	clock = t0.Add(time.Minute)
+	if n := s.Sweep(); n != 1 || s.Len() != 0 {
+		t.Errorf("Sweep = %d, Len = %d; want 1, 0", n, s.Len())
+	}
+}
@@ -0,0 +1,63 @@
+// Package realip определяет адрес клиента. Заголовку с адресом доверяем только одному —
+// тому, что выставляет свой прокси (REAL_IP_HEADER); без него берётся адрес соединения,
+// иначе клиент подделает адрес сам.
+package realip
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Resolver — единая точка для лимитов запросов, журнала аудита и антиспама
+type Resolver struct {
+	// header — заголовок с адресом клиента от доверенного прокси (X-Real-IP и т.п.)
+	header string
+}
+
+func New(header string) *Resolver {
+	return &Resolver{header: header}
+}
+
+// ClientIP — адрес клиента с учётом прокси
+func (r *Resolver) ClientIP(req *http.Request) string {
+	if r.header != "" {
+		// X-Forwarded-For: client, proxy1, proxy2 — начало списка присылает сам клиент,
+		// поэтому берём последний адрес: его дописал наш прокси. В X-Real-IP адрес один.
+		if values := req.Header.Values(r.header); len(values) > 0 {
+			list := values[len(values)-1]
+			value := list[strings.LastIndex(list, ",")+1:]
+			if ip := net.ParseIP(strings.TrimSpace(value)); ip != nil {
+				return ip.String()
+			}
+		}
+	}
+	return RemoteIP(req)
+}
+
+// IsHTTPS сообщает, пришёл ли запрос по HTTPS. X-Forwarded-Proto учитывается, только если
+// настроен доверенный прокси: он завершает TLS и выставляет заголовок, а без прокси
+// заголовок присылает сам клиент.
+func (r *Resolver) IsHTTPS(req *http.Request) bool {
+	if req.TLS != nil {
+		return true
+	}
+	if r.header == "" {
+		return false
+	}
+	values := req.Header.Values("X-Forwarded-Proto")
+	if len(values) == 0 {
+		return false
+	}
+	list := values[len(values)-1]
+	return strings.EqualFold(strings.TrimSpace(list[strings.LastIndex(list, ",")+1:]), "https")
+}
+
+// RemoteIP — адрес соединения без порта
+func RemoteIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
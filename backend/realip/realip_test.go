@@ -0,0 +1,73 @@
+package realip
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string   // доверенный заголовок (REAL_IP_HEADER)
+		values []string // его значения в запросе
+		want   string
+	}{
+		{name: "no trusted header ignores forwarded", header: "", values: nil, want: "10.0.0.1"},
+		{name: "single address", header: "X-Real-IP", values: []string{"203.0.113.7"}, want: "203.0.113.7"},
+		{name: "right-most forwarded address", header: "X-Forwarded-For",
+			values: []string{"1.1.1.1, 198.51.100.2, 203.0.113.7"}, want: "203.0.113.7"},
+		{name: "last header line wins", header: "X-Forwarded-For",
+			values: []string{"1.1.1.1", "203.0.113.7"}, want: "203.0.113.7"},
+		{name: "spoofed garbage falls back to connection", header: "X-Forwarded-For",
+			values: []string{"203.0.113.7, not-an-ip"}, want: "10.0.0.1"},
+		{name: "ipv6", header: "X-Real-IP", values: []string{" 2001:db8::1 "}, want: "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = "10.0.0.1:54321"
+			req.Header.Set("X-Forwarded-For", "6.6.6.6")
+			if tt.header != "" {
+				req.Header.Del(tt.header)
+				for _, v := range tt.values {
+					req.Header.Add(tt.header, v)
+				}
+			}
+			if got := New(tt.header).ClientIP(req); got != tt.want {
+				t.Errorf("ClientIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsHTTPS(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string   // доверенный заголовок (REAL_IP_HEADER)
+		proto  []string // X-Forwarded-Proto в запросе
+		want   bool
+	}{
+		{name: "plain http", header: "X-Real-IP", want: false},
+		{name: "no trusted proxy ignores forwarded proto", header: "", proto: []string{"https"}, want: false},
+		{name: "trusted proxy", header: "X-Real-IP", proto: []string{"https"}, want: true},
+		{name: "proxy says http", header: "X-Real-IP", proto: []string{"http"}, want: false},
+		{name: "proxy appends after the client", header: "X-Forwarded-For", proto: []string{"https, http"}, want: false},
+		{name: "last header line wins", header: "X-Forwarded-For", proto: []string{"http", "HTTPS"}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			for _, v := range tt.proto {
+				req.Header.Add("X-Forwarded-Proto", v)
+			}
+			if got := New(tt.header).IsHTTPS(req); got != tt.want {
+				t.Errorf("IsHTTPS = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// по TLS самого сервера — всегда HTTPS
+	if !New("").IsHTTPS(httptest.NewRequest("GET", "https://example.com/", nil)) {
+		t.Error("IsHTTPS over TLS = false")
+	}
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"renault-backend/apidoc"
+	"renault-backend/config"
+	"renault-backend/database"
+	"renault-backend/handlers"
+	"renault-backend/jobs"
+	"renault-backend/logging"
+	"renault-backend/metrics"
+	"renault-backend/notify"
+	"renault-backend/ratelimit"
+	"renault-backend/realip"
+
+	"github.com/gorilla/mux"
+)
+
+// newRouter собирает маршруты API поверх открытых БД (carDB, database.DB) и глобальных
+// зависимостей каталога из main; фоновые задачи маршрутов добавляет в workers, но не запускает.
+// Возвращает роутер и спецификацию OpenAPI — их сверяет `server openapi check` и тесты.
+func newRouter(cfg *config.Config, logger *slog.Logger, workers *workerGroup, clientIPs *realip.Resolver,
+	build handlers.BuildInfo) (*mux.Router, []byte, error) {
+	jobsLog := logger.With("component", "jobs")
+
+	authHandler := handlers.NewAuthHandler(database.NewUserRepository(), JWT_SECRET)
+	carTrashRepo := database.NewCarTrashRepository(carDB)
+	carTrashRetention := time.Duration(cfg.CarTrashRetentionDays) * 24 * time.Hour
+	publishingRepo := database.NewCarPublishingRepository(carDB)
+	priceRepo := database.NewPriceRepository(carDB)
+	financeRepo := database.NewFinanceRepository(carDB)
+	catalogIORepo := database.NewCatalogIORepository(carDB)
+	searchRepo := database.NewSearchRepository(carDB)
+	analyticsRepo := database.NewAnalyticsRepository()
+	moderationRepo := database.NewModerationRepository()
+
+	router := mux.NewRouter()
+
+	// метрики и журнал доступа по шаблонам маршрутов; несовпавшие запросы идут в route="unmatched"
+	router.Use(metrics.Middleware, logging.AccessLog)
+	router.NotFoundHandler = metrics.Middleware(logging.AccessLog(http.NotFoundHandler()))
+	router.MethodNotAllowedHandler = metrics.Middleware(logging.AccessLog(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})))
+
+	// служебные маршруты для мониторинга и оркестратора — вне /api
+	healthHandler := handlers.NewHealthHandler(build, map[string]*sql.DB{"catalog": carDB, "main": database.DB})
+	// метрики раскрывают маршруты, нагрузку и состояние пулов БД — только для Prometheus с токеном
+	router.Handle("/metrics", metrics.Handler(cfg.MetricsToken)).Methods("GET")
+	if cfg.MetricsToken == "" {
+		logger.Warn("METRICS_TOKEN is empty, /metrics is closed")
+	}
+	router.HandleFunc("/livez", healthHandler.Livez).Methods("GET")
+	router.HandleFunc("/readyz", healthHandler.Readyz).Methods("GET")
+
+	// ---------- ЛИМИТЫ ЗАПРОСОВ ----------
+	// вёдра в памяти процесса: при нескольких экземплярах сервера нужен общий ratelimit.Store
+	rateStore := ratelimit.NewMemoryStore(cfg.RateLimitMaxKeys)
+	limiter := ratelimit.NewLimiter(rateStore, clientIPs)
+	workers.Go(jobs.NewRateLimitSweep(rateStore, time.Minute, jobsLog).Run)
+	limits := map[string]ratelimit.Limit{}
+	for name, value := range map[string]string{
+		"register":          cfg.RateLimitRegister,
+		"login":             cfg.RateLimitLogin,
+		"validate-password": cfg.RateLimitValidatePassword,
+		"reviews":           cfg.RateLimitReviews,
+		"events":            cfg.RateLimitEvents,
+	} {
+		limit, err := ratelimit.ParseLimit(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid rate limit config: %w", err)
+		}
+		limits[name] = limit
+	}
+
+	// подроутер /api
+	api := router.PathPrefix("/api").Subrouter()
+
+	// Публичные маршруты (auth и прочее); регистрация дорогая — bcrypt на каждый запрос
+	api.HandleFunc("/health", healthHandler.Health).Methods("GET")
+	api.Handle("/register", limiter.Wrap("register", limits["register"], ratelimit.ByIP,
+		http.HandlerFunc(authHandler.Register))).Methods("POST")
+	api.Handle("/login", limiter.Wrap("login", limits["login"], ratelimit.ByIP,
+		http.HandlerFunc(authHandler.Login))).Methods("POST")
+	api.Handle("/validate-password", limiter.Wrap("validate-password", limits["validate-password"], ratelimit.ByIP,
+		http.HandlerFunc(authHandler.ValidatePassword))).Methods("POST")
+	api.HandleFunc("/password-rules", authHandler.PasswordRules).Methods("GET")
+
+	// ОТЗЫВЫ: только сохранение в БД
+	api.Handle("/reviews", limiter.Wrap("reviews", limits["reviews"], ratelimit.ByIP,
+		http.HandlerFunc(createReviewHandler))).Methods("POST", "OPTIONS")
+
+	// Отладочные маршруты (как было)
+	api.HandleFunc("/users", authHandler.GetAllUsers).Methods("GET")
+
+	// схема характеристик и счётчики фильтров; до /cars/{id}, иначе совпадут с ним
+	api.HandleFunc("/cars/spec-schema", specSchemaHandler).Methods("GET")
+	facetsHandler := handlers.NewFacetsHandler(database.NewCatalogFacetsRepository(carDB))
+	api.HandleFunc("/cars/facets", facetsHandler.Facets).Methods("GET")
+
+	// Каталог автомобилей — новые хендлеры на carDB
+	api.HandleFunc("/cars", getAllCarsHandler).Methods("GET")
+	api.HandleFunc("/cars/{id}", getCarByIDHandler).Methods("GET")
+	// если нужно будет фильтровать по категории:
+	// api.HandleFunc("/cars/category/{category}", getCarsByCategoryHandler).Methods("GET")
+
+	// Каталог автомобилей — публичные GET
+	api.HandleFunc("/cars", getAllCarsHandler).Methods("GET")
+	api.HandleFunc("/cars/{id}", getCarByIDHandler).Methods("GET")
+	// api.HandleFunc("/cars/category/{category}", getCarsByCategoryHandler).Methods("GET")
+
+	api.HandleFunc("/cars/{id}/preview", previewCarHandler).Methods("GET")
+
+	// похожие автомобили и «также смотрели»; просмотры карточек пишет getCarByIDHandler
+	api.HandleFunc("/cars/{id}/similar", recommendationHandler.Similar).Methods("GET")
+
+	priceHandler := handlers.NewPriceHandler(priceRepo)
+	api.HandleFunc("/cars/{id}/price-history", priceHandler.History).Methods("GET")
+
+	// полнотекстовый поиск (индекс обновляется триггерами, см. SearchRepository)
+	searchHandler := handlers.NewSearchHandler(searchRepo)
+	api.HandleFunc("/search", searchHandler.Search).Methods("GET")
+	api.HandleFunc("/search/suggest", searchHandler.Suggest).Methods("GET")
+
+	// ----- АДМИНСКИЕ РОУТЫ ДЛЯ КАТАЛОГА -----
+	admin := api.PathPrefix("/admin").Subrouter()
+
+	// защищаем все маршруты /api/admin/...; изменения пишем в журнал аудита
+	auditRepo := database.NewAuditRepository()
+	admin.Use(JWTAdminMiddleware, handlers.AuditMiddleware(auditRepo, clientIPs))
+
+	auditHandler := handlers.NewAuditHandler(auditRepo)
+	admin.HandleFunc("/audit", auditHandler.List).Methods("GET")
+
+	if cfg.AuditRetentionDays > 0 {
+		workers.Go(jobs.NewAuditRetention(auditRepo, time.Duration(cfg.AuditRetentionDays)*24*time.Hour, 24*time.Hour, jobsLog).Run)
+	}
+
+	admin.HandleFunc("/cars", adminListCarsHandler).Methods("GET")
+	admin.HandleFunc("/cars", createCarHandler).Methods("POST")
+	admin.HandleFunc("/cars/{id}", updateCarHandler).Methods("PUT")
+	admin.HandleFunc("/cars/{id}", patchCarHandler).Methods("PATCH")
+	admin.HandleFunc("/cars/{id}", deleteCarHandler).Methods("DELETE")
+
+	carTrashHandler := handlers.NewCarTrashHandler(carTrashRepo, carTrashRetention)
+	admin.HandleFunc("/cars/trash", carTrashHandler.List).Methods("GET")
+	admin.HandleFunc("/cars/{id}/restore", carTrashHandler.Restore).Methods("POST")
+
+	publishingHandler := handlers.NewCarPublishingHandler(publishingRepo, previewLinkKey)
+	admin.HandleFunc("/cars/{id}/status", publishingHandler.SetStatus).Methods("PUT")
+	admin.HandleFunc("/cars/{id}/preview-link", publishingHandler.PreviewLink).Methods("POST")
+
+	admin.HandleFunc("/cars/{id}/price-schedule", priceHandler.Schedule).Methods("POST")
+	admin.HandleFunc("/price-schedule", priceHandler.ListScheduled).Methods("GET")
+	admin.HandleFunc("/price-schedule/{id:[0-9]+}", priceHandler.CancelScheduled).Methods("DELETE")
+
+	// ----- КРЕДИТ И ЛИЗИНГ -----
+	financeHandler := handlers.NewFinanceHandler(financeRepo)
+
+	api.HandleFunc("/finance/products", financeHandler.ListProducts).Methods("GET")
+	api.HandleFunc("/finance/quote", financeHandler.Quote).Methods("POST", "OPTIONS")
+
+	admin.HandleFunc("/finance/products", financeHandler.AdminListProducts).Methods("GET")
+	admin.HandleFunc("/finance/products", financeHandler.CreateProduct).Methods("POST")
+	admin.HandleFunc("/finance/products/{id}", financeHandler.UpdateProduct).Methods("PUT")
+	admin.HandleFunc("/finance/products/{id}", financeHandler.DeleteProduct).Methods("DELETE")
+
+	// ----- TRADE-IN -----
+	tradeInRepo := database.NewTradeInRepository()
+	tradeInHandler := handlers.NewTradeInHandler(tradeInRepo, "uploads/trade-in")
+
+	api.Handle("/trade-in", JWTUserMiddleware(http.HandlerFunc(tradeInHandler.Submit))).Methods("POST", "OPTIONS")
+	api.Handle("/trade-in", JWTUserMiddleware(http.HandlerFunc(tradeInHandler.ListMine))).Methods("GET")
+	api.Handle("/trade-in/credit", JWTUserMiddleware(http.HandlerFunc(tradeInHandler.Credit))).Methods("GET")
+	api.Handle("/trade-in/{id:[0-9]+}", JWTUserMiddleware(http.HandlerFunc(tradeInHandler.GetMine))).Methods("GET")
+	api.Handle("/trade-in/{id:[0-9]+}/photos/{name}", JWTUserMiddleware(http.HandlerFunc(tradeInHandler.Photo))).Methods("GET")
+	api.Handle("/trade-in/{id:[0-9]+}/accept", JWTUserMiddleware(http.HandlerFunc(tradeInHandler.Accept))).Methods("POST", "OPTIONS")
+	api.Handle("/trade-in/{id:[0-9]+}/reject", JWTUserMiddleware(http.HandlerFunc(tradeInHandler.Reject))).Methods("POST", "OPTIONS")
+	api.Handle("/trade-in/{id:[0-9]+}/apply", JWTUserMiddleware(http.HandlerFunc(tradeInHandler.Apply))).Methods("POST", "OPTIONS")
+
+	admin.HandleFunc("/trade-in", tradeInHandler.AdminList).Methods("GET")
+	admin.HandleFunc("/trade-in/{id:[0-9]+}/photos/{name}", tradeInHandler.AdminPhoto).Methods("GET")
+	admin.HandleFunc("/trade-in/{id:[0-9]+}/offer", tradeInHandler.SetOffer).Methods("PUT")
+	admin.HandleFunc("/trade-in/depreciation", tradeInHandler.ListDepreciation).Methods("GET")
+	admin.HandleFunc("/trade-in/depreciation", tradeInHandler.SaveDepreciation).Methods("PUT")
+	admin.HandleFunc("/trade-in/depreciation/{id:[0-9]+}", tradeInHandler.DeleteDepreciation).Methods("DELETE")
+
+	// ----- ЛИДЫ (заявки отделу продаж) -----
+	leadRepo := database.NewLeadRepository()
+	leadHandler := handlers.NewLeadHandler(
+		leadRepo,
+		database.NewAdminRepository(),
+		time.Duration(cfg.LeadSLAHours)*time.Hour,
+		analyticsEvents,
+		spamGuard,
+	)
+
+	api.HandleFunc("/leads", leadHandler.Submit).Methods("POST", "OPTIONS")
+
+	admin.HandleFunc("/leads", leadHandler.List).Methods("GET")
+	admin.HandleFunc("/leads/export", leadHandler.ExportCSV).Methods("GET")
+	admin.HandleFunc("/leads/{id:[0-9]+}", leadHandler.Get).Methods("GET")
+	admin.HandleFunc("/leads/{id:[0-9]+}/status", leadHandler.UpdateStatus).Methods("PUT")
+	admin.HandleFunc("/leads/{id:[0-9]+}/assign", leadHandler.Assign).Methods("PUT")
+	admin.HandleFunc("/leads/{id:[0-9]+}/notes", leadHandler.AddNote).Methods("POST")
+
+	// ----- МОДЕРАЦИЯ отзывов и заявок, которые антиспам счёл подозрительными -----
+	moderationHandler := handlers.NewModerationHandler(moderationRepo, reviewRepo, leadRepo, analyticsEvents)
+	api.HandleFunc("/form-token", spamGuard.FormToken).Methods("GET")
+	admin.HandleFunc("/moderation", moderationHandler.List).Methods("GET")
+	admin.HandleFunc("/moderation/{id:[0-9]+}/approve", moderationHandler.Approve).Methods("POST")
+	admin.HandleFunc("/moderation/{id:[0-9]+}/reject", moderationHandler.Reject).Methods("POST")
+
+	// ----- АНАЛИТИКА -----
+	analyticsHandler := handlers.NewAnalyticsHandler(analyticsRepo, analyticsEvents)
+
+	// публичный приём событий: без лимита им можно накрутить просмотры и засорить отчёты
+	api.Handle("/events", limiter.Wrap("events", limits["events"], ratelimit.ByIP,
+		http.HandlerFunc(analyticsHandler.Ingest))).Methods("POST", "OPTIONS")
+
+	admin.HandleFunc("/analytics/views", analyticsHandler.Views).Methods("GET")
+	admin.HandleFunc("/analytics/funnel", analyticsHandler.Funnel).Methods("GET")
+	admin.HandleFunc("/analytics/categories", analyticsHandler.Categories).Methods("GET")
+	admin.HandleFunc("/analytics/ratings", analyticsHandler.Ratings).Methods("GET")
+	admin.HandleFunc("/analytics/refresh", analyticsHandler.RefreshRollups).Methods("POST")
+
+	// ----- АКЦИИ -----
+	promotionHandler := handlers.NewPromotionHandler(promoRepo)
+
+	admin.HandleFunc("/promotions", promotionHandler.List).Methods("GET")
+	admin.HandleFunc("/promotions", promotionHandler.Create).Methods("POST")
+	admin.HandleFunc("/promotions/{id:[0-9]+}", promotionHandler.Update).Methods("PUT")
+	admin.HandleFunc("/promotions/{id:[0-9]+}", promotionHandler.Delete).Methods("DELETE")
+
+	cartHandler := handlers.NewCartHandler(priceRepo, promoRepo, tradeInRepo, analyticsEvents, logger)
+
+	api.HandleFunc("/cart", cartHandler.GetCart).Methods(http.MethodGet, http.MethodOptions)
+	api.HandleFunc("/cart", cartHandler.AddToCart).Methods(http.MethodPost, http.MethodOptions)
+	api.HandleFunc("/cart", cartHandler.Clear).Methods(http.MethodDelete, http.MethodOptions)
+	api.HandleFunc("/cart/{id}", cartHandler.UpdateQuantity).Methods(http.MethodPatch, http.MethodOptions)
+	api.HandleFunc("/cart/{id}", cartHandler.DeleteItem).Methods(http.MethodDelete, http.MethodOptions)
+	api.HandleFunc("/cart/checkout", cartHandler.Checkout).Methods(http.MethodPost, http.MethodOptions)
+	api.Handle("/cart/promo", JWTUserMiddleware(http.HandlerFunc(cartHandler.ApplyPromoCode))).Methods(http.MethodPost, http.MethodOptions)
+	api.HandleFunc("/cart/promo/{code}", cartHandler.RemovePromoCode).Methods(http.MethodDelete, http.MethodOptions)
+
+	// ----- ЖИЗНЕННЫЙ ЦИКЛ КОРЗИНЫ -----
+	cartRepo := database.NewCartRepository()
+	notificationRepo := database.NewNotificationRepository()
+	abandonedAfter := time.Duration(cfg.CartAbandonedHours) * time.Hour
+
+	notifier, err := notify.New(cfg.NotificationSink, cfg.NotificationFile, logger.With("component", "notify"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid notification config: %w", err)
+	}
+	workers.Go(jobs.NewCartLifecycle(cartRepo, promoRepo, notificationRepo,
+		time.Duration(cfg.CartExpiryDays)*24*time.Hour, abandonedAfter, 10*time.Minute, jobsLog).Run)
+	workers.Go(jobs.NewNotificationDispatcher(notificationRepo, notifier, time.Minute, jobsLog).Run)
+
+	cartReportHandler := handlers.NewCartReportHandler(cartRepo, abandonedAfter)
+	admin.HandleFunc("/carts/abandoned", cartReportHandler.Abandoned).Methods("GET")
+
+	// ----- ВЫГРУЗКА / ЗАГРУЗКА КАТАЛОГА -----
+	catalogIOHandler := handlers.NewCatalogIOHandler(catalogIORepo)
+	admin.HandleFunc("/catalog/export", catalogIOHandler.Export).Methods("GET")
+	admin.HandleFunc("/catalog/import", catalogIOHandler.Import).Methods("POST")
+
+	// окончательно удаляем автомобили, пролежавшие в корзине дольше срока хранения
+	workers.Go(jobs.NewCarTrashPurge(carTrashRepo, cartRepo, carTrashRetention, time.Hour, jobsLog).Run)
+
+	// ---------- ДОКУМЕНТАЦИЯ API ----------
+	// спецификация собирается из описаний маршрутов (openapi.go, handlers/openapi.go)
+	spec, err := buildAPISpec()
+	if err != nil {
+		return nil, nil, fmt.Errorf("build OpenAPI spec: %w", err)
+	}
+	api.Handle("/openapi.json", apidoc.SpecHandler(spec)).Methods("GET")
+	api.Handle("/docs", http.RedirectHandler("/api/docs/", http.StatusMovedPermanently)).Methods("GET")
+	api.PathPrefix("/docs/").Handler(http.StripPrefix("/api/docs", apidoc.DocsHandler())).Methods("GET")
+
+	return router, spec, nil
+}
@@ -0,0 +1,69 @@
+// Package security — политика CORS и заголовки безопасности, общие для всех ответов сервера
+package security
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/rs/cors"
+)
+
+// CORSOptions — политика для браузерных запросов с чужих источников
+type CORSOptions struct {
+	// AllowedOrigins — точные источники вида https://shop.example.ru; допускается один
+	// поддомен-шаблон (https://*.example.ru) и "*" — любой источник без credentials.
+	// Пустой список — кросс-доменные запросы запрещены.
+	AllowedOrigins []string
+	// AllowCredentials разрешает браузеру отправлять cookie и HTTP-аутентификацию;
+	// API авторизуется заголовком Authorization, поэтому по умолчанию не нужно
+	AllowCredentials bool
+}
+
+// NewCORS проверяет политику и собирает middleware. Ошибка — политика, которую браузер
+// отвергнет или которая открывает API любому сайту с учётными данными пользователя.
+func NewCORS(opts CORSOptions) (*cors.Cors, error) {
+	origins := make([]string, 0, len(opts.AllowedOrigins))
+	for _, origin := range opts.AllowedOrigins {
+		if origin == "*" {
+			if opts.AllowCredentials {
+				return nil, fmt.Errorf(`CORS: origin "*" cannot be combined with credentials, list origins explicitly`)
+			}
+			if len(opts.AllowedOrigins) > 1 {
+				return nil, fmt.Errorf(`CORS: origin "*" must be the only allowed origin`)
+			}
+		} else if err := validateOrigin(origin); err != nil {
+			return nil, err
+		}
+		origins = append(origins, strings.TrimSuffix(origin, "/"))
+	}
+
+	options := cors.Options{
+		AllowedOrigins: origins,
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders: []string{"Content-Type", "Authorization", "X-Requested-With", "X-User-Id", "X-Request-ID"},
+		ExposedHeaders: []string{"Content-Length", "Content-Disposition", "X-Request-ID",
+			"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After"},
+		AllowCredentials: opts.AllowCredentials,
+		MaxAge:           86400,
+	}
+	if len(origins) == 0 {
+		// rs/cors трактует пустой список как «разрешено всем» — явно запрещаем
+		options.AllowOriginFunc = func(string) bool { return false }
+	}
+	return cors.New(options), nil
+}
+
+// validateOrigin — источник по RFC 6454: схема http(s) и хост, без пути, запроса и фрагмента.
+// "null" (страницы из file://, песочницы iframe) не принимаем: его может прислать любой сайт.
+func validateOrigin(origin string) error {
+	u, err := url.Parse(strings.Replace(strings.TrimSuffix(origin, "/"), "://*.", "://wildcard.", 1))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return fmt.Errorf("CORS: invalid origin %q, expected scheme://host[:port]", origin)
+	}
+	if strings.Contains(u.Host, "*") {
+		return fmt.Errorf("CORS: invalid origin %q, only a leading *. subdomain wildcard is supported", origin)
+	}
+	return nil
+}
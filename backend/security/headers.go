@@ -0,0 +1,47 @@
+package security
+
+import (
+	"net/http"
+	"renault-backend/realip"
+	"strconv"
+)
+
+// HeadersOptions — заголовки безопасности, которые получает каждый ответ
+type HeadersOptions struct {
+	// ContentSecurityPolicy; пустая строка — заголовок не ставится.
+	// Сервер отдаёт JSON и картинки, поэтому по умолчанию запрещено всё.
+	ContentSecurityPolicy string
+	// HSTSMaxAgeSeconds > 0 — Strict-Transport-Security для ответов по HTTPS
+	// (в том числе за доверенным прокси, завершающим TLS, см. realip.Resolver.IsHTTPS)
+	HSTSMaxAgeSeconds int
+	// Proxy определяет, пришёл ли запрос по HTTPS; nil — только по TLS самого сервера
+	Proxy *realip.Resolver
+}
+
+// Headers — внешний middleware: заголовки выставляются до обработчика, поэтому есть
+// и у ошибок, и у ответов CORS, и у статических файлов
+func Headers(opts HeadersOptions, next http.Handler) http.Handler {
+	hsts := ""
+	if opts.HSTSMaxAgeSeconds > 0 {
+		hsts = "max-age=" + strconv.Itoa(opts.HSTSMaxAgeSeconds) + "; includeSubDomains"
+	}
+	proxy := opts.Proxy
+	if proxy == nil {
+		proxy = realip.New("")
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		if opts.ContentSecurityPolicy != "" {
+			h.Set("Content-Security-Policy", opts.ContentSecurityPolicy)
+		}
+		// браузер не должен угадывать тип: JSON или загруженный файл не станет HTML/скриптом
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("X-Frame-Options", "DENY")
+		h.Set("Referrer-Policy", "strict-origin-when-cross-origin")
+		// по HTTP браузер HSTS игнорирует, так что отправляем только по HTTPS
+		if hsts != "" && proxy.IsHTTPS(r) {
+			h.Set("Strict-Transport-Security", hsts)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"renault-backend/config"
+)
+
+// newHTTPServer — сервер с таймаутами: без них медленный или зависший клиент
+// держит соединение и горутину сколько угодно
+func newHTTPServer(cfg *config.Config, addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       time.Duration(cfg.HTTPReadTimeoutSeconds) * time.Second,
+		WriteTimeout:      time.Duration(cfg.HTTPWriteTimeoutSeconds) * time.Second,
+		IdleTimeout:       time.Duration(cfg.HTTPIdleTimeoutSeconds) * time.Second,
+		MaxHeaderBytes:    1 << 20,
+		TLSConfig:         &tls.Config{MinVersion: tls.VersionTLS12},
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+	}
+}
+
+// runServer обслуживает запросы на ln, пока не отменён ctx, затем перестаёт принимать
+// новые соединения и ждёт завершения начатых запросов не дольше drainTimeout.
+// certFile и keyFile заданы — соединения принимаются по TLS.
+func runServer(ctx context.Context, srv *http.Server, ln net.Listener, certFile, keyFile string, drainTimeout time.Duration) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		if certFile != "" {
+			serveErr <- srv.ServeTLS(ln, certFile, keyFile)
+		} else {
+			serveErr <- srv.Serve(ln)
+		}
+	}()
+
+	select {
+	case err := <-serveErr:
+		// сервер упал сам, например не прочитался сертификат
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		// не дождались — обрываем оставшиеся соединения
+		srv.Close()
+		return err
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// workerGroup — фоновые задачи сервера с общим контекстом; Stop отменяет его и ждёт,
+// пока задачи закончат текущую итерацию (например, допишут события аналитики)
+type workerGroup struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	pending []func(ctx context.Context)
+}
+
+func newWorkerGroup() *workerGroup {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &workerGroup{ctx: ctx, cancel: cancel}
+}
+
+// Go добавляет задачу вида jobs.X.Run; запускается она в Start
+func (g *workerGroup) Go(run func(ctx context.Context)) {
+	g.pending = append(g.pending, run)
+}
+
+// Start запускает добавленные задачи. До него можно собрать роутер
+// и выйти (подкоманды CLI), не запустив ни одной задачи.
+func (g *workerGroup) Start() {
+	for _, run := range g.pending {
+		g.wg.Add(1)
+		go func() {
+			defer g.wg.Done()
+			run(g.ctx)
+		}()
+	}
+	g.pending = nil
+}
+
+// Stop останавливает задачи; false — не все успели завершиться за timeout
+func (g *workerGroup) Stop(timeout time.Duration) bool {
+	g.cancel()
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}